@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/supperghost/ossre/internal/bundle"
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/report"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// kmsgLines 为快照包中保留的内核日志行数。
+const kmsgLines = 1000
+
+// handleCollect 运行诊断模块并记录其读取的全部文件，连同额外的系统文件打包为快照包，
+// 供 run --from-bundle 在其他机器上离线复现诊断结果。
+func handleCollect(args []string) {
+	fs := flag.NewFlagSet("collect", flag.ExitOnError)
+	out := fs.String("out", "", "快照包输出路径，如 bundle.tar.gz")
+	module := fs.String("module", "", "采集时运行的诊断模块，多个模块以逗号分隔；默认运行全部模块")
+	pid := fs.Int("pid", 0, "目标进程 PID，可选；不指定时默认使用自身 PID")
+	container := fs.String("container", "", "目标容器 ID，以容器的 init 进程与 cgroup 作为诊断目标")
+	common := addCommonFlags(fs)
+	_ = fs.Parse(args)
+
+	if *out == "" {
+		fmt.Fprintln(os.Stderr, "必须通过 --out 指定快照包输出路径")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	cfg := common.load(fs)
+	// 快照包只保存每个文件的首次读取结果，趋势采样无法离线复现
+	cfg.Sampling.Window = 0
+
+	target := core.Target{ContainerID: *container}
+	if *pid > 0 {
+		target.PIDs = []int{*pid}
+	}
+
+	rec := bundle.NewRecorder(collectors.NewHostFS(cfg.Paths))
+	r := newRunner(core.WithConfig(cfg), core.WithLogger(newLogger(*common.verbose)), core.WithFS(rec))
+
+	var names []string
+	if *module != "" {
+		names = strings.Split(*module, ",")
+	} else {
+		for _, p := range r.ListPlugins() {
+			names = append(names, p.Name())
+		}
+	}
+
+	// 单个模块失败不影响采集，已完成模块的读取记录与结果照常打包
+	runResults, err := r.RunAll(context.Background(), names, target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "警告: %v\n", err)
+		if runResults == nil {
+			os.Exit(1)
+		}
+	}
+	bundle.CaptureExtras(rec)
+
+	meta := bundle.Meta{
+		OssreVersion: version,
+		CreatedAt:    time.Now().UTC(),
+		Target:       target,
+		Plugins:      names,
+		Paths:        cfg.Paths,
+	}
+	c := collectors.NewCollector(rec)
+	meta.Hostname, _ = c.Sysctl("kernel.hostname")
+	meta.KernelRelease, _ = c.Sysctl("kernel.osrelease")
+
+	attachments := make(map[string][]byte)
+	results := make([]models.Result, 0, len(runResults))
+	for _, rr := range runResults {
+		results = append(results, rr.Result)
+	}
+	var resultsJSON bytes.Buffer
+	if err := report.Write(&resultsJSON, report.FormatJSON, report.Meta{}, results); err == nil {
+		attachments[bundle.AttachmentResults] = resultsJSON.Bytes()
+	}
+	if data, err := bundle.ReadKmsg(kmsgLines); err == nil {
+		attachments[bundle.AttachmentKmsg] = data
+	} else {
+		fmt.Fprintf(os.Stderr, "警告: 读取内核日志失败，快照包中不包含 %s: %v\n", bundle.AttachmentKmsg, err)
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.Write(&buf, rec, meta, attachments); err != nil {
+		fmt.Fprintf(os.Stderr, "生成快照包失败: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "写入快照包失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "已写入快照包 %s：%d 个模块，%d 个文件，%d 字节\n", *out, len(runResults), rec.Len(), buf.Len())
+}
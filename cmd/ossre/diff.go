@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/supperghost/ossre/internal/diff"
+	"github.com/supperghost/ossre/internal/report"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// exitRegression 为 diff 发现回归时的退出码，与参数或读取错误的退出码 1 区分。
+const exitRegression = 2
+
+// handleDiff 按案例 ID 对比两份 json 报告，输出已解决、新增、级别变化与未变化的发现以及数值证据的变化。
+func handleDiff(args []string) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := fs.String("format", report.FormatPlain, "输出格式: "+strings.Join(diff.Formats(), "、"))
+	failOn := fs.String("fail-on", "", "存在新增或级别升高、且级别不低于该值的发现时以退出码 2 退出，可选 info、warning、error、critical")
+
+	// 允许选项出现在文件参数之后，如 ossre diff old.json new.json --fail-on=warning
+	var files []string
+	for {
+		_ = fs.Parse(args)
+		if fs.NArg() == 0 {
+			break
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(files) != 2 {
+		fmt.Fprintln(os.Stderr, "用法: ossre diff [选项] <旧报告.json> <新报告.json>")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	supported := false
+	for _, f := range diff.Formats() {
+		supported = supported || f == *format
+	}
+	if !supported {
+		fmt.Fprintf(os.Stderr, "不支持的输出格式: %s（可选 %s）\n", *format, strings.Join(diff.Formats(), "、"))
+		os.Exit(1)
+	}
+	threshold := models.Severity(*failOn)
+	switch threshold {
+	case "", models.SeverityInfo, models.SeverityWarning, models.SeverityError, models.SeverityCritical:
+	default:
+		fmt.Fprintf(os.Stderr, "不支持的 --fail-on 级别: %s\n", *failOn)
+		os.Exit(1)
+	}
+
+	old, err := readReport(files[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取报告 %s 失败: %v\n", files[0], err)
+		os.Exit(1)
+	}
+	cur, err := readReport(files[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取报告 %s 失败: %v\n", files[1], err)
+		os.Exit(1)
+	}
+
+	d := diff.Compare(old, cur)
+	if err := diff.Write(os.Stdout, *format, files[0], files[1], d); err != nil {
+		fmt.Fprintf(os.Stderr, "输出对比结果失败: %v\n", err)
+		os.Exit(1)
+	}
+	if threshold != "" {
+		if regressions := d.Regressions(threshold); len(regressions) > 0 {
+			fmt.Fprintf(os.Stderr, "发现 %d 条新增或级别升高的问题（级别不低于 %s）\n", len(regressions), threshold)
+			os.Exit(exitRegression)
+		}
+	}
+}
+
+func readReport(path string) ([]models.Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return report.ReadJSON(f)
+}
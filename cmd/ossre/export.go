@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/remedy"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// handleExport 将报告中可修复的发现转换为可纳入配置管理的持久化配置文件。
+// 指定报告文件时从报告读取，否则与 fix 一样在本机运行诊断模块。
+func handleExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("out", "", "输出目录，文件按部署路径存放，如 <dir>/etc/sysctl.d/99-ossre.conf；为空时输出到标准输出")
+	unit := fs.String("unit", "", "systemd drop-in 所属的服务单元，如 nginx.service；为空时不生成 systemd drop-in")
+	ansible := fs.Bool("ansible", false, "额外生成部署这些文件的 Ansible 任务文件")
+	module := fs.String("module", strings.Join(defaultFixModules, ","), "未指定报告文件时运行的诊断模块，多个模块以逗号分隔")
+	pid := fs.Int("pid", 0, "未指定报告文件时的目标进程 PID，可选；不指定时默认使用自身 PID")
+	common := addCommonFlags(fs)
+
+	// 与 diff 一样允许选项出现在报告文件之后
+	var files []string
+	for {
+		_ = fs.Parse(args)
+		if fs.NArg() == 0 {
+			break
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(files) > 1 {
+		fmt.Fprintln(os.Stderr, "用法: ossre export [选项] [报告.json]")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	var results []models.Result
+	if len(files) == 1 {
+		var err error
+		if results, err = readReport(files[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "读取报告 %s 失败: %v\n", files[0], err)
+			os.Exit(1)
+		}
+	} else {
+		cfg := common.load(fs)
+		target := core.Target{}
+		if *pid > 0 {
+			target.PIDs = []int{*pid}
+		}
+		r := newRunner(core.WithConfig(cfg), core.WithLogger(newLogger(*common.verbose)))
+		runResults, err := r.RunAll(context.Background(), strings.Split(*module, ","), target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "运行模块 %s 失败: %v\n", *module, err)
+			os.Exit(1)
+		}
+		for _, rr := range runResults {
+			results = append(results, rr.Result)
+		}
+		results = applySuppressions(cfg, hostname(cfg), results)
+	}
+
+	artifacts, warnings := remedy.Export(results, remedy.ExportOptions{Unit: *unit, Ansible: *ansible})
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "注意: %s\n", w)
+	}
+	if len(artifacts) == 0 {
+		fmt.Fprintln(os.Stderr, "报告中没有可生成配置文件的发现。")
+		return
+	}
+
+	if *out == "" {
+		for i, a := range artifacts {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("==> %s <==\n%s", a.Path, a.Content)
+		}
+		return
+	}
+	for _, a := range artifacts {
+		name := filepath.Join(*out, filepath.FromSlash(strings.TrimPrefix(a.Path, "/")))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "创建目录失败: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(name, []byte(a.Content), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "写入 %s 失败: %v\n", name, err)
+			os.Exit(1)
+		}
+		fmt.Println(name)
+	}
+}
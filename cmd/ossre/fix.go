@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/plugins/kernel"
+	"github.com/supperghost/ossre/internal/plugins/maxproc"
+	"github.com/supperghost/ossre/internal/remedy"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// defaultFixModules 为 fix 默认运行的模块，其建议带有可自动执行的修复动作。
+var defaultFixModules = []string{kernel.PluginName, maxproc.PluginName}
+
+// handleFix 运行诊断模块并根据建议中的修复动作生成修复计划。默认只预览计划；
+// 指定 --apply 时经确认后执行，执行前保存备份记录，可通过 --rollback 恢复。
+func handleFix(args []string) {
+	fs := flag.NewFlagSet("fix", flag.ExitOnError)
+	module := fs.String("module", strings.Join(defaultFixModules, ","), "生成修复计划的诊断模块，多个模块以逗号分隔")
+	pid := fs.Int("pid", 0, "目标进程 PID，可选；不指定时默认使用自身 PID")
+	apply := fs.Bool("apply", false, "执行修复计划，默认只预览（dry-run）")
+	yes := fs.Bool("yes", false, "执行前不再交互确认")
+	selected := fs.String("select", "", "只执行指定序号的动作，如 1,3")
+	rollback := fs.String("rollback", "", "按备份记录 ID 恢复修复前的状态")
+	history := fs.Bool("history", false, "列出备份记录")
+	stateDir := fs.String("state-dir", remedy.DefaultStateDir, "备份记录的保存目录")
+	common := addCommonFlags(fs)
+	_ = fs.Parse(args)
+
+	cfg := common.load(fs)
+	engine := remedy.NewEngine(cfg.Paths, *stateDir)
+
+	switch {
+	case *history:
+		printFixHistory(engine)
+		return
+	case *rollback != "":
+		rec, err := engine.Rollback(*rollback)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "回滚失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("已按备份记录 %s 恢复 %d 个文件:\n", rec.ID, len(rec.Files))
+		for _, f := range rec.Files {
+			if f.Existed {
+				fmt.Printf("  恢复 %s\n", f.Path)
+			} else {
+				fmt.Printf("  删除 %s\n", f.Path)
+			}
+		}
+		return
+	}
+
+	target := core.Target{}
+	if *pid > 0 {
+		target.PIDs = []int{*pid}
+	}
+	r := newRunner(core.WithConfig(cfg), core.WithLogger(newLogger(*common.verbose)))
+	runResults, err := r.RunAll(context.Background(), strings.Split(*module, ","), target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "运行模块 %s 失败: %v\n", *module, err)
+		os.Exit(1)
+	}
+	results := make([]models.Result, 0, len(runResults))
+	for _, rr := range runResults {
+		results = append(results, rr.Result)
+	}
+	// 被豁免的发现是已接受的风险，不生成修复动作
+	results = applySuppressions(cfg, hostname(cfg), results)
+
+	steps := engine.Plan(results)
+	if len(steps) == 0 {
+		fmt.Println("未发现可自动修复的问题。")
+		return
+	}
+	if *selected != "" {
+		if steps, err = selectSteps(steps, *selected); err != nil {
+			fmt.Fprintf(os.Stderr, "--select 参数无效: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	printFixPlan(steps)
+
+	if !*apply {
+		fmt.Println("\n以上为预览，未做任何修改。使用 --apply 执行全部动作，或配合 --select=1,3 只执行部分动作。")
+		return
+	}
+	if !*yes && !confirm(fmt.Sprintf("\n将执行以上 %d 个动作，备份记录保存在 %s。确认执行？[y/N] ", len(steps), *stateDir)) {
+		fmt.Println("已取消。")
+		return
+	}
+
+	rec, err := engine.Apply(steps)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "执行修复失败: %v\n", err)
+		if rec != nil {
+			fmt.Fprintf(os.Stderr, "部分修改可能已生效，可执行 ossre fix --rollback=%s 恢复\n", rec.ID)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("\n已执行 %d 个动作，备份记录 ID: %s\n", len(rec.Actions), rec.ID)
+	fmt.Printf("如需恢复，执行: ossre fix --rollback=%s\n", rec.ID)
+	for _, a := range rec.Actions {
+		if a.Type == models.ActionLimitsDropIn {
+			fmt.Println("limits 配置需重新登录或重启对应服务进程后生效。")
+			break
+		}
+	}
+}
+
+// selectSteps 按逗号分隔的序号筛选修复动作，保持计划中的原始顺序。
+func selectSteps(steps []remedy.Step, spec string) ([]remedy.Step, error) {
+	want := make(map[int]bool)
+	for _, s := range strings.Split(spec, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n < 1 || n > len(steps) {
+			return nil, fmt.Errorf("序号 %q 超出范围 1-%d", s, len(steps))
+		}
+		want[n] = true
+	}
+	var out []remedy.Step
+	for _, s := range steps {
+		if want[s.Index] {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func printFixPlan(steps []remedy.Step) {
+	fmt.Printf("修复计划（共 %d 个动作）:\n", len(steps))
+	for _, s := range steps {
+		fmt.Printf("\n[%d] %s %s\n    %s\n    %s\n", s.Index, s.Plugin, s.FindingID, s.Suggestion, remedy.Describe(s.Action))
+		if s.Current != "" {
+			fmt.Printf("    当前值: %s\n", s.Current)
+		}
+	}
+}
+
+func printFixHistory(engine *remedy.Engine) {
+	records, err := engine.History()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取备份记录失败: %v\n", err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		fmt.Println("没有备份记录。")
+		return
+	}
+	for _, rec := range records {
+		status := "已生效"
+		if rec.RolledBack != nil {
+			status = "已于 " + rec.RolledBack.Local().Format(time.RFC3339) + " 回滚"
+		}
+		fmt.Printf("%s\t%s\t%d 个动作\t%s\n", rec.ID, rec.CreatedAt.Local().Format(time.RFC3339), len(rec.Actions), status)
+	}
+}
+
+// confirm 在标准错误输出提示并从标准输入读取确认，只有 y 或 yes 视为确认。
+func confirm(prompt string) bool {
+	fmt.Fprint(os.Stderr, prompt)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -2,17 +2,21 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/supperghost/ossre/internal/bundle"
+	"github.com/supperghost/ossre/internal/collectors"
 	"github.com/supperghost/ossre/internal/core"
-	"github.com/supperghost/ossre/internal/plugins/io"
-	"github.com/supperghost/ossre/internal/plugins/kernel"
-	"github.com/supperghost/ossre/internal/plugins/maxproc"
-	"github.com/supperghost/ossre/internal/plugins/net"
-	"github.com/supperghost/ossre/internal/plugins/system"
+	"github.com/supperghost/ossre/internal/plugins/builtin"
+	"github.com/supperghost/ossre/internal/plugins/scan"
+	"github.com/supperghost/ossre/internal/report"
+	"github.com/supperghost/ossre/internal/suppress"
+	"github.com/supperghost/ossre/pkg/config"
 	"github.com/supperghost/ossre/pkg/models"
 )
 
@@ -28,9 +32,21 @@ func main() {
 
 	switch cmd {
 	case "list":
-		handleList()
+		handleList(os.Args[2:])
 	case "run":
 		handleRun(os.Args[2:])
+	case "collect":
+		handleCollect(os.Args[2:])
+	case "serve":
+		handleServe(os.Args[2:])
+	case "diff":
+		handleDiff(os.Args[2:])
+	case "fix":
+		handleFix(os.Args[2:])
+	case "export":
+		handleExport(os.Args[2:])
+	case "preflight":
+		handlePreflight(os.Args[2:])
 	case "version":
 		handleVersion()
 	case "-h", "--help", "help":
@@ -42,131 +58,463 @@ func main() {
 	}
 }
 
-func newRunner() *core.Runner {
-	// TODO: 后续可从配置中动态选择启用的插件
-	plugins := []core.Plugin{
-		kernel.New(),
-		maxproc.New(),
-		io.New(),
-		net.New(),
-		system.New(),
+func newRunner(opts ...core.Option) *core.Runner {
+	// 外部插件在所有选项生效后按最终配置发现
+	return core.NewRunner(builtin.Plugins(), append(opts, core.WithExecPlugins())...)
+}
+
+func handleList(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	tag := fs.String("tag", "", "只列出带有指定标签的模块，多个标签以逗号分隔，满足其一即可")
+	detail := fs.Bool("detail", false, "同时列出支持的平台、所需权限、场景与案例 ID")
+	common := addCommonFlags(fs)
+	_ = fs.Parse(args)
+
+	r := newRunner(core.WithConfig(common.load(fs)))
+	platform := r.Platform()
+	for _, p := range r.ListPlugins() {
+		meta := r.Metadata(p.Name())
+		if *tag != "" && !meta.HasTag(strings.Split(*tag, ",")...) {
+			continue
+		}
+		desc := p.Description()
+		if ep, ok := p.(*core.ExecPlugin); ok {
+			desc += fmt.Sprintf("（外部插件 %s）", ep.Path())
+		}
+		if ok, reason := meta.Supports(platform); !ok {
+			desc += fmt.Sprintf("（当前平台不适用: %s）", reason)
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\n", p.Name(), orDash(meta.Version), orDash(strings.Join(meta.Tags, ",")), desc)
+		if *detail {
+			printMetadata(meta)
+		}
+	}
+	for _, err := range r.PluginErrors() {
+		fmt.Fprintf(os.Stderr, "警告: 外部插件未加载: %v\n", err)
 	}
-	return core.NewRunner(plugins)
 }
 
-func handleList() {
-	r := newRunner()
-	plugins := r.ListPlugins()
-	for _, p := range plugins {
-		fmt.Printf("%s\t%s\n", p.Name(), p.Description())
+// printMetadata 以缩进形式打印插件元数据中非空的字段。
+func printMetadata(meta core.Metadata) {
+	for _, kv := range [][2]string{
+		{"平台", strings.Join(meta.OS, ",")},
+		{"最低内核", meta.MinKernel},
+		{"所需权限", strings.Join(meta.Privileges, ",")},
+		{"场景", strings.Join(meta.Scenarios, ", ")},
+		{"案例 ID", strings.Join(meta.CaseIDs, ", ")},
+	} {
+		if kv[1] != "" {
+			fmt.Printf("    %s: %s\n", kv[0], kv[1])
+		}
 	}
 }
 
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 func handleRun(args []string) {
 	fs := flag.NewFlagSet("run", flag.ExitOnError)
-	module := fs.String("module", "", "要运行的诊断模块名称")
+	module := fs.String("module", "", "要运行的诊断模块名称，多个模块以逗号分隔")
+	all := fs.Bool("all", false, "运行全部适用于当前平台的模块")
+	tag := fs.String("tag", "", "运行带有指定标签且适用于当前平台的模块，多个标签以逗号分隔")
 	pid := fs.Int("pid", 0, "目标进程 PID，可选；不指定时默认使用自身 PID")
-	format := fs.String("format", "json", "输出格式: json 或 plain")
+	container := fs.String("container", "", "目标容器 ID（可为短 ID），以容器的 init 进程与 cgroup 作为诊断目标；未指定模块时运行带 container 标签的模块")
+	format := fs.String("format", report.FormatJSON, "输出格式: "+strings.Join(report.Formats(), "、"))
+	sampleWindow := fs.Duration("sample-window", 0, "趋势采样窗口，如 60s；为 0 时仅做单次快照评估")
+	sampleInterval := fs.Duration("sample-interval", 5*time.Second, "趋势采样间隔")
+	scenario := fs.String("scenario", "", "只运行匹配的场景，多个模式以逗号分隔，如 kernel.net.*；未指定 --module 时运行包含这些场景的模块")
+	forecastThreshold := fs.Duration("forecast-threshold", time.Hour, "预计耗尽时间低于该阈值时提升严重级别")
+	allProcesses := fs.Bool("all-processes", false, "全主机扫描：评估所有进程并按余量排序（隐含 --module=scan）")
+	pidSelector := fs.String("pid-selector", "", "全主机扫描的进程筛选条件，如 comm:java,user:app,cgroup:/system.slice/x")
+	workers := fs.Int("workers", 0, "全主机扫描的并发数，默认为 CPU 核数")
+	top := fs.Int("top", 10, "全主机扫描排名表展示的进程数")
+	fromBundle := fs.String("from-bundle", "", "离线分析 ossre collect 生成的快照包，不再读取本机数据")
+	common := addCommonFlags(fs)
 	_ = fs.Parse(args)
 
+	scanMode := *allProcesses || *pidSelector != ""
+	if (*all || *tag != "") && (*module != "" || scanMode) {
+		fmt.Fprintln(os.Stderr, "--all/--tag 不能与 --module、--all-processes、--pid-selector 同时使用")
+		os.Exit(1)
+	}
+	if *container != "" && (*pid > 0 || scanMode) {
+		fmt.Fprintln(os.Stderr, "--container 不能与 --pid、--all-processes、--pid-selector 同时使用")
+		os.Exit(1)
+	}
+	if scanMode {
+		if *module == "" {
+			*module = scan.PluginName
+		} else if *module != scan.PluginName {
+			fmt.Fprintf(os.Stderr, "--all-processes/--pid-selector 仅适用于 %s 模块\n", scan.PluginName)
+			os.Exit(1)
+		}
+	}
+
+	if !report.Supported(*format) {
+		fmt.Fprintf(os.Stderr, "不支持的输出格式: %s（可选 %s）\n", *format, strings.Join(report.Formats(), "、"))
+		os.Exit(1)
+	}
+
+	cfg := common.load(fs)
+	// 仅覆盖命令行中显式指定的参数，未指定时保留配置文件或默认值
+	var windowSet bool
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "sample-window":
+			cfg.Sampling.Window = *sampleWindow
+			windowSet = true
+		case "sample-interval":
+			cfg.Sampling.Interval = *sampleInterval
+		case "forecast-threshold":
+			cfg.Sampling.ForecastThreshold = *forecastThreshold
+		case "workers":
+			cfg.Scan.Workers = *workers
+		case "top":
+			cfg.Scan.Top = *top
+		}
+	})
+
+	target := core.Target{
+		AllProcesses: *allProcesses,
+		PIDSelector:  *pidSelector,
+		ContainerID:  *container,
+	}
+	if *pid > 0 {
+		target.PIDs = []int{*pid}
+	}
+
+	reportMeta := report.Meta{Version: version, GeneratedAt: time.Now(), Hostname: hostname(cfg)}
+	opts := []core.Option{core.WithLogger(newLogger(*common.verbose))}
+	var scenarios []string
+	if *scenario != "" {
+		scenarios = strings.Split(*scenario, ",")
+		opts = append(opts, core.WithScenarios(scenarios))
+	}
+	if *fromBundle != "" {
+		if windowSet && *sampleWindow > 0 {
+			fmt.Fprintln(os.Stderr, "快照包只包含单次快照，不支持 --sample-window 趋势采样")
+			os.Exit(1)
+		}
+		cfg.Sampling.Window = 0
+		b, err := bundle.Open(*fromBundle)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "加载快照包失败: %v\n", err)
+			os.Exit(1)
+		}
+		// 未指定模块与目标时沿用采集时的设置，以得到与现场一致的结果
+		meta := b.Meta()
+		if *module == "" {
+			*module = strings.Join(meta.Plugins, ",")
+		}
+		if *pid == 0 && *container == "" && !scanMode {
+			target = meta.Target
+		}
+		reportMeta.Hostname = meta.Hostname
+		reportMeta.Source = fmt.Sprintf("快照包 %s（采集于 %s）", *fromBundle, meta.CreatedAt.Local().Format(time.RFC3339))
+		opts = append(opts, core.WithFS(b))
+	}
+
+	r := newRunner(append(opts, core.WithConfig(cfg))...)
+	// 只指定容器时运行适用于容器目标的模块
+	if *container != "" && *module == "" && !*all && *tag == "" && scenarios == nil {
+		*tag = "container"
+	}
+	if *all || *tag != "" {
+		*module = strings.Join(selectModules(r, *tag), ",")
+		if *module == "" {
+			fmt.Fprintln(os.Stderr, "没有适用于当前平台的模块")
+			os.Exit(1)
+		}
+	}
+	if scenarios != nil {
+		names, err := r.ScenarioPlugins(scenarios)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "--scenario 参数无效: %v\n", err)
+			os.Exit(1)
+		}
+		*module = strings.Join(intersectModules(*module, names), ",")
+		if *module == "" {
+			fmt.Fprintln(os.Stderr, "指定的模块不包含匹配 --scenario 的场景")
+			os.Exit(1)
+		}
+	}
 	if *module == "" {
-		fmt.Fprintln(os.Stderr, "必须通过 --module 指定诊断模块名称")
+		fmt.Fprintln(os.Stderr, "必须通过 --module、--all、--tag 或 --scenario 指定诊断模块")
 		fs.Usage()
 		os.Exit(1)
 	}
 
-	r := newRunner()
 	ctx := context.Background()
-	if *pid > 0 {
-		ctx = context.WithValue(ctx, "ossre.pid", *pid)
-	}
-	result, err := r.Run(ctx, *module)
+
+	// 多个模块以逗号分隔时一并运行，共享同一份采集缓存
+	names := strings.Split(*module, ",")
+	runResults, err := r.RunAll(ctx, names, target)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "运行模块 %s 失败: %v\n", *module, err)
 		os.Exit(1)
 	}
 
-	// 确保空结果也序列化为 [] 而不是 null
-	if result.Findings == nil {
-		result.Findings = []models.Finding{}
+	results := make([]models.Result, 0, len(runResults))
+	for _, rr := range runResults {
+		results = append(results, rr.Result)
 	}
-	if result.Suggestions == nil {
-		result.Suggestions = []models.Suggestion{}
+	results = applySuppressions(cfg, reportMeta.Hostname, results)
+	if err := report.Write(os.Stdout, *format, reportMeta, results); err != nil {
+		fmt.Fprintf(os.Stderr, "输出模块 %s 结果失败: %v\n", *module, err)
+		os.Exit(1)
 	}
+	warnSkipped(results)
+}
 
-	// 根据格式选择输出方式
-	switch *format {
-	case "plain":
-		outputPlainText(result)
-	default:
-		// 默认输出JSON格式
-		data, err := json.MarshalIndent(result, "", "  ")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "序列化模块 %s 结果为 JSON 失败: %v\n", *module, err)
-			os.Exit(1)
+// selectModules 返回适用于当前平台、且带有 tag 中任一标签（tag 为空时不限）的模块，
+// 不适用的模块在标准错误中说明原因。
+func selectModules(r *core.Runner, tag string) []string {
+	names, skipped := r.Applicable(r.Platform())
+	var selected []string
+	for _, name := range names {
+		if tag == "" || r.Metadata(name).HasTag(strings.Split(tag, ",")...) {
+			selected = append(selected, name)
+		}
+	}
+	for _, p := range r.ListPlugins() {
+		reason, ok := skipped[p.Name()]
+		if ok && (tag == "" || r.Metadata(p.Name()).HasTag(strings.Split(tag, ",")...)) {
+			fmt.Fprintf(os.Stderr, "跳过模块 %s: %s\n", p.Name(), reason)
 		}
-		_, _ = os.Stdout.Write(append(data, '\n'))
 	}
+	return selected
 }
 
-// outputPlainText 以格式化文本方式输出诊断结果
-func outputPlainText(result models.Result) {
-	fmt.Printf("=== %s 诊断结果 ===\n\n", result.Plugin)
-
-	if len(result.Findings) > 0 {
-		fmt.Println("发现问题:")
-		for i, finding := range result.Findings {
-			fmt.Printf("\n%d. %s\n", i+1, finding.Title)
-			fmt.Printf("   严重程度: %s\n", finding.Severity)
-			fmt.Printf("   描述: %s\n", finding.Description)
-			if finding.Impact != "" {
-				fmt.Printf("   影响: %s\n", finding.Impact)
-			}
+// intersectModules 返回 modules（逗号分隔，为空时不限）中属于 names 的模块，顺序与 names 一致。
+func intersectModules(modules string, names []string) []string {
+	if modules == "" {
+		return names
+	}
+	wanted := make(map[string]bool)
+	for _, m := range strings.Split(modules, ",") {
+		wanted[m] = true
+	}
+	var out []string
+	for _, name := range names {
+		if wanted[name] {
+			out = append(out, name)
 		}
-		fmt.Println()
 	}
+	return out
+}
+
+// hostname 返回诊断对象的主机名；通过 --root 诊断宿主机时取自重定向后的 /proc。
+func hostname(cfg *config.Config) string {
+	h, _ := collectors.NewCollector(collectors.NewHostFS(cfg.Paths)).Sysctl("kernel.hostname")
+	return h
+}
 
-	if len(result.Suggestions) > 0 {
-		fmt.Println("建议:")
-		for i, suggestion := range result.Suggestions {
-			fmt.Printf("\n%d. %s\n", i+1, suggestion.Title)
-			fmt.Printf("   %s\n", suggestion.Details)
+// applySuppressions 按配置的豁免文件将已接受的发现移到 Suppressed，豁免文件无效时直接退出。
+func applySuppressions(cfg *config.Config, host string, results []models.Result) []models.Result {
+	list, err := suppress.Load(cfg.Suppress.File)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载豁免文件失败: %v\n", err)
+		os.Exit(1)
+	}
+	return list.Apply(results, host, cfg.Suppress.Role, time.Now())
+}
+
+// commonFlags 为 run 与 collect 共用的配置文件、数据根目录与日志参数。
+type commonFlags struct {
+	verbose    *bool
+	configPath *string
+	root       *string
+	procRoot   *string
+	sysRoot    *string
+	etcRoot    *string
+	rulesDir   *string
+	pluginsDir *string
+	suppress   *string
+	role       *string
+}
+
+func addCommonFlags(fs *flag.FlagSet) *commonFlags {
+	return &commonFlags{
+		verbose:    fs.Bool("verbose", false, "向标准错误输出调试日志"),
+		configPath: fs.String("config", "", "YAML 配置文件路径，命令行参数优先于配置文件"),
+		root:       fs.String("root", "", "宿主机文件系统根目录，同时设置 proc/sys/etc 的位置，如 /host"),
+		procRoot:   fs.String("proc-root", "", "/proc 的实际位置，如 /host/proc"),
+		sysRoot:    fs.String("sys-root", "", "/sys 的实际位置，如 /host/sys"),
+		etcRoot:    fs.String("etc-root", "", "/etc 的实际位置，如 /host/etc"),
+		rulesDir:   fs.String("rules-dir", "", "rules 模块的规则目录，多个目录以逗号分隔，默认 "+config.DefaultRulesDir),
+		pluginsDir: fs.String("plugins-dir", "", "外部插件目录，默认 "+config.DefaultPluginsDir),
+		suppress:   fs.String("suppress-file", "", "豁免文件路径，默认 "+config.DefaultSuppressFile),
+		role:       fs.String("role", "", "本机角色，用于匹配豁免文件中限定 roles 的条目"),
+	}
+}
+
+// load 加载配置文件（如有），再以命令行中显式指定的根目录参数覆盖，出错时直接退出。
+func (f *commonFlags) load(fs *flag.FlagSet) *config.Config {
+	cfg := config.NewDefault()
+	if *f.configPath != "" {
+		loaded, err := config.LoadFromFile(*f.configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "加载配置文件失败: %v\n", err)
+			os.Exit(1)
+		}
+		cfg = loaded
+	}
+	fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == "root" {
+			cfg.Paths.SetRoot(*f.root)
 		}
-		fmt.Println()
+	})
+	// 单独指定的 proc/sys/etc 位置优先于 --root
+	if *f.procRoot != "" {
+		cfg.Paths.ProcRoot = *f.procRoot
+	}
+	if *f.sysRoot != "" {
+		cfg.Paths.SysRoot = *f.sysRoot
+	}
+	if *f.etcRoot != "" {
+		cfg.Paths.EtcRoot = *f.etcRoot
+	}
+	if *f.rulesDir != "" {
+		cfg.Rules.Dirs = strings.Split(*f.rulesDir, ",")
+	}
+	if *f.pluginsDir != "" {
+		cfg.Plugins.Dir = *f.pluginsDir
+	}
+	if *f.suppress != "" {
+		cfg.Suppress.File = *f.suppress
 	}
+	if *f.role != "" {
+		cfg.Suppress.Role = *f.role
+	}
+	return cfg
+}
 
-	if len(result.Findings) == 0 && len(result.Suggestions) == 0 {
-		fmt.Println("未发现问题。")
+// newLogger 创建输出到标准错误的日志接口，verbose 为 true 时输出调试日志。
+func newLogger(verbose bool) *slog.Logger {
+	level := slog.LevelWarn
+	if verbose {
+		level = slog.LevelDebug
 	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
 }
 
 func handleVersion() {
 	fmt.Printf("ossre 诊断框架版本: %s\n", version)
 }
 
+// moduleUsage 按注册表生成帮助信息中的内置模块列表。
+func moduleUsage() string {
+	var b strings.Builder
+	for _, p := range builtin.Plugins() {
+		fmt.Fprintf(&b, "                      %-8s %s\n", p.Name(), p.Description())
+	}
+	return b.String()
+}
+
 func usage() {
 	fmt.Fprintf(os.Stderr, `用法: %s <命令> [选项]
 
 命令:
-  list                列出可用诊断模块
-  run --module=<name> 运行指定诊断模块
+  list                列出可用诊断模块及其版本与标签，包括外部插件目录中的插件
+  run --module=<name> 运行指定诊断模块；--all 运行全部适用于当前平台的模块
+  collect --out=<file>
+                      运行诊断模块并将读取的 proc/sys/etc 文件打包为快照包，供离线分析
+  serve               常驻运行，按周期执行诊断并在 /metrics 上暴露 Prometheus 指标；
+                      指定 --api 时提供远程触发诊断的 HTTP/JSON 接口
+  diff <old.json> <new.json>
+                      按案例 ID 对比两份 json 报告，列出已解决、新增、级别变化的发现与数值变化
+  fix                 根据诊断建议生成修复计划，默认只预览；--apply 执行并保存备份，--rollback 恢复
+  export [report.json]
+                      将可修复的发现转换为 sysctl.d、limits.d、systemd drop-in 与 Ansible 任务文件，供配置管理使用
+  preflight           检测有效 UID、能力集、user/PID namespace 与目标进程数据的可读性，
+                      说明以当前权限运行时哪些检查会记为未评估
   version             显示版本信息
 
 选项:
-  --module=<name>     指定要运行的诊断模块名称
-                      kernel 内核参数优化
-					  maxproc 最大进程数诊断
-					  io I/O 诊断
-					  net 网络诊断
-					  system 系统通用诊断
-  --pid=<pid>         目标进程 PID，可选；不指定时默认使用自身 PID
-  --format=<format>   输出格式，可选值: json (默认), plain (格式化文本)
+  --module=<name>     指定要运行的诊断模块名称，多个模块以逗号分隔时共享同一份采集数据
+%s  --all               运行全部适用于当前平台的模块，不适用的模块（操作系统或内核版本不满足）被跳过
+  --tag=<tag,...>     list 只列出带有指定标签的模块；run 运行带有指定标签且适用于当前平台的模块
+  --detail            list 同时列出支持的平台、所需权限、场景与案例 ID
+  --scenario=<p,...>  只运行匹配的场景，如 kernel.net.*；未指定 --module 时运行包含这些场景的模块
+  --pid=<pid>         目标进程 PID，可选；不指定时默认使用自身 PID（preflight 默认探测 1 号进程）
+  --container=<id>    目标容器 ID，支持短 ID 与 containerd:// 等前缀；扫描 /proc/*/cgroup 定位容器的 init 进程
+                      与 cgroup（docker、containerd、CRI-O、podman），未指定模块时运行带 container 标签的模块
+  --format=<format>   输出格式，可选值: json (默认), plain (格式化文本), markdown, html (单文件报告，适合附到工单),
+                      sarif (代码扫描告警), junit (CI 测试报告，warning 及以上的发现记为失败),
+                      prometheus (文本格式指标，可写入 node_exporter 的 textfile 目录)
+  --all-processes     全主机扫描所有进程，输出最接近线程/fd/内存耗尽的排名表
+  --pid-selector=<s>  按条件筛选扫描的进程，如 comm:java,user:app,cgroup:/system.slice/x
+  --workers=<n>       全主机扫描的并发数，默认为 CPU 核数
+  --top=<n>           排名表展示的进程数，默认 10
+  --verbose           向标准错误输出调试日志
+  --config=<path>     YAML 配置文件路径，命令行参数优先于配置文件
+  --root=<dir>        宿主机文件系统根目录，从 <dir>/proc、<dir>/sys、<dir>/etc 读取数据
+  --proc-root=<dir>   单独指定 /proc 的位置，优先于 --root
+  --sys-root=<dir>    单独指定 /sys 的位置，优先于 --root
+  --etc-root=<dir>    单独指定 /etc 的位置，优先于 --root
+  --rules-dir=<dir>   rules 模块的规则目录，多个目录以逗号分隔，默认 /etc/ossre/rules.d
+  --suppress-file=<f> 豁免文件，匹配的发现移到“已豁免”部分，默认 /etc/ossre/suppressions.yaml
+  --role=<role>       本机角色，用于匹配豁免文件中限定 roles 的条目
+  --plugins-dir=<dir> 外部插件目录，其中的可执行文件按外部插件协议作为诊断模块运行，
+                      默认 /usr/local/libexec/ossre/plugins
+  --from-bundle=<f>   离线分析 collect 生成的快照包；未指定 --module/--pid 时沿用采集时的设置
+  --out=<path>        collect 的快照包输出路径；export 的输出目录，未指定时输出到标准输出
+  --listen=<addr>     serve 的监听地址，默认 127.0.0.1:9464；unix:<path> 表示只监听 unix socket
+  --interval=<d>      serve 的定时运行周期，默认 1m；为 0 时只提供 API
+  --api               serve 启用 /api/v1/ 下的 HTTP/JSON 接口
+  --token=<t>, --token-file=<f>
+                      API 访问令牌，请求需携带 Authorization: Bearer <token>
+  --max-concurrent=<n>
+                      API 同时进行的运行数上限，默认 2
+  --run-timeout=<d>   API 单次运行的超时时间上限，默认 5m
+  --apply             fix 执行修复计划，执行前交互确认（--yes 跳过确认）
+  --select=<n,...>    fix 只执行指定序号的动作
+  --rollback=<id>     fix 按备份记录恢复修复前的状态，--history 列出备份记录
+  --state-dir=<dir>   fix 备份记录的保存目录，默认 /var/lib/ossre/fix
+  --unit=<name>       export 的 systemd drop-in 所属服务单元，未指定时不生成 systemd drop-in
+  --ansible           export 额外生成 Ansible 任务文件
+  --fail-on=<level>   diff 中存在新增或级别升高、且级别不低于 level 的发现时以退出码 2 退出
+  --sample-window=<d> 趋势采样窗口（如 60s、5m），maxproc/maxfd 据此预测耗尽时间，
+                      cpu 据此计算窗口内的限流比例
+  --sample-interval=<d>
+                      趋势采样间隔，默认 5s
+  --forecast-threshold=<d>
+                      预计耗尽时间低于该阈值时提升为 error，默认 1h
 
 示例:
   %s list
+  %s list --plugins-dir=/opt/ossre/plugins
+  %s list --tag=network --detail
+  %s run --all --format=plain
+  %s run --tag=capacity --pid=1234 --format=plain
+  %s run --scenario=kernel.net.* --format=plain
+  %s run --module=kernel --suppress-file=configs/suppressions.yaml --role=frontend --format=plain
   %s run --module=kernel
   %s run --module=maxproc --pid=1 --format=plain
   %s run --module=kernel --format=plain
+  %s run --module=maxproc --pid=1 --sample-window=2m --sample-interval=10s
+  %s run --pid-selector=comm:java --format=plain
+  %s run --container=3f4e1a2b9c0d --format=plain
+  %s run --module=cpu --container=3f4e1a2b9c0d --sample-window=30s --format=plain
+  %s run --module=maxproc --root=/host --pid=1234
+  %s run --module=maxproc,maxfd --pid=1234 --format=plain
+  %s collect --out=bundle.tar.gz --pid=1234
+  %s run --from-bundle=bundle.tar.gz --format=plain
+  %s run --module=maxproc,maxfd,kernel --pid=1234 --format=html > report.html
+  %s run --module=kernel,maxproc,maxfd --format=prometheus > ossre.prom
+  %s run --module=rules --rules-dir=configs/rules.d --format=plain
+  %s serve --listen=:9464 --interval=5m --pid=1234
+  %s serve --api --interval=0 --listen=unix:/run/ossre.sock
+  %s diff before.json after.json --format=markdown --fail-on=info
+  %s fix --module=kernel
+  %s fix --module=kernel --apply --select=1,2
+  %s fix --rollback=20261018T101500-a1b2c3
+  %s export report.json --out=deploy --unit=nginx.service --ansible
+  %s preflight --pid=1234
   %s version
-`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+`, os.Args[0], moduleUsage(), os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
 }
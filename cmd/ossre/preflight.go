@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// preflightCaps 为预检中逐项展示的能力。
+var preflightCaps = []string{"CAP_SYS_PTRACE", "CAP_DAC_READ_SEARCH", "CAP_DAC_OVERRIDE", "CAP_SYS_ADMIN", "CAP_SYS_RESOURCE"}
+
+// handlePreflight 检测当前身份、能力集与 namespace，并探测目标进程的数据是否可读，
+// 说明以当前权限运行时哪些模块的检查可能记为未评估。
+func handlePreflight(args []string) {
+	fs := flag.NewFlagSet("preflight", flag.ExitOnError)
+	pid := fs.Int("pid", 1, "探测可读性的目标进程 PID，默认为 1 号进程（代表其他用户的进程）")
+	common := addCommonFlags(fs)
+	_ = fs.Parse(args)
+
+	cfg := common.load(fs)
+	c := collectors.NewCollector(collectors.NewHostFS(cfg.Paths))
+	pf := c.Preflight(*pid)
+
+	euid := "未知"
+	if pf.EUID >= 0 {
+		euid = fmt.Sprint(pf.EUID)
+	}
+	fmt.Printf("有效 UID: %s\n", euid)
+	if pf.CapKnown {
+		var caps []string
+		for _, name := range preflightCaps {
+			mark := "无"
+			if pf.HasCap(name) {
+				mark = "有"
+			}
+			caps = append(caps, name+" "+mark)
+		}
+		fmt.Printf("有效能力: %s\n", strings.Join(caps, ", "))
+	} else {
+		fmt.Println("有效能力: 未知（无法读取 /proc/self/status）")
+	}
+	fmt.Printf("user namespace: %s\n", namespaceLabel(pf.UserNS))
+	fmt.Printf("PID namespace: %s\n", namespaceLabel(pf.PIDNS))
+	if len(pf.Denied) > 0 {
+		fmt.Printf("权限不足无法读取: %s\n", strings.Join(pf.Denied, ", "))
+	} else {
+		fmt.Printf("PID=%d 的 limits、status、fd、task 均可读取或不存在\n", *pid)
+	}
+
+	fmt.Println()
+	fmt.Println("模块:")
+	r := newRunner(core.WithConfig(cfg))
+	for _, p := range r.ListPlugins() {
+		meta := r.Metadata(p.Name())
+		status := "权限满足"
+		if missing := pf.Missing(meta.Privileges); len(missing) > 0 {
+			status = fmt.Sprintf("缺少 %s，读取其他用户进程的数据可能被拒绝，相关检查将记为未评估", strings.Join(missing, "、"))
+		}
+		if pf.UserNS == collectors.NamespaceNested && len(meta.Privileges) > 0 {
+			status += "；运行在非初始 user namespace 中，能力只在该 namespace 内有效"
+		}
+		fmt.Printf("  %-8s %s\n", p.Name(), status)
+	}
+}
+
+func namespaceLabel(s collectors.NamespaceState) string {
+	switch s {
+	case collectors.NamespaceInitial:
+		return "初始（宿主机）"
+	case collectors.NamespaceNested:
+		return "非初始（容器或沙箱内）"
+	default:
+		return "未知"
+	}
+}
+
+// warnSkipped 在存在未评估的检查时于标准错误中提示，避免将没有发现误读为检查通过。
+func warnSkipped(results []models.Result) {
+	var total, denied int
+	for _, result := range results {
+		for _, s := range result.Skipped {
+			total++
+			if s.Permission {
+				denied++
+			}
+		}
+	}
+	if total == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "注意: %d 项检查未评估（其中 %d 项因权限不足），详见报告中的“未评估”部分；运行 %s preflight 查看当前权限\n",
+		total, denied, os.Args[0])
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/plugins/kernel"
+	"github.com/supperghost/ossre/internal/plugins/maxfd"
+	"github.com/supperghost/ossre/internal/plugins/maxproc"
+	"github.com/supperghost/ossre/internal/report"
+	"github.com/supperghost/ossre/internal/server"
+	"github.com/supperghost/ossre/internal/suppress"
+)
+
+// defaultServeModules 为 serve 默认定时运行的模块，均会输出数值指标。
+var defaultServeModules = []string{kernel.PluginName, maxproc.PluginName, maxfd.PluginName}
+
+// unixPrefix 为 --listen 中表示 unix socket 的前缀。
+const unixPrefix = "unix:"
+
+// handleServe 以常驻模式运行：按固定周期执行诊断模块并在 /metrics 上暴露 Prometheus 指标，
+// 指定 --api 时同时在 /api/v1/ 下提供远程触发诊断的 HTTP/JSON 接口。
+func handleServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", "127.0.0.1:9464", "监听地址，如 :9464；unix:/run/ossre.sock 表示只监听 unix socket")
+	module := fs.String("module", strings.Join(defaultServeModules, ","), "定时运行的诊断模块，多个模块以逗号分隔")
+	pid := fs.Int("pid", 0, "定时运行的目标进程 PID，可选；不指定时默认使用自身 PID")
+	container := fs.String("container", "", "定时运行的目标容器 ID，每次运行时重新定位 init 进程，容器重启后仍然有效")
+	interval := fs.Duration("interval", time.Minute, "定时运行周期，同时作为单次运行的超时时间；为 0 时不定时运行，也不提供 /metrics")
+	api := fs.Bool("api", false, "启用 /api/v1/ 下的 HTTP/JSON 接口")
+	token := fs.String("token", "", "API 访问令牌，请求需携带 Authorization: Bearer <token>")
+	tokenFile := fs.String("token-file", "", "从文件读取 API 访问令牌，避免令牌出现在进程参数中")
+	maxConcurrent := fs.Int("max-concurrent", 2, "API 同时进行的运行数上限，超出时返回 429")
+	runTimeout := fs.Duration("run-timeout", 5*time.Minute, "API 单次运行的超时时间上限")
+	common := addCommonFlags(fs)
+	_ = fs.Parse(args)
+
+	if *interval < 0 || *interval == 0 && !*api {
+		fmt.Fprintln(os.Stderr, "--interval 必须大于 0，或通过 --api 只提供 HTTP/JSON 接口")
+		os.Exit(1)
+	}
+	if *tokenFile != "" {
+		data, err := os.ReadFile(*tokenFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "读取令牌文件失败: %v\n", err)
+			os.Exit(1)
+		}
+		*token = strings.TrimSpace(string(data))
+	}
+
+	cfg := common.load(fs)
+	// 定时运行的单次超时时间为一个周期，采样窗口不短于周期时趋势评估总会被取消
+	if *interval > 0 && cfg.Sampling.Window >= *interval {
+		fmt.Fprintf(os.Stderr, "配置中的 sampling.window（%s）必须小于 --interval（%s）\n", cfg.Sampling.Window, *interval)
+		os.Exit(1)
+	}
+	logger := newLogger(*common.verbose)
+	waivers, err := suppress.Load(cfg.Suppress.File)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载豁免文件失败: %v\n", err)
+		os.Exit(1)
+	}
+	host := hostname(cfg)
+	factory := func(opts ...core.Option) *core.Runner {
+		return newRunner(append([]core.Option{core.WithConfig(cfg), core.WithLogger(logger)}, opts...)...)
+	}
+
+	mux := http.NewServeMux()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var endpoints []string
+	if *interval > 0 {
+		names := strings.Split(*module, ",")
+		known := make(map[string]bool)
+		for _, p := range factory().ListPlugins() {
+			known[p.Name()] = true
+		}
+		for _, name := range names {
+			if !known[name] {
+				fmt.Fprintf(os.Stderr, "未知的诊断模块: %s\n", name)
+				os.Exit(1)
+			}
+		}
+		target := core.Target{ContainerID: *container}
+		if *pid > 0 {
+			target.PIDs = []int{*pid}
+		}
+		meta := report.Meta{Version: version, Hostname: host}
+		sched := server.NewScheduler(factory(), names, *interval,
+			server.WithTarget(target), server.WithMeta(meta), server.WithLogger(logger),
+			server.WithSuppressions(waivers, host, cfg.Suppress.Role))
+		mux.Handle("/metrics", sched)
+		go sched.Run(ctx)
+		endpoints = append(endpoints, fmt.Sprintf("/metrics（每 %s 运行 %s）", *interval, strings.Join(names, ",")))
+	}
+	if *api {
+		a := server.NewAPI(factory, cfg,
+			server.WithToken(*token),
+			server.WithMaxConcurrent(*maxConcurrent),
+			server.WithRunTimeout(*runTimeout),
+			server.WithAPILogger(logger),
+			server.WithAPISuppressions(waivers, host, cfg.Suppress.Role))
+		defer a.Close()
+		mux.Handle("/api/", a.Handler())
+		endpoints = append(endpoints, "/api/v1/")
+		if *token == "" && !strings.HasPrefix(*listen, unixPrefix) && !isLoopback(*listen) {
+			fmt.Fprintln(os.Stderr, "警告: API 监听在非本机地址且未设置 --token，任何可访问该地址的客户端都能触发诊断")
+		}
+	}
+
+	ln, err := listenAddr(*listen)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "监听 %s 失败: %v\n", *listen, err)
+		os.Exit(1)
+	}
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second, IdleTimeout: time.Minute}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "ossre serve 已启动，监听 %s: %s\n", *listen, strings.Join(endpoints, "、"))
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "HTTP 服务异常退出: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// listenAddr 监听 TCP 地址或 unix:<path> 形式的 unix socket。
+// unix socket 的权限设置为 0600，只有同一用户（通常为 root）可以访问；残留的 socket 文件会先被删除。
+func listenAddr(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, unixPrefix)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// isLoopback 判断 TCP 监听地址是否只在本机回环地址上可达。
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,63 @@
+// embed 演示在 Go 服务中通过 pkg/ossre 内嵌诊断：启动时检查自身进程的 fd 与线程余量，
+// 并注册一个自定义插件，存在 error 及以上级别的发现时拒绝启动。
+//
+//	go run ./examples/embed
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/supperghost/ossre/pkg/models"
+	"github.com/supperghost/ossre/pkg/ossre"
+)
+
+// tmpPlugin 检查 /tmp 是否可写，是最小的自定义插件示例。
+type tmpPlugin struct{}
+
+func (tmpPlugin) Name() string        { return "tmpdir" }
+func (tmpPlugin) Description() string { return "检查临时目录是否可写" }
+
+func (tmpPlugin) Run(ctx context.Context, env *ossre.Env) (models.Result, error) {
+	result := models.Result{Plugin: "tmpdir"}
+	f, err := os.CreateTemp("", "ossre-embed-")
+	if err != nil {
+		result.Findings = append(result.Findings, models.Finding{
+			ID:          "tmpdir.fs.writable",
+			Title:       "临时目录不可写",
+			Description: err.Error(),
+			Severity:    models.SeverityError,
+		})
+		return result, nil
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return result, nil
+}
+
+func main() {
+	r, err := ossre.New(ossre.WithBuiltins("maxfd", "maxproc"), ossre.WithPlugins(tmpPlugin{}))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	rep, err := r.Run(context.Background(), ossre.Target{PIDs: []int{os.Getpid()}})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := rep.Write(os.Stdout, "plain"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if blocking := rep.Findings(models.SeverityError); len(blocking) > 0 {
+		var titles []string
+		for _, f := range blocking {
+			titles = append(titles, f.Title)
+		}
+		fmt.Fprintf(os.Stderr, "启动检查未通过: %s\n", strings.Join(titles, "；"))
+		os.Exit(1)
+	}
+}
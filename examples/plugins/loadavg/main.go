@@ -0,0 +1,141 @@
+// loadavg 是外部插件协议的参考实现：比较 1 分钟平均负载与 CPU 数，负载过高时产生发现。
+//
+// 编译后放入外部插件目录即可作为 loadavg 模块运行：
+//
+//	go build -o /usr/local/libexec/ossre/plugins/loadavg ./examples/plugins/loadavg
+//	ossre run --module=loadavg --format=plain
+//
+// 外部插件只需依赖 pkg/models 中的结果类型，也可以用任意语言实现同样的协议。
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+const (
+	protocol = 1
+	name     = "loadavg"
+	// 每个 CPU 的平均负载超过该值时产生 warning。
+	perCPUThreshold = 2.0
+)
+
+// request 为 run 请求中本插件用到的字段。
+type request struct {
+	Protocol int `json:"protocol"`
+	Paths    struct {
+		ProcRoot string
+	} `json:"paths"`
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: loadavg describe|run")
+		os.Exit(2)
+	}
+	var out any
+	var err error
+	switch os.Args[1] {
+	case "describe":
+		out = map[string]any{
+			"protocol":    protocol,
+			"name":        name,
+			"description": "平均负载与 CPU 数对比（参考实现）",
+			"version":     "1.0.0",
+			"tags":        []string{"cpu", "capacity"},
+		}
+	case "run":
+		out, err = run()
+	default:
+		err = fmt.Errorf("unknown request %q", os.Args[1])
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() (models.Result, error) {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		return models.Result{}, fmt.Errorf("decode request: %w", err)
+	}
+	if req.Protocol != protocol {
+		return models.Result{}, fmt.Errorf("unsupported protocol %d", req.Protocol)
+	}
+	procRoot := req.Paths.ProcRoot
+	if procRoot == "" {
+		procRoot = "/proc"
+	}
+
+	data, err := os.ReadFile(filepath.Join(procRoot, "loadavg"))
+	if err != nil {
+		return models.Result{}, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return models.Result{}, fmt.Errorf("unexpected loadavg format: %q", data)
+	}
+	load1, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return models.Result{}, fmt.Errorf("parse loadavg: %w", err)
+	}
+	cpus, err := countCPUs(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return models.Result{}, err
+	}
+
+	perCPU := load1 / float64(cpus)
+	result := models.Result{
+		Plugin:      name,
+		Findings:    []models.Finding{},
+		Suggestions: []models.Suggestion{},
+		Metrics: []models.Metric{
+			{Name: "loadavg_per_cpu", Help: "1 分钟平均负载除以 CPU 数", Value: perCPU},
+		},
+	}
+	if perCPU > perCPUThreshold {
+		id := "loadavg.cpu.saturation.load1"
+		result.Findings = append(result.Findings, models.Finding{
+			ID:          id,
+			Title:       "平均负载明显高于 CPU 数",
+			Description: fmt.Sprintf("1 分钟平均负载为 %.2f，CPU 数为 %d，每个 CPU 约 %.2f，高于阈值 %.1f。", load1, cpus, perCPU, perCPUThreshold),
+			Severity:    models.SeverityWarning,
+			Impact:      "可运行任务排队等待 CPU，请求延迟上升。",
+		})
+		result.Suggestions = append(result.Suggestions, models.Suggestion{
+			FindingID: id,
+			Title:     "排查占用 CPU 或处于 D 状态的任务",
+			Details:   "使用 top -H 或 pidstat -u 1 查看 CPU 占用最高的线程；负载高但 CPU 空闲时，用 ps -eo state,pid,comm | grep '^D' 查找等待 I/O 的任务。",
+		})
+	}
+	return result, nil
+}
+
+// countCPUs 统计 /proc/stat 中 cpuN 行的数量。
+func countCPUs(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "cpu") && len(line) > 3 && line[3] >= '0' && line[3] <= '9' {
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("no cpu lines in %s", path)
+	}
+	return n, nil
+}
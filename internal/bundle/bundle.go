@@ -0,0 +1,334 @@
+// Package bundle 实现诊断快照包：在线采集时记录插件读取的全部文件，离线时以同样的数据重放，
+// 使 run --from-bundle 得到与采集现场一致的诊断结果。
+//
+// 快照包为 tar.gz，布局如下：
+//
+//	manifest.json       元数据、目录列表、stat 结果与读取失败记录
+//	rootfs/<path>       读取成功的文件（宿主视角的绝对路径）与符号链接
+//	attachments/<name>  附加材料，如采集时的诊断结果与内核日志片段
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/config"
+)
+
+// FormatVersion 为快照包格式版本，格式不兼容时递增。
+const FormatVersion = 1
+
+const (
+	manifestName   = "manifest.json"
+	rootfsDir      = "rootfs"
+	attachmentsDir = "attachments"
+
+	opRead     = "read"
+	opReadDir  = "readdir"
+	opReadlink = "readlink"
+	opStat     = "stat"
+)
+
+// 常用附件名称。
+const (
+	AttachmentResults = "results.json"
+	AttachmentKmsg    = "kmsg.txt"
+)
+
+// Meta 描述快照包的采集环境。
+type Meta struct {
+	FormatVersion int
+	OssreVersion  string
+	CreatedAt     time.Time
+	Hostname      string
+	KernelRelease string
+	// Target 为采集时的诊断目标，离线运行未指定目标时沿用。
+	Target core.Target
+	// Plugins 为采集时运行的插件，离线运行未指定模块时沿用。
+	Plugins []string
+	// Paths 为采集时 proc/sys/etc 的实际位置，仅供参考。
+	Paths config.PathsConfig
+}
+
+type manifest struct {
+	Meta   Meta
+	Dirs   map[string][]string
+	Stats  map[string]statInfo
+	Errors map[string]recordedError
+}
+
+type statInfo struct {
+	Mode fs.FileMode
+	Size int64
+}
+
+// recordedError 保存采集时的错误信息，并保留“不存在”“无权限”两类语义，以便离线时 errors.Is 判断一致。
+type recordedError struct {
+	Msg  string
+	Kind string `json:",omitempty"`
+}
+
+func newRecordedError(err error) recordedError {
+	e := recordedError{Msg: err.Error()}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		e.Kind = "not_exist"
+	case errors.Is(err, fs.ErrPermission):
+		e.Kind = "permission"
+	}
+	return e
+}
+
+func (e recordedError) Error() string { return e.Msg }
+
+func (e recordedError) Is(target error) bool {
+	switch target {
+	case fs.ErrNotExist:
+		return e.Kind == "not_exist"
+	case fs.ErrPermission:
+		return e.Kind == "permission"
+	}
+	return false
+}
+
+func errKey(op, name string) string {
+	return op + " " + name
+}
+
+// Write 将 rec 记录的全部访问结果、meta 与附件写为 tar.gz 快照包。
+func Write(w io.Writer, rec *Recorder, meta Meta, attachments map[string][]byte) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	meta.FormatVersion = FormatVersion
+	m := manifest{
+		Meta:   meta,
+		Dirs:   rec.dirs,
+		Stats:  rec.stats,
+		Errors: rec.errs,
+	}
+	manifestData, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	modTime := meta.CreatedAt
+
+	writeFile := func(name string, data []byte) error {
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0o644,
+			Size:     int64(len(data)),
+			ModTime:  modTime,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := writeFile(manifestName, manifestData); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	for _, name := range sortedKeys(rec.files) {
+		if err := writeFile(rootfsDir+name, rec.files[name]); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	for _, name := range sortedKeys(rec.links) {
+		hdr := &tar.Header{
+			Typeflag: tar.TypeSymlink,
+			Name:     rootfsDir + name,
+			Linkname: rec.links[name],
+			Mode:     0o777,
+			ModTime:  modTime,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("write link %s: %w", name, err)
+		}
+	}
+	for _, name := range sortedKeys(attachments) {
+		if err := writeFile(path.Join(attachmentsDir, name), attachments[name]); err != nil {
+			return fmt.Errorf("write attachment %s: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Bundle 是已加载到内存中的快照包，实现了 collectors.FS，可直接作为插件的数据来源。
+// 未被记录的路径一律返回 fs.ErrNotExist。
+type Bundle struct {
+	meta        Meta
+	files       map[string][]byte
+	links       map[string]string
+	dirs        map[string][]string
+	stats       map[string]statInfo
+	errs        map[string]recordedError
+	attachments map[string][]byte
+}
+
+// Open 读取并解析指定路径的快照包。
+func Open(name string) (*Bundle, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open bundle: %w", err)
+	}
+	defer f.Close()
+	b, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("read bundle %s: %w", name, err)
+	}
+	return b, nil
+}
+
+// Read 从 r 中读取 tar.gz 格式的快照包。
+func Read(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	b := &Bundle{
+		files:       make(map[string][]byte),
+		links:       make(map[string]string),
+		attachments: make(map[string][]byte),
+	}
+	var sawManifest bool
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case hdr.Name == manifestName:
+			var m manifest
+			if err := json.NewDecoder(tr).Decode(&m); err != nil {
+				return nil, fmt.Errorf("decode manifest: %w", err)
+			}
+			if m.Meta.FormatVersion != FormatVersion {
+				return nil, fmt.Errorf("unsupported bundle format version %d (want %d)", m.Meta.FormatVersion, FormatVersion)
+			}
+			b.meta, b.dirs, b.stats, b.errs = m.Meta, m.Dirs, m.Stats, m.Errors
+			sawManifest = true
+		case strings.HasPrefix(hdr.Name, rootfsDir+"/"):
+			name := strings.TrimPrefix(hdr.Name, rootfsDir)
+			switch hdr.Typeflag {
+			case tar.TypeSymlink:
+				b.links[name] = hdr.Linkname
+			case tar.TypeReg:
+				data, err := io.ReadAll(tr)
+				if err != nil {
+					return nil, fmt.Errorf("read %s: %w", name, err)
+				}
+				b.files[name] = data
+			}
+		case strings.HasPrefix(hdr.Name, attachmentsDir+"/"):
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", hdr.Name, err)
+			}
+			b.attachments[strings.TrimPrefix(hdr.Name, attachmentsDir+"/")] = data
+		}
+	}
+	if !sawManifest {
+		return nil, fmt.Errorf("missing %s", manifestName)
+	}
+	return b, nil
+}
+
+// Meta 返回快照包的采集元数据。
+func (b *Bundle) Meta() Meta {
+	return b.meta
+}
+
+// Attachment 返回指定名称的附件内容。
+func (b *Bundle) Attachment(name string) ([]byte, bool) {
+	data, ok := b.attachments[name]
+	return data, ok
+}
+
+func (b *Bundle) lookupErr(op, name string) error {
+	if e, ok := b.errs[errKey(op, name)]; ok {
+		return e
+	}
+	return &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+func (b *Bundle) ReadFile(name string) ([]byte, error) {
+	name = path.Clean(name)
+	if data, ok := b.files[name]; ok {
+		return data, nil
+	}
+	return nil, b.lookupErr(opRead, name)
+}
+
+func (b *Bundle) ReadDirNames(name string) ([]string, error) {
+	name = path.Clean(name)
+	if names, ok := b.dirs[name]; ok {
+		return append([]string(nil), names...), nil
+	}
+	return nil, b.lookupErr(opReadDir, name)
+}
+
+func (b *Bundle) Readlink(name string) (string, error) {
+	name = path.Clean(name)
+	if target, ok := b.links[name]; ok {
+		return target, nil
+	}
+	return "", b.lookupErr(opReadlink, name)
+}
+
+func (b *Bundle) Stat(name string) (fs.FileInfo, error) {
+	name = path.Clean(name)
+	if st, ok := b.stats[name]; ok {
+		return fileInfo{name: path.Base(name), stat: st, modTime: b.meta.CreatedAt}, nil
+	}
+	return nil, b.lookupErr(opStat, name)
+}
+
+// fileInfo 根据采集时记录的 stat 结果实现 fs.FileInfo。
+type fileInfo struct {
+	name    string
+	stat    statInfo
+	modTime time.Time
+}
+
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) Size() int64        { return fi.stat.Size }
+func (fi fileInfo) Mode() fs.FileMode  { return fi.stat.Mode }
+func (fi fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi fileInfo) IsDir() bool        { return fi.stat.Mode.IsDir() }
+func (fi fileInfo) Sys() any           { return nil }
@@ -0,0 +1,108 @@
+package bundle
+
+import (
+	"path"
+	"sort"
+
+	"github.com/supperghost/ossre/internal/collectors"
+)
+
+// maxSysctlFiles 限制 /proc/sys 遍历的文件数，避免在网卡或容器数量极多的主机上生成过大的快照包。
+const maxSysctlFiles = 10000
+
+// extraFiles 为插件未必读取、但内核团队排查问题时通常需要的系统级文件。
+var extraFiles = []string{
+	"/proc/version",
+	"/proc/cmdline",
+	"/proc/uptime",
+	"/proc/loadavg",
+	"/proc/meminfo",
+	"/proc/stat",
+	"/proc/vmstat",
+	"/proc/zoneinfo",
+	"/proc/buddyinfo",
+	"/proc/swaps",
+	"/proc/diskstats",
+	"/proc/interrupts",
+	"/proc/softirqs",
+	"/proc/pressure/cpu",
+	"/proc/pressure/memory",
+	"/proc/pressure/io",
+	"/proc/self/mountinfo",
+	"/proc/net/dev",
+	"/proc/net/snmp",
+	"/proc/net/netstat",
+	"/proc/net/sockstat",
+	"/proc/net/sockstat6",
+	"/proc/net/softnet_stat",
+	"/etc/os-release",
+	"/etc/sysctl.conf",
+	"/etc/security/limits.conf",
+}
+
+// extraDirs 下的所有普通文件都会被采集（不递归）。
+var extraDirs = []string{
+	"/etc/sysctl.d",
+	"/etc/security/limits.d",
+	"/sys/fs/cgroup",
+}
+
+// sysctlSkip 中的目录在遍历 /proc/sys 时跳过：binfmt_misc 通常由 automount 挂载，stat 会触发挂载。
+var sysctlSkip = map[string]bool{
+	"/proc/sys/fs/binfmt_misc": true,
+}
+
+// CaptureExtras 通过 fsys（通常为 Recorder）读取额外的系统级文件与全部 sysctl，
+// 读取失败的文件同样会被 Recorder 记录，不视为错误。
+func CaptureExtras(fsys collectors.FS) {
+	for _, name := range extraFiles {
+		_, _ = fsys.ReadFile(name)
+	}
+	for _, dir := range extraDirs {
+		names, err := fsys.ReadDirNames(dir)
+		if err != nil {
+			continue
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p := path.Join(dir, name)
+			if st, err := fsys.Stat(p); err == nil && st.Mode().IsRegular() {
+				_, _ = fsys.ReadFile(p)
+			}
+		}
+	}
+	budget := maxSysctlFiles
+	walkSysctl(fsys, "/proc/sys", &budget)
+}
+
+// walkSysctl 深度优先遍历 /proc/sys，读取所有可读的参数文件。
+func walkSysctl(fsys collectors.FS, dir string, budget *int) {
+	names, err := fsys.ReadDirNames(dir)
+	if err != nil {
+		return
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if *budget <= 0 {
+			return
+		}
+		p := path.Join(dir, name)
+		if sysctlSkip[p] {
+			continue
+		}
+		st, err := fsys.Stat(p)
+		if err != nil {
+			continue
+		}
+		if st.IsDir() {
+			walkSysctl(fsys, p, budget)
+			continue
+		}
+		// 只写参数（如 vm.compact_memory）没有读权限，跳过以免记录大量无意义的错误
+		if st.Mode().Perm()&0o444 == 0 {
+			continue
+		}
+		*budget--
+		_, _ = fsys.ReadFile(p)
+	}
+}
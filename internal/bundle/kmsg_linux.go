@@ -0,0 +1,44 @@
+//go:build linux
+// +build linux
+
+package bundle
+
+import (
+	"bytes"
+	"syscall"
+)
+
+const (
+	// klogctl(2) 的操作码
+	syslogActionReadAll    = 3
+	syslogActionSizeBuffer = 10
+)
+
+// ReadKmsg 读取内核环形缓冲区中最近的 maxLines 行日志（等同于 dmesg 的尾部）。
+// 需要 CAP_SYSLOG 或 kernel.dmesg_restrict=0。
+func ReadKmsg(maxLines int) ([]byte, error) {
+	size, err := syscall.Klogctl(syslogActionSizeBuffer, nil)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	n, err := syscall.Klogctl(syslogActionReadAll, buf)
+	if err != nil {
+		return nil, err
+	}
+	return tailLines(buf[:n], maxLines), nil
+}
+
+// tailLines 返回 data 的最后 n 行。
+func tailLines(data []byte, n int) []byte {
+	data = bytes.TrimRight(data, "\n")
+	idx := len(data)
+	for i := 0; i < n; i++ {
+		j := bytes.LastIndexByte(data[:idx], '\n')
+		if j < 0 {
+			return append(data, '\n')
+		}
+		idx = j
+	}
+	return append(data[idx+1:], '\n')
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package bundle
+
+import "errors"
+
+// ReadKmsg 在非 Linux 平台上不可用。
+func ReadKmsg(maxLines int) ([]byte, error) {
+	return nil, errors.New("kernel log is only available on linux")
+}
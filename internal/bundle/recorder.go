@@ -0,0 +1,135 @@
+package bundle
+
+import (
+	"io/fs"
+	"path"
+	"sync"
+
+	"github.com/supperghost/ossre/internal/collectors"
+)
+
+// Recorder 包装一个 FS，在透传读取的同时记录每一次访问的结果（包括失败），
+// 供 Write 打包成快照，使离线分析时各插件读到与采集时完全一致的数据。
+// 同一路径被多次访问时以首次结果为准，避免后续补充采集覆盖插件实际使用的数据。
+// 进程的环境变量文件只记录 collectors.RedactEnviron 保留的变量，其余变量可能包含密钥，不写入快照包。
+type Recorder struct {
+	fs collectors.FS
+
+	mu    sync.Mutex
+	files map[string][]byte
+	dirs  map[string][]string
+	links map[string]string
+	stats map[string]statInfo
+	errs  map[string]recordedError
+}
+
+// NewRecorder 创建一个记录 fsys 访问结果的 Recorder。
+func NewRecorder(fsys collectors.FS) *Recorder {
+	return &Recorder{
+		fs:    fsys,
+		files: make(map[string][]byte),
+		dirs:  make(map[string][]string),
+		links: make(map[string]string),
+		stats: make(map[string]statInfo),
+		errs:  make(map[string]recordedError),
+	}
+}
+
+func (r *Recorder) recordErr(op, name string, err error) {
+	key := errKey(op, name)
+	r.mu.Lock()
+	if !r.seen(op, name) {
+		r.errs[key] = newRecordedError(err)
+	}
+	r.mu.Unlock()
+}
+
+// seen 判断 op 对 name 的访问是否已被记录，调用方需持有 mu。
+func (r *Recorder) seen(op, name string) bool {
+	if _, ok := r.errs[errKey(op, name)]; ok {
+		return true
+	}
+	var ok bool
+	switch op {
+	case opRead:
+		_, ok = r.files[name]
+	case opReadDir:
+		_, ok = r.dirs[name]
+	case opReadlink:
+		_, ok = r.links[name]
+	case opStat:
+		_, ok = r.stats[name]
+	}
+	return ok
+}
+
+func (r *Recorder) ReadFile(name string) ([]byte, error) {
+	name = path.Clean(name)
+	data, err := r.fs.ReadFile(name)
+	if err != nil {
+		r.recordErr(opRead, name, err)
+		return nil, err
+	}
+	r.mu.Lock()
+	if !r.seen(opRead, name) {
+		if collectors.IsEnvironPath(name) {
+			r.files[name] = collectors.RedactEnviron(data)
+		} else {
+			r.files[name] = data
+		}
+	}
+	r.mu.Unlock()
+	return data, nil
+}
+
+func (r *Recorder) ReadDirNames(name string) ([]string, error) {
+	name = path.Clean(name)
+	names, err := r.fs.ReadDirNames(name)
+	if err != nil {
+		r.recordErr(opReadDir, name, err)
+		return nil, err
+	}
+	r.mu.Lock()
+	if !r.seen(opReadDir, name) {
+		r.dirs[name] = append([]string(nil), names...)
+	}
+	r.mu.Unlock()
+	return names, nil
+}
+
+func (r *Recorder) Readlink(name string) (string, error) {
+	name = path.Clean(name)
+	target, err := r.fs.Readlink(name)
+	if err != nil {
+		r.recordErr(opReadlink, name, err)
+		return "", err
+	}
+	r.mu.Lock()
+	if !r.seen(opReadlink, name) {
+		r.links[name] = target
+	}
+	r.mu.Unlock()
+	return target, nil
+}
+
+func (r *Recorder) Stat(name string) (fs.FileInfo, error) {
+	name = path.Clean(name)
+	fi, err := r.fs.Stat(name)
+	if err != nil {
+		r.recordErr(opStat, name, err)
+		return nil, err
+	}
+	r.mu.Lock()
+	if !r.seen(opStat, name) {
+		r.stats[name] = statInfo{Mode: fi.Mode(), Size: fi.Size()}
+	}
+	r.mu.Unlock()
+	return fi, nil
+}
+
+// Len 返回已记录的文件数量。
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.files)
+}
@@ -0,0 +1,346 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	cgroupRoot = "/sys/fs/cgroup"
+	// cgroup v1 中 memory.limit_in_bytes 不小于该值时视为无限制（内核以接近 int64 上限的值表示）
+	cgroupV1MemoryUnlimited = int64(1) << 62
+)
+
+// CgroupDir 返回进程在指定控制器下、包含 v2File（v2）或 v1File（v1）的 cgroup 目录及其版本（"v2" 或 "v1"）。
+// 优先查找 cgroup v2 统一层级中进程所在目录，其次是 /sys/fs/cgroup 根目录（容器内开启 cgroup namespace 时
+// 进程路径可能与挂载点不一致），最后是 cgroup v1 中对应控制器的层级；均不存在时 ok 为 false。
+func (c *Collector) CgroupDir(pid int, controller, v2File, v1File string) (dir, version string, ok bool) {
+	cgroups, _ := c.Cgroups(pid)
+	if p, found := cgroups.Unified(); found && v2File != "" {
+		if d := path.Join(cgroupRoot, p); c.isFile(path.Join(d, v2File)) {
+			return d, "v2", true
+		}
+	}
+	if v2File != "" && c.isFile(path.Join(cgroupRoot, v2File)) {
+		return cgroupRoot, "v2", true
+	}
+	if p, found := cgroups.Controller(controller); found && v1File != "" {
+		if d := path.Join(cgroupRoot, controller, p); c.isFile(path.Join(d, v1File)) {
+			return d, "v1", true
+		}
+	}
+	return "", "", false
+}
+
+func (c *Collector) isFile(name string) bool {
+	st, err := c.Stat(name)
+	return err == nil && !st.IsDir()
+}
+
+// CgroupPids 为 pids 控制器的上限与当前任务数；Version 为 "none" 时表示未找到 pids 限制。
+type CgroupPids struct {
+	Version string
+	Dir     string
+	// Max 为 pids.max，取值为 Unlimited 时表示无限制。
+	Max     int64
+	Current int64
+	// Err 为读取 pids.max 或 pids.current 时遇到的错误，非 nil 时 Max 与 Current 不可信。
+	Err error
+}
+
+// CgroupPids 读取进程所在 cgroup 的 pids.max 与 pids.current（v2 或 v1）。
+func (c *Collector) CgroupPids(pid int) CgroupPids {
+	dir, version, ok := c.CgroupDir(pid, "pids", "pids.max", "pids.max")
+	if !ok {
+		return CgroupPids{Version: "none", Max: Unlimited}
+	}
+	p := CgroupPids{Version: version, Dir: dir}
+	var errMax, errCur error
+	p.Max, errMax = c.readCgroupLimit(path.Join(dir, "pids.max"))
+	p.Current, errCur = c.readCgroupInt(path.Join(dir, "pids.current"))
+	p.Err = errors.Join(errMax, errCur)
+	return p
+}
+
+// CgroupMemory 为 memory 控制器的上限与当前用量（字节）；Version 为 "none" 时表示未找到内存限制文件。
+type CgroupMemory struct {
+	Version string
+	Dir     string
+	// Limit 为 v2 的 memory.max 或 v1 的 memory.limit_in_bytes，取值为 Unlimited 时表示无限制。
+	Limit int64
+	Usage int64
+	// Err 为读取上限或用量文件时遇到的错误，非 nil 时 Limit 与 Usage 不可信。
+	Err error
+}
+
+// CgroupMemory 读取进程所在 cgroup 的内存上限与当前用量（v2 或 v1）。
+func (c *Collector) CgroupMemory(pid int) CgroupMemory {
+	dir, version, ok := c.CgroupDir(pid, "memory", "memory.max", "memory.limit_in_bytes")
+	if !ok {
+		return CgroupMemory{Version: "none", Limit: Unlimited}
+	}
+	m := CgroupMemory{Version: version, Dir: dir}
+	var errLimit, errUsage error
+	if version == "v2" {
+		m.Limit, errLimit = c.readCgroupLimit(path.Join(dir, "memory.max"))
+		m.Usage, errUsage = c.readCgroupInt(path.Join(dir, "memory.current"))
+	} else {
+		m.Limit, errLimit = c.readCgroupLimit(path.Join(dir, "memory.limit_in_bytes"))
+		if m.Limit >= cgroupV1MemoryUnlimited {
+			m.Limit = Unlimited
+		}
+		m.Usage, errUsage = c.readCgroupInt(path.Join(dir, "memory.usage_in_bytes"))
+	}
+	m.Err = errors.Join(errLimit, errUsage)
+	return m
+}
+
+// readCgroupLimit 解析 pids.max、memory.max 等限制文件，"max" 或无法解析时返回 Unlimited；
+// 无法读取时同样返回 Unlimited 并返回读取错误，由调用方决定是否将该限制记为未评估。
+func (c *Collector) readCgroupLimit(name string) (int64, error) {
+	data, err := c.ReadFile(name)
+	if err != nil {
+		return Unlimited, err
+	}
+	s := strings.TrimSpace(string(data))
+	if s == "max" || s == "unlimited" {
+		return Unlimited, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return Unlimited, nil
+	}
+	return v, nil
+}
+
+// readCgroupInt 读取单个整数值的 cgroup 文件，无法读取时返回 0 与读取错误，无法解析时返回 0。
+func (c *Collector) readCgroupInt(name string) (int64, error) {
+	data, err := c.ReadFile(name)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, nil
+	}
+	return v, nil
+}
+
+// CgroupCPU 为 cpu 控制器的 CFS 带宽限制；Version 为 "none" 时表示未找到 cpu 配额文件。
+type CgroupCPU struct {
+	Version string
+	Dir     string
+	// Quota 为每个周期内可使用的 CPU 时间（微秒），取值为 Unlimited 时表示无限制。
+	Quota int64
+	// Period 为 CFS 调度周期（微秒）。
+	Period int64
+	// Err 为读取配额文件时遇到的错误，非 nil 时 Quota 与 Period 不可信。
+	Err error
+}
+
+// CPUs 返回配额折合的 CPU 核数，无限制时返回 0。
+func (q CgroupCPU) CPUs() float64 {
+	if q.Quota == Unlimited || q.Quota <= 0 || q.Period <= 0 {
+		return 0
+	}
+	return float64(q.Quota) / float64(q.Period)
+}
+
+// CgroupCPU 读取进程所在 cgroup 的 CPU 配额：v2 的 cpu.max（"<quota> <period>"，quota 为 "max" 表示无限制），
+// v1 的 cpu.cfs_quota_us 与 cpu.cfs_period_us（quota 为 -1 表示无限制）。
+func (c *Collector) CgroupCPU(pid int) CgroupCPU {
+	dir, version, ok := c.CgroupDir(pid, "cpu", "cpu.max", "cpu.cfs_quota_us")
+	if !ok {
+		return CgroupCPU{Version: "none", Quota: Unlimited}
+	}
+	q := CgroupCPU{Version: version, Dir: dir, Quota: Unlimited}
+	if version == "v2" {
+		data, err := c.ReadFile(path.Join(dir, "cpu.max"))
+		if err != nil {
+			q.Err = err
+			return q
+		}
+		fields := strings.Fields(string(data))
+		if len(fields) >= 1 && fields[0] != "max" {
+			if v, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+				q.Quota = v
+			}
+		}
+		if len(fields) >= 2 {
+			q.Period, _ = strconv.ParseInt(fields[1], 10, 64)
+		}
+		return q
+	}
+	var errQuota, errPeriod error
+	q.Quota, errQuota = c.readCgroupInt(path.Join(dir, "cpu.cfs_quota_us"))
+	if q.Quota < 0 {
+		q.Quota = Unlimited
+	}
+	q.Period, errPeriod = c.readCgroupInt(path.Join(dir, "cpu.cfs_period_us"))
+	q.Err = errors.Join(errQuota, errPeriod)
+	return q
+}
+
+// CgroupCpuset 为 cpuset 控制器下进程可运行的 CPU 集合；Version 为 "none" 时表示未找到 cpuset 文件。
+type CgroupCpuset struct {
+	Version string
+	Dir     string
+	// CPUs 为 CPU 列表，如 "0-3,8"。
+	CPUs string
+	// Count 为列表中的 CPU 数。
+	Count int
+	Err   error
+}
+
+// CgroupCpuset 读取进程所在 cgroup 实际生效的 CPU 集合（v2 的 cpuset.cpus.effective、v1 的 cpuset.effective_cpus）。
+func (c *Collector) CgroupCpuset(pid int) CgroupCpuset {
+	v2File, v1File := "cpuset.cpus.effective", "cpuset.effective_cpus"
+	dir, version, ok := c.CgroupDir(pid, "cpuset", v2File, v1File)
+	if !ok {
+		return CgroupCpuset{Version: "none"}
+	}
+	s := CgroupCpuset{Version: version, Dir: dir}
+	name := v2File
+	if version == "v1" {
+		name = v1File
+	}
+	data, err := c.ReadFile(path.Join(dir, name))
+	if err != nil {
+		s.Err = err
+		return s
+	}
+	s.CPUs = strings.TrimSpace(string(data))
+	s.Count, s.Err = ParseCPUList(s.CPUs)
+	return s
+}
+
+// ParseCPUList 返回 "0-3,8" 形式的 CPU 列表中的 CPU 数。
+func ParseCPUList(s string) (int, error) {
+	var n int
+	for _, part := range strings.Split(strings.TrimSpace(s), ",") {
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		a, err := strconv.Atoi(lo)
+		if err != nil {
+			return 0, fmt.Errorf("invalid cpu list %q", s)
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(hi); err != nil || b < a {
+				return 0, fmt.Errorf("invalid cpu list %q", s)
+			}
+		}
+		n += b - a + 1
+	}
+	return n, nil
+}
+
+// CgroupCPUStat 为 cpu 控制器的 CFS 带宽统计（cpu.stat），均为自 cgroup 创建以来的累计值；
+// Version 为 "none" 时表示未找到 cpu.stat。
+type CgroupCPUStat struct {
+	Version string
+	Dir     string
+	// NrPeriods 为 cgroup 有可运行任务的调度周期数，NrThrottled 为其中用尽配额被限流的周期数。
+	NrPeriods   uint64
+	NrThrottled uint64
+	// ThrottledUsec 为累计被限流的时间（微秒），v1 的 throttled_time 以纳秒为单位，已换算。
+	ThrottledUsec uint64
+	// UsageUsec 为累计使用的 CPU 时间（微秒），v1 取自 cpuacct.usage；cpu 与 cpuacct
+	// 分别挂载且进程在两个层级中的路径不同时无法对应到同一 cgroup，此时为 0。
+	UsageUsec uint64
+	Err       error
+}
+
+// CgroupCPUStat 读取进程所在 cgroup 的 cpu.stat（v2 或 v1）。
+func (c *Collector) CgroupCPUStat(pid int) CgroupCPUStat {
+	dir, version, ok := c.CgroupDir(pid, "cpu", "cpu.stat", "cpu.stat")
+	if !ok {
+		return CgroupCPUStat{Version: "none"}
+	}
+	s := CgroupCPUStat{Version: version, Dir: dir}
+	data, err := c.ReadFile(path.Join(dir, "cpu.stat"))
+	if err != nil {
+		s.Err = err
+		return s
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "nr_periods":
+			s.NrPeriods = v
+		case "nr_throttled":
+			s.NrThrottled = v
+		case "throttled_usec":
+			s.ThrottledUsec = v
+		case "throttled_time":
+			s.ThrottledUsec = v / 1000
+		case "usage_usec":
+			s.UsageUsec = v
+		}
+	}
+	if version == "v1" {
+		cgroups, _ := c.Cgroups(pid)
+		cpuPath, _ := cgroups.Controller("cpu")
+		if p, found := cgroups.Controller("cpuacct"); !found || p != cpuPath {
+			return s
+		}
+		if d, _, ok := c.CgroupDir(pid, "cpuacct", "", "cpuacct.usage"); ok {
+			if ns, err := c.readCgroupInt(path.Join(d, "cpuacct.usage")); err == nil && ns > 0 {
+				s.UsageUsec = uint64(ns) / 1000
+			}
+		}
+	}
+	return s
+}
+
+// CgroupThreads 返回进程所在 cpu cgroup 中的全部线程 ID（v2 的 cgroup.threads、v1 的 tasks），
+// 不包含子 cgroup 中的线程。
+func (c *Collector) CgroupThreads(pid int) ([]int, error) {
+	dir, version, ok := c.CgroupDir(pid, "cpu", "cgroup.threads", "tasks")
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	name := "cgroup.threads"
+	if version == "v1" {
+		name = "tasks"
+	}
+	data, err := c.ReadFile(path.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	var tids []int
+	for _, f := range strings.Fields(string(data)) {
+		if tid, err := strconv.Atoi(f); err == nil {
+			tids = append(tids, tid)
+		}
+	}
+	return tids, nil
+}
+
+// EffectiveCPUs 返回进程实际可用的 CPU 数：CPU 配额、cpuset 与在线 CPU 数中的最小值，均无法确定时返回 0。
+func (c *Collector) EffectiveCPUs(pid int) float64 {
+	var n float64
+	if st, err := c.SystemStat(); err == nil && len(st.PerCPU) > 0 {
+		n = float64(len(st.PerCPU))
+	}
+	if s := c.CgroupCpuset(pid); s.Err == nil && s.Count > 0 && (n == 0 || float64(s.Count) < n) {
+		n = float64(s.Count)
+	}
+	if q := c.CgroupCPU(pid); q.Err == nil && q.CPUs() > 0 && (n == 0 || q.CPUs() < n) {
+		n = q.CPUs()
+	}
+	return n
+}
@@ -1,19 +1,109 @@
 package collectors
 
-import "context"
+import (
+	"io/fs"
+	"sync"
+)
 
-// TODO: 本包负责从 /proc、/sys 以及其他系统接口中采集原始数据，供诊断插件复用。
-// 这里仅提供占位定义，后续可拆分为多个源文件（如 procfs.go、sysfs.go 等）。
+// 本包负责从 /proc、/sys 以及 /etc 中采集原始数据并解析为类型化结构，供诊断插件复用。
+// 按数据来源拆分为多个源文件：procfs.go（进程级）、system.go（系统级与 sysctl）、
+// net.go（/proc/net）、disk.go（/proc/diskstats）、cgroup.go（cgroup v1/v2）；
+// sampling.go 提供按间隔轮询计数器并计算速率与分位数的采样引擎。
 
-// Sample is a占位结构体，用于表示一次采集到的原始样本。
-type Sample struct {
-	// TODO: 根据实际需求扩展字段，例如 Key、Value、Timestamp 等。
-	Source string
-	Data   string
+// Collector 在 FS 之上提供带缓存的类型化采集接口。
+// 同一个 Collector 内每个文件、目录或链接只读取一次，多个插件或同一插件内的多个场景共享读取结果；
+// 需要观察数据变化（如趋势采样）时，应通过 Fresh 获取一个缓存为空的新实例。
+// Collector 自身也实现了 FS，可直接传给只需要原始文件访问的代码。
+type Collector struct {
+	fs FS
+
+	mu    sync.Mutex
+	cache map[cacheKey]*cacheEntry
+}
+
+type cacheKey struct {
+	op   string
+	name string
+}
+
+type cacheEntry struct {
+	once sync.Once
+	val  any
+	err  error
+}
+
+// NewCollector 创建一个基于 fsys 的采集器，缓存生命周期与返回的实例一致。
+func NewCollector(fsys FS) *Collector {
+	return &Collector{
+		fs:    fsys,
+		cache: make(map[cacheKey]*cacheEntry),
+	}
+}
+
+// Fresh 返回共享同一底层 FS、但缓存为空的新采集器。
+func (c *Collector) Fresh() *Collector {
+	return NewCollector(c.fs)
+}
+
+// FS 返回未经缓存的底层文件系统。
+func (c *Collector) FS() FS {
+	return c.fs
+}
+
+// load 返回 key 对应的缓存结果，首次访问时调用 fn 读取；并发访问同一 key 时 fn 只执行一次。
+func (c *Collector) load(op, name string, fn func() (any, error)) (any, error) {
+	key := cacheKey{op: op, name: name}
+	c.mu.Lock()
+	e, ok := c.cache[key]
+	if !ok {
+		e = &cacheEntry{}
+		c.cache[key] = e
+	}
+	c.mu.Unlock()
+
+	e.once.Do(func() {
+		e.val, e.err = fn()
+	})
+	return e.val, e.err
+}
+
+// ReadFile 返回缓存的文件内容，返回的切片在所有调用方之间共享，不得修改。
+func (c *Collector) ReadFile(name string) ([]byte, error) {
+	v, err := c.load("read", name, func() (any, error) {
+		return c.fs.ReadFile(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+func (c *Collector) ReadDirNames(name string) ([]string, error) {
+	v, err := c.load("readdir", name, func() (any, error) {
+		return c.fs.ReadDirNames(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]string), nil
+}
+
+func (c *Collector) Readlink(name string) (string, error) {
+	v, err := c.load("readlink", name, func() (any, error) {
+		return c.fs.Readlink(name)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
 }
 
-// Collector 是采集器接口的占位定义。
-type Collector interface {
-	// Collect 执行一次采集并返回零个或多个样本。
-	Collect(ctx context.Context) ([]Sample, error)
+func (c *Collector) Stat(name string) (fs.FileInfo, error) {
+	v, err := c.load("stat", name, func() (any, error) {
+		return c.fs.Stat(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(fs.FileInfo), nil
 }
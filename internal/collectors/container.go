@@ -0,0 +1,191 @@
+package collectors
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Container 为按容器 ID 在 /proc/*/cgroup 中定位到的容器。
+type Container struct {
+	// ID 为完整的 64 位十六进制容器 ID。
+	ID string
+	// Runtime 为根据 cgroup 路径推断的运行时：docker、containerd、cri-o、podman，
+	// cgroupfs 驱动下的 Kubernetes 容器无法区分运行时，记为 kubernetes。
+	Runtime string
+	// PID 为容器的 init 进程（容器内最早启动的进程）。
+	PID int
+	// PIDs 为 cgroup 路径中包含该容器 ID 的全部进程，升序排列。
+	PIDs []int
+	// CgroupPath 为 init 进程所在的 cgroup 路径，优先取 v2 统一层级。
+	CgroupPath string
+}
+
+// containerScopePrefixes 为 systemd cgroup 驱动下容器 scope 单元名称的前缀与对应的运行时，
+// conmon 等监控进程所在的 scope 不属于容器本身，对应的运行时为空。
+var containerScopePrefixes = []struct{ prefix, runtime string }{
+	{"crio-conmon-", ""},
+	{"libpod-conmon-", ""},
+	{"docker-", "docker"},
+	{"cri-containerd-", "containerd"},
+	{"crio-", "cri-o"},
+	{"libpod-", "podman"},
+}
+
+// ParseContainerCgroup 从 cgroup 路径中提取容器 ID 与运行时，支持以下约定：
+//
+//	/docker/<id>                                  docker（cgroupfs 驱动）
+//	/system.slice/docker-<id>.scope               docker（systemd 驱动）
+//	/kubepods/burstable/pod<uid>/<id>             Kubernetes（cgroupfs 驱动）
+//	/kubepods.slice/.../cri-containerd-<id>.scope containerd（systemd 驱动）
+//	/kubepods.slice/.../crio-<id>.scope           CRI-O（systemd 驱动）
+//	/machine.slice/libpod-<id>.scope              podman
+//	/<namespace>/<id>                             containerd（ctr 等直接创建的容器）
+//
+// 取路径中最内层的容器 ID，路径中不含容器 ID 时 ok 为 false。
+func ParseContainerCgroup(p string) (id, runtime string, ok bool) {
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		name := strings.TrimSuffix(parts[i], ".scope")
+		for _, s := range containerScopePrefixes {
+			if strings.HasPrefix(name, s.prefix) {
+				if s.runtime == "" || !isContainerID(name[len(s.prefix):]) {
+					return "", "", false
+				}
+				return name[len(s.prefix):], s.runtime, true
+			}
+		}
+		if !isContainerID(name) {
+			continue
+		}
+		runtime := "containerd"
+		for _, parent := range parts[:i] {
+			switch {
+			case parent == "docker":
+				runtime = "docker"
+			case strings.HasPrefix(parent, "kubepods"):
+				runtime = "kubernetes"
+			}
+		}
+		return name, runtime, true
+	}
+	return "", "", false
+}
+
+// isContainerID 判断 s 是否为 64 位十六进制的完整容器 ID。
+func isContainerID(s string) bool {
+	return len(s) == 64 && isHex(s)
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// NormalizeContainerID 去掉 Kubernetes containerStatuses 中 "docker://"、"containerd://"、"cri-o://" 等运行时前缀
+// 并转为小写，结果不是十六进制字符串时返回错误。
+func NormalizeContainerID(id string) (string, error) {
+	if i := strings.Index(id, "://"); i >= 0 {
+		id = id[i+3:]
+	}
+	id = strings.ToLower(strings.TrimSpace(id))
+	if !isHex(id) || len(id) > 64 {
+		return "", fmt.Errorf("invalid container id %q: must be a hexadecimal id or prefix", id)
+	}
+	return id, nil
+}
+
+// FindContainer 扫描 /proc/*/cgroup，定位 ID 以 id 开头的容器（可以是 docker ps 显示的 12 位短 ID）。
+// 容器内最早启动的进程作为 init 进程；没有匹配的进程或前缀匹配到多个容器时返回错误。
+func (c *Collector) FindContainer(id string) (Container, error) {
+	prefix, err := NormalizeContainerID(id)
+	if err != nil {
+		return Container{}, err
+	}
+	pids, err := c.PIDs()
+	if err != nil {
+		return Container{}, err
+	}
+	found := make(map[string]*Container)
+	cgroupOf := make(map[int]string)
+	for _, pid := range pids {
+		cgroups, err := c.Cgroups(pid)
+		if err != nil {
+			continue
+		}
+		paths := cgroups.Paths()
+		if p, ok := cgroups.Unified(); ok {
+			paths = append([]string{p}, paths...)
+		}
+		for _, p := range paths {
+			full, runtime, ok := ParseContainerCgroup(p)
+			if !ok || !strings.HasPrefix(full, prefix) {
+				continue
+			}
+			ct := found[full]
+			if ct == nil {
+				ct = &Container{ID: full, Runtime: runtime}
+				found[full] = ct
+			}
+			ct.PIDs = append(ct.PIDs, pid)
+			cgroupOf[pid] = p
+			break
+		}
+	}
+	switch len(found) {
+	case 0:
+		return Container{}, fmt.Errorf("no process found in container %s", prefix)
+	case 1:
+	default:
+		ids := make([]string, 0, len(found))
+		for full := range found {
+			ids = append(ids, full[:12])
+		}
+		sort.Strings(ids)
+		return Container{}, fmt.Errorf("container id %s is ambiguous: matches %s", prefix, strings.Join(ids, ", "))
+	}
+	var ct *Container
+	for _, v := range found {
+		ct = v
+	}
+	ct.PID = c.earliestStarted(ct.PIDs)
+	ct.CgroupPath = cgroupOf[ct.PID]
+	return *ct, nil
+}
+
+// earliestStarted 返回 pids 中启动时间最早的进程，启动时间相同或不可读时取 PID 较小者。
+// docker exec、kubectl exec 进入容器的进程与 init 进程的父进程都在容器外，因此以启动时间而不是父子关系判断 init。
+func (c *Collector) earliestStarted(pids []int) int {
+	best, bestStart := 0, ^uint64(0)
+	for _, pid := range pids {
+		start := ^uint64(0)
+		if st, err := c.ProcStat(pid); err == nil {
+			start = st.StartTime
+		}
+		if best == 0 || start < bestStart {
+			best, bestStart = pid, start
+		}
+	}
+	return best
+}
+
+// NamespacedSysctl 读取 pid 所在 namespace 中的内核参数，如容器网络 namespace 中的 net.core.somaxconn。
+// 路径为 /proc/<pid>/root/proc/sys/<key>：HostFS 对 net、IPC、UTS namespace 隔离的参数先进入 pid 对应的
+// namespace 再读取（见 HostFS.ReadFile），快照包与测试夹具则直接以该路径保存进程视角的值。
+func (c *Collector) NamespacedSysctl(pid int, key string) (string, error) {
+	data, err := c.ReadFile(NamespacedSysctlPath(pid, key))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// NamespacedSysctlPath 返回 pid 视角下内核参数 key 的路径。
+func NamespacedSysctlPath(pid int, key string) string {
+	return path.Join(procPath(pid, "root"), SysctlPath(key))
+}
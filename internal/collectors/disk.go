@@ -0,0 +1,50 @@
+package collectors
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiskStats 为 /proc/diskstats 中单个块设备的 I/O 统计，时间单位为毫秒。
+// 字段含义参见内核文档 Documentation/admin-guide/iostats.rst。
+type DiskStats struct {
+	Major, Minor int
+	Name         string
+
+	Reads, ReadsMerged, SectorsRead, ReadTimeMs       uint64
+	Writes, WritesMerged, SectorsWritten, WriteTimeMs uint64
+	// InFlight 为当前未完成的请求数，是瞬时值而非累计计数器。
+	InFlight         uint64
+	IOTimeMs         uint64
+	WeightedIOTimeMs uint64
+}
+
+// Diskstats 读取并解析 /proc/diskstats，按设备名称排序。
+func (c *Collector) Diskstats() ([]DiskStats, error) {
+	data, err := c.ReadFile("/proc/diskstats")
+	if err != nil {
+		return nil, err
+	}
+	var disks []DiskStats
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 14 {
+			continue
+		}
+		major, _ := strconv.Atoi(fields[0])
+		minor, _ := strconv.Atoi(fields[1])
+		var v [11]uint64
+		for i := range v {
+			v[i], _ = strconv.ParseUint(fields[3+i], 10, 64)
+		}
+		disks = append(disks, DiskStats{
+			Major: major, Minor: minor, Name: fields[2],
+			Reads: v[0], ReadsMerged: v[1], SectorsRead: v[2], ReadTimeMs: v[3],
+			Writes: v[4], WritesMerged: v[5], SectorsWritten: v[6], WriteTimeMs: v[7],
+			InFlight: v[8], IOTimeMs: v[9], WeightedIOTimeMs: v[10],
+		})
+	}
+	sort.Slice(disks, func(i, j int) bool { return disks[i].Name < disks[j].Name })
+	return disks, nil
+}
@@ -0,0 +1,119 @@
+package collectors
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/supperghost/ossre/pkg/config"
+)
+
+// FS 抽象诊断所需的只读文件系统访问。
+// 调用方始终使用宿主视角的绝对路径（如 /proc/1/limits、/sys/fs/cgroup/pids.max、/etc/sysctl.conf），
+// 由实现负责将其映射到实际位置，从而支持备用根目录、快照包与测试夹具。
+type FS interface {
+	ReadFile(name string) ([]byte, error)
+	// ReadDirNames 返回目录下的条目名称，不保证顺序。
+	ReadDirNames(name string) ([]string, error)
+	Readlink(name string) (string, error)
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// HostFS 基于本地文件系统实现 FS，并将 /proc、/sys、/etc 前缀重定向到配置的根目录。
+type HostFS struct {
+	paths config.PathsConfig
+}
+
+// NewHostFS 创建一个按 paths 重定向的本地文件系统访问器。
+func NewHostFS(paths config.PathsConfig) *HostFS {
+	return &HostFS{paths: paths}
+}
+
+// Resolve 将宿主视角的绝对路径映射为本地文件系统中的实际路径。
+func (h *HostFS) Resolve(name string) string {
+	name = path.Clean(name)
+	for _, m := range []struct{ prefix, root string }{
+		{"/proc", h.paths.ProcRoot},
+		{"/sys", h.paths.SysRoot},
+		{"/etc", h.paths.EtcRoot},
+	} {
+		if m.root == "" || m.root == m.prefix {
+			continue
+		}
+		if name == m.prefix {
+			return m.root
+		}
+		if strings.HasPrefix(name, m.prefix+"/") {
+			return m.root + name[len(m.prefix):]
+		}
+	}
+	return name
+}
+
+// ReadFile 读取文件内容。/proc/sys 下的 net、IPC、UTS 参数按读取者所在的 namespace 返回值，
+// 因此 /proc/<pid>/root/proc/sys 下的这类参数先进入 pid 对应的 namespace 再读取，得到该进程视角的值。
+func (h *HostFS) ReadFile(name string) ([]byte, error) {
+	if pid, rest, nstype, ok := namespacedSysctlFile(name); ok {
+		return readInNamespace(h.Resolve(procPath(pid, "ns/"+nstype)), h.Resolve("/proc/sys/"+rest))
+	}
+	return os.ReadFile(h.Resolve(name))
+}
+
+// namespacedSysctlFile 解析 /proc/<pid>/root/proc/sys/<rest> 形式的路径，返回参数所属的 namespace 类型；
+// 不隔离的参数（如 fs.file-max）ok 为 false，直接经由 root 链接读取即可。
+func namespacedSysctlFile(name string) (pid int, rest, nstype string, ok bool) {
+	name = path.Clean(name)
+	if !strings.HasPrefix(name, "/proc/") {
+		return 0, "", "", false
+	}
+	pidStr, rest, found := strings.Cut(name[len("/proc/"):], "/root/proc/sys/")
+	if !found {
+		return 0, "", "", false
+	}
+	pid, err := strconv.Atoi(pidStr)
+	if err != nil || pid <= 0 {
+		return 0, "", "", false
+	}
+	switch {
+	case strings.HasPrefix(rest, "net/"):
+		nstype = "net"
+	case strings.HasPrefix(rest, "kernel/shm"), strings.HasPrefix(rest, "kernel/msg"),
+		rest == "kernel/sem", strings.HasPrefix(rest, "fs/mqueue/"):
+		nstype = "ipc"
+	case rest == "kernel/hostname", rest == "kernel/domainname":
+		nstype = "uts"
+	default:
+		return 0, "", "", false
+	}
+	return pid, rest, nstype, true
+}
+
+func (h *HostFS) ReadDirNames(name string) ([]string, error) {
+	d, err := os.Open(h.Resolve(name))
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+	return d.Readdirnames(-1)
+}
+
+func (h *HostFS) Readlink(name string) (string, error) {
+	return os.Readlink(h.Resolve(name))
+}
+
+func (h *HostFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(h.Resolve(name))
+}
+
+// SelfPID 返回 ossre 自身在 fsys 所对应 PID namespace 中的 PID。
+// 当 /proc 指向宿主机的 procfs 时，/proc/self 解析出的是宿主视角的 PID，与 os.Getpid() 可能不同。
+func SelfPID(fsys FS) int {
+	if target, err := fsys.Readlink("/proc/self"); err == nil {
+		if pid, err := strconv.Atoi(path.Base(target)); err == nil && pid > 0 {
+			return pid
+		}
+	}
+	return os.Getpid()
+}
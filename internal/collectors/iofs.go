@@ -0,0 +1,81 @@
+package collectors
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// FromIOFS 将 io/fs.FS（如 testing/fstest.MapFS 或解开的目录）适配为 FS，使插件可以读取内存中的伪造文件树。
+// 插件使用的宿主视角绝对路径会去掉开头的 "/" 后在 fsys 中查找；
+// 模式带 fs.ModeSymlink 的文件视为符号链接，其内容即链接目标。
+func FromIOFS(fsys fs.FS) FS {
+	return &ioFS{fsys: fsys}
+}
+
+type ioFS struct {
+	fsys fs.FS
+}
+
+func (f *ioFS) name(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// hostErr 将错误中的相对路径还原为插件传入的宿主视角路径，使错误信息与 HostFS 一致。
+func hostErr(name string, err error) error {
+	if pe, ok := err.(*fs.PathError); ok {
+		return &fs.PathError{Op: pe.Op, Path: name, Err: pe.Err}
+	}
+	return err
+}
+
+func (f *ioFS) ReadFile(name string) ([]byte, error) {
+	data, err := fs.ReadFile(f.fsys, f.name(name))
+	return data, hostErr(name, err)
+}
+
+func (f *ioFS) ReadDirNames(name string) ([]string, error) {
+	entries, err := fs.ReadDir(f.fsys, f.name(name))
+	if err != nil {
+		return nil, hostErr(name, err)
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	return names, nil
+}
+
+// readLinkFS 与 Go 1.25 起 io/fs 中的 ReadLinkFS 方法一致，fstest.MapFS 等实现会解析符号链接。
+type readLinkFS interface {
+	ReadLink(name string) (string, error)
+}
+
+func (f *ioFS) Readlink(name string) (string, error) {
+	if rl, ok := f.fsys.(readLinkFS); ok {
+		target, err := rl.ReadLink(f.name(name))
+		return target, hostErr(name, err)
+	}
+	// 旧版 fstest.MapFS 不解析符号链接，Open 直接返回链接本身
+	st, err := fs.Stat(f.fsys, f.name(name))
+	if err != nil {
+		return "", hostErr(name, err)
+	}
+	if st.Mode()&fs.ModeSymlink == 0 {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	data, err := fs.ReadFile(f.fsys, f.name(name))
+	if err != nil {
+		return "", hostErr(name, err)
+	}
+	return string(data), nil
+}
+
+func (f *ioFS) Stat(name string) (fs.FileInfo, error) {
+	st, err := fs.Stat(f.fsys, f.name(name))
+	return st, hostErr(name, err)
+}
@@ -0,0 +1,182 @@
+package collectors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// /proc/net 是指向 /proc/self/net 的链接，反映的是 ossre 自身所在网络命名空间的统计。
+
+// NetDevStats 为 /proc/net/dev 中单个网卡的收发统计。
+type NetDevStats struct {
+	Name                                    string
+	RxBytes, RxPackets, RxErrors, RxDropped uint64
+	TxBytes, TxPackets, TxErrors, TxDropped uint64
+}
+
+// NetDev 读取并解析 /proc/net/dev，按网卡名称排序。
+func (c *Collector) NetDev() ([]NetDevStats, error) {
+	data, err := c.ReadFile("/proc/net/dev")
+	if err != nil {
+		return nil, err
+	}
+	var devs []NetDevStats
+	for _, line := range strings.Split(string(data), "\n") {
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) < 16 {
+			continue
+		}
+		var v [16]uint64
+		for i := range v {
+			v[i], _ = strconv.ParseUint(fields[i], 10, 64)
+		}
+		devs = append(devs, NetDevStats{
+			Name:    strings.TrimSpace(name),
+			RxBytes: v[0], RxPackets: v[1], RxErrors: v[2], RxDropped: v[3],
+			TxBytes: v[8], TxPackets: v[9], TxErrors: v[10], TxDropped: v[11],
+		})
+	}
+	sort.Slice(devs, func(i, j int) bool { return devs[i].Name < devs[j].Name })
+	return devs, nil
+}
+
+// NetCounters 为 /proc/net/snmp 与 /proc/net/netstat 的解析结果，
+// 以 "协议 -> 计数器名 -> 值" 组织，如 counters["Tcp"]["RetransSegs"]、counters["TcpExt"]["ListenOverflows"]。
+type NetCounters map[string]map[string]int64
+
+// Get 返回指定协议下的计数器，不存在时返回 0 与 false。
+func (n NetCounters) Get(proto, name string) (int64, bool) {
+	v, ok := n[proto][name]
+	return v, ok
+}
+
+// NetSNMP 读取并合并 /proc/net/snmp 与 /proc/net/netstat；两者都不可读时返回错误。
+func (c *Collector) NetSNMP() (NetCounters, error) {
+	counters := make(NetCounters)
+	var firstErr error
+	read := 0
+	for _, name := range []string{"/proc/net/snmp", "/proc/net/netstat"} {
+		data, err := c.ReadFile(name)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		read++
+		if err := parseNetCounters(data, counters); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+	}
+	if read == 0 {
+		return nil, firstErr
+	}
+	return counters, nil
+}
+
+// parseNetCounters 解析“表头行 + 数值行”成对出现的格式，如：
+//
+//	Tcp: RtoAlgorithm RtoMin ...
+//	Tcp: 1 200 ...
+func parseNetCounters(data []byte, counters NetCounters) error {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	for i := 0; i+1 < len(lines); i += 2 {
+		hProto, header, ok1 := strings.Cut(lines[i], ":")
+		vProto, values, ok2 := strings.Cut(lines[i+1], ":")
+		if !ok1 || !ok2 || hProto != vProto {
+			return fmt.Errorf("mismatched header/value lines at line %d", i+1)
+		}
+		names := strings.Fields(header)
+		vals := strings.Fields(values)
+		if len(names) != len(vals) {
+			return fmt.Errorf("%s: %d names but %d values", hProto, len(names), len(vals))
+		}
+		m := counters[hProto]
+		if m == nil {
+			m = make(map[string]int64, len(names))
+			counters[hProto] = m
+		}
+		for j, n := range names {
+			v, err := strconv.ParseInt(vals[j], 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", hProto, n, err)
+			}
+			m[n] = v
+		}
+	}
+	return nil
+}
+
+// Sockstat 为 /proc/net/sockstat 的解析结果，如 sockstat["TCP"]["tw"]、sockstat["sockets"]["used"]。
+type Sockstat map[string]map[string]int64
+
+// Sockstat 读取并解析 /proc/net/sockstat。
+func (c *Collector) Sockstat() (Sockstat, error) {
+	data, err := c.ReadFile("/proc/net/sockstat")
+	if err != nil {
+		return nil, err
+	}
+	s := make(Sockstat)
+	for _, line := range strings.Split(string(data), "\n") {
+		proto, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		m := make(map[string]int64, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			if v, err := strconv.ParseInt(fields[i+1], 10, 64); err == nil {
+				m[fields[i]] = v
+			}
+		}
+		s[proto] = m
+	}
+	return s, nil
+}
+
+// SoftnetStat 为 /proc/net/softnet_stat 中单个 CPU 的软中断收包统计。
+type SoftnetStat struct {
+	// CPU 为行号对应的 CPU 编号；较新内核在第 13 列给出实际编号，优先使用。
+	CPU       int
+	Processed uint64
+	// Dropped 为 netdev_max_backlog 队列已满导致的丢包数。
+	Dropped uint64
+	// TimeSqueeze 为 net.core.netdev_budget 或时间片耗尽时仍有待处理数据包的次数。
+	TimeSqueeze uint64
+}
+
+// Softnet 读取并解析 /proc/net/softnet_stat（各列为十六进制）。
+func (c *Collector) Softnet() ([]SoftnetStat, error) {
+	data, err := c.ReadFile("/proc/net/softnet_stat")
+	if err != nil {
+		return nil, err
+	}
+	var stats []SoftnetStat
+	for i, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		hex := func(s string) uint64 {
+			v, _ := strconv.ParseUint(s, 16, 64)
+			return v
+		}
+		s := SoftnetStat{
+			CPU:         i,
+			Processed:   hex(fields[0]),
+			Dropped:     hex(fields[1]),
+			TimeSqueeze: hex(fields[2]),
+		}
+		if len(fields) >= 13 {
+			s.CPU = int(hex(fields[12]))
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
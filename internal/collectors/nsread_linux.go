@@ -0,0 +1,48 @@
+//go:build linux
+// +build linux
+
+package collectors
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"runtime"
+	"syscall"
+)
+
+// nsFlags 为 setns 的 namespace 类型参数。
+var nsFlags = map[string]uintptr{
+	"net": syscall.CLONE_NEWNET,
+	"ipc": syscall.CLONE_NEWIPC,
+	"uts": syscall.CLONE_NEWUTS,
+}
+
+// readInNamespace 在一个独占的 OS 线程中进入 nsFile（如 /proc/42/ns/net）对应的 namespace 后读取 name。
+// 该线程不再解除锁定，goroutine 退出时由运行时销毁，避免切换过 namespace 的线程被其他 goroutine 复用。
+// 进入其他进程的 namespace 需要 CAP_SYS_ADMIN，权限不足时返回 fs.ErrPermission。
+func readInNamespace(nsFile, name string) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		runtime.LockOSThread()
+		f, err := os.Open(nsFile)
+		if err != nil {
+			ch <- result{err: err}
+			return
+		}
+		_, _, errno := syscall.RawSyscall(sysSetns, f.Fd(), nsFlags[path.Base(nsFile)], 0)
+		f.Close()
+		if errno != 0 {
+			ch <- result{err: &fs.PathError{Op: "setns", Path: nsFile, Err: errno}}
+			return
+		}
+		data, err := os.ReadFile(name)
+		ch <- result{data, err}
+	}()
+	r := <-ch
+	return r.data, r.err
+}
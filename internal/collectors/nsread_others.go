@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package collectors
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// readInNamespace 在非 Linux 平台上不支持进入其他进程的 namespace。
+func readInNamespace(nsFile, name string) ([]byte, error) {
+	_ = name
+	return nil, &fs.PathError{Op: "setns", Path: nsFile, Err: errors.ErrUnsupported}
+}
@@ -0,0 +1,132 @@
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 初始 user / PID namespace 的 inode 编号，为内核中的固定值（PROC_USER_INIT_INO、PROC_PID_INIT_INO）。
+const (
+	initUserNSInode = "4026531837"
+	initPIDNSInode  = "4026531836"
+)
+
+// NamespaceState 表示 ossre 是否运行在非初始的 namespace 中。
+type NamespaceState string
+
+const (
+	NamespaceInitial NamespaceState = "initial"
+	NamespaceNested  NamespaceState = "nested"
+	NamespaceUnknown NamespaceState = "unknown"
+)
+
+// capBits 为预检关心的能力及其在能力位图中的位置，见 linux/capability.h。
+var capBits = map[string]uint{
+	"CAP_DAC_OVERRIDE":    1,
+	"CAP_DAC_READ_SEARCH": 2,
+	"CAP_SYS_PTRACE":      19,
+	"CAP_SYS_ADMIN":       21,
+	"CAP_SYS_RESOURCE":    24,
+}
+
+// Preflight 为诊断前的权限预检结果，用于解释哪些检查可能因权限不足而无法评估。
+type Preflight struct {
+	// EUID 为 ossre 的有效 UID，无法读取 /proc/self/status 时为 -1。
+	EUID int
+	// CapEff 为有效能力集位图，CapKnown 为 false 时表示无法读取。
+	CapEff   uint64
+	CapKnown bool
+	// UserNS 与 PIDNS 表示 ossre 是否运行在非初始的 user / PID namespace 中（如 rootless 容器），
+	// 此时即使 EUID 为 0 也可能无法读取宿主机其他进程的信息。
+	UserNS NamespaceState
+	PIDNS  NamespaceState
+	// Denied 为探测路径中因权限不足而无法读取的路径，按字典序排列。
+	Denied []string
+}
+
+// Preflight 检测有效 UID、能力集与 namespace，并探测目标进程 pids 的 limits、status、fd 与 task 是否可读。
+func (c *Collector) Preflight(pids ...int) Preflight {
+	self := SelfPID(c)
+	p := Preflight{
+		EUID:   -1,
+		UserNS: c.namespaceState(self, "user", initUserNSInode),
+		PIDNS:  c.namespaceState(self, "pid", initPIDNSInode),
+	}
+	if st, err := c.Status(self); err == nil {
+		if fields := strings.Fields(st.Fields["Uid"]); len(fields) > 1 {
+			if euid, err := strconv.Atoi(fields[1]); err == nil {
+				p.EUID = euid
+			}
+		}
+		if v, err := strconv.ParseUint(st.Fields["CapEff"], 16, 64); err == nil {
+			p.CapEff, p.CapKnown = v, true
+		}
+	}
+
+	for _, pid := range pids {
+		for _, name := range []string{"limits", "status"} {
+			if _, err := c.ReadFile(procPath(pid, name)); IsPermission(err) {
+				p.Denied = append(p.Denied, procPath(pid, name))
+			}
+		}
+		for _, name := range []string{"fd", "task"} {
+			if _, err := c.ReadDirNames(procPath(pid, name)); IsPermission(err) {
+				p.Denied = append(p.Denied, procPath(pid, name))
+			}
+		}
+	}
+	sort.Strings(p.Denied)
+	return p
+}
+
+func (c *Collector) namespaceState(pid int, ns, initInode string) NamespaceState {
+	link, err := c.Readlink(procPath(pid, "ns/"+ns))
+	if err != nil {
+		return NamespaceUnknown
+	}
+	if link == fmt.Sprintf("%s:[%s]", ns, initInode) {
+		return NamespaceInitial
+	}
+	return NamespaceNested
+}
+
+// Root 表示有效 UID 是否为 0。
+func (p Preflight) Root() bool {
+	return p.EUID == 0
+}
+
+// HasCap 表示是否持有能力 name（如 CAP_SYS_PTRACE）。能力集未知时以 EUID 是否为 0 推断，未知的能力名称返回 false。
+func (p Preflight) HasCap(name string) bool {
+	bit, ok := capBits[name]
+	if !ok {
+		return false
+	}
+	if !p.CapKnown {
+		return p.Root()
+	}
+	return p.CapEff&(1<<bit) != 0
+}
+
+// Missing 返回 privileges 中未持有的能力，能力名称以外的项（如 root）按 EUID 判断。
+func (p Preflight) Missing(privileges []string) []string {
+	var missing []string
+	for _, priv := range privileges {
+		held := p.HasCap(priv)
+		if priv == "root" {
+			held = p.Root()
+		}
+		if !held {
+			missing = append(missing, priv)
+		}
+	}
+	return missing
+}
+
+// IsPermission 表示 err 是否为权限不足（EACCES/EPERM）导致的读取失败。
+func IsPermission(err error) bool {
+	return err != nil && errors.Is(err, fs.ErrPermission)
+}
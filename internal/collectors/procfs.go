@@ -0,0 +1,393 @@
+package collectors
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unlimited 表示 limits、pids.max、memory.max 等限制项取值为 unlimited/max。
+const Unlimited = int64(-1)
+
+// /proc/<pid>/limits 中常用的限制项名称。
+const (
+	LimitNproc        = "Max processes"
+	LimitNofile       = "Max open files"
+	LimitStack        = "Max stack size"
+	LimitAddressSpace = "Max address space"
+)
+
+// limitNames 为内核 /proc/<pid>/limits 输出的全部限制项名称，用于在列未对齐时按名称切分行。
+var limitNames = []string{
+	"Max cpu time", "Max file size", "Max data size", "Max stack size",
+	"Max core file size", "Max resident set", "Max processes", "Max open files",
+	"Max locked memory", "Max address space", "Max file locks", "Max pending signals",
+	"Max msgqueue size", "Max nice priority", "Max realtime priority", "Max realtime timeout",
+}
+
+// Limit 表示单个资源限制的软/硬限制，取值为 Unlimited 时表示无限制。
+type Limit struct {
+	Soft int64
+	Hard int64
+}
+
+// Limits 为 /proc/<pid>/limits 解析结果，键为限制项名称（如 LimitNproc）。
+type Limits map[string]Limit
+
+// Soft 返回指定限制项的软限制；限制项缺失或为 unlimited 时返回 Unlimited。
+func (l Limits) Soft(name string) int64 {
+	v, ok := l[name]
+	if !ok {
+		return Unlimited
+	}
+	return v.Soft
+}
+
+// ParseLimits 解析 /proc/<pid>/limits 的内容。
+func ParseLimits(data []byte) (Limits, error) {
+	limits := make(Limits)
+	for _, line := range strings.Split(string(data), "\n") {
+		var name string
+		for _, n := range limitNames {
+			if strings.HasPrefix(line, n+" ") {
+				name = n
+				break
+			}
+		}
+		if name == "" {
+			continue
+		}
+		fields := strings.Fields(line[len(name):])
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("unexpected limits line: %q", line)
+		}
+		soft, err := parseLimitValue(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("parse %s soft limit: %w", name, err)
+		}
+		hard, err := parseLimitValue(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("parse %s hard limit: %w", name, err)
+		}
+		limits[name] = Limit{Soft: soft, Hard: hard}
+	}
+	return limits, nil
+}
+
+func parseLimitValue(s string) (int64, error) {
+	if s == "unlimited" {
+		return Unlimited, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// Limits 读取并解析 /proc/<pid>/limits。
+func (c *Collector) Limits(pid int) (Limits, error) {
+	data, err := c.ReadFile(procPath(pid, "limits"))
+	if err != nil {
+		return nil, err
+	}
+	return ParseLimits(data)
+}
+
+// ProcStatus 为 /proc/<pid>/status 中常用字段的解析结果，内存字段单位为 kB。
+type ProcStatus struct {
+	Name    string
+	State   string
+	PPID    int
+	UID     int
+	Threads int64
+	VmSize  int64
+	VmRSS   int64
+	// Fields 保存全部原始键值，便于读取未单独建模的字段。
+	Fields map[string]string
+}
+
+// Status 读取并解析 /proc/<pid>/status。
+func (c *Collector) Status(pid int) (ProcStatus, error) {
+	data, err := c.ReadFile(procPath(pid, "status"))
+	if err != nil {
+		return ProcStatus{}, err
+	}
+	st := ProcStatus{Fields: make(map[string]string), UID: -1}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		st.Fields[key] = value
+		fields := strings.Fields(value)
+		switch key {
+		case "Name":
+			st.Name = value
+		case "State":
+			if len(fields) > 0 {
+				st.State = fields[0]
+			}
+		case "PPid":
+			st.PPID, _ = strconv.Atoi(value)
+		case "Uid":
+			if len(fields) > 0 {
+				if uid, err := strconv.Atoi(fields[0]); err == nil {
+					st.UID = uid
+				}
+			}
+		case "Threads":
+			st.Threads, _ = strconv.ParseInt(value, 10, 64)
+		case "VmSize":
+			st.VmSize = parseKB(fields)
+		case "VmRSS":
+			st.VmRSS = parseKB(fields)
+		}
+	}
+	return st, nil
+}
+
+func parseKB(fields []string) int64 {
+	if len(fields) == 0 {
+		return 0
+	}
+	v, _ := strconv.ParseInt(fields[0], 10, 64)
+	return v
+}
+
+// ProcStat 为 /proc/<pid>/stat 中常用字段的解析结果，CPU 时间单位为 clock tick。
+type ProcStat struct {
+	PID        int
+	Comm       string
+	State      string
+	PPID       int
+	UTime      uint64
+	STime      uint64
+	NumThreads int64
+	StartTime  uint64
+}
+
+// ProcStat 读取并解析 /proc/<pid>/stat。
+// comm 字段可能包含空格与括号，因此以最后一个 ')' 作为分隔。
+func (c *Collector) ProcStat(pid int) (ProcStat, error) {
+	data, err := c.ReadFile(procPath(pid, "stat"))
+	if err != nil {
+		return ProcStat{}, err
+	}
+	s := strings.TrimSpace(string(data))
+	open := strings.IndexByte(s, '(')
+	closing := strings.LastIndexByte(s, ')')
+	if open < 0 || closing < open {
+		return ProcStat{}, fmt.Errorf("unexpected stat format: %q", s)
+	}
+	st := ProcStat{Comm: s[open+1 : closing]}
+	st.PID, _ = strconv.Atoi(strings.TrimSpace(s[:open]))
+	// rest[0] 对应第 3 列 state
+	rest := strings.Fields(s[closing+1:])
+	if len(rest) < 20 {
+		return ProcStat{}, fmt.Errorf("unexpected stat format: %q", s)
+	}
+	st.State = rest[0]
+	st.PPID, _ = strconv.Atoi(rest[1])
+	st.UTime, _ = strconv.ParseUint(rest[11], 10, 64)
+	st.STime, _ = strconv.ParseUint(rest[12], 10, 64)
+	st.NumThreads, _ = strconv.ParseInt(rest[17], 10, 64)
+	st.StartTime, _ = strconv.ParseUint(rest[19], 10, 64)
+	return st, nil
+}
+
+// CgroupEntry 为 /proc/<pid>/cgroup 中的一行；cgroup v2 统一层级的 Controllers 为空。
+type CgroupEntry struct {
+	HierarchyID int
+	Controllers []string
+	Path        string
+}
+
+// ProcCgroups 为 /proc/<pid>/cgroup 的解析结果。
+type ProcCgroups []CgroupEntry
+
+// Unified 返回 cgroup v2 统一层级中的路径。
+func (p ProcCgroups) Unified() (string, bool) {
+	for _, e := range p {
+		if e.HierarchyID == 0 && len(e.Controllers) == 0 {
+			return e.Path, true
+		}
+	}
+	return "", false
+}
+
+// Controller 返回 cgroup v1 中指定控制器所在层级的路径。
+func (p ProcCgroups) Controller(name string) (string, bool) {
+	for _, e := range p {
+		for _, c := range e.Controllers {
+			if c == name {
+				return e.Path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Paths 返回所有层级的路径，用于按前缀筛选进程。
+func (p ProcCgroups) Paths() []string {
+	paths := make([]string, 0, len(p))
+	for _, e := range p {
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
+// Cgroups 读取并解析 /proc/<pid>/cgroup。
+func (c *Collector) Cgroups(pid int) (ProcCgroups, error) {
+	data, err := c.ReadFile(procPath(pid, "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	var cgroups ProcCgroups
+	for _, line := range strings.Split(string(data), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		e := CgroupEntry{Path: parts[2]}
+		e.HierarchyID, _ = strconv.Atoi(parts[0])
+		if parts[1] != "" {
+			e.Controllers = strings.Split(parts[1], ",")
+		}
+		cgroups = append(cgroups, e)
+	}
+	return cgroups, nil
+}
+
+// FdInfo 表示 /proc/<pid>/fd 下的一个文件描述符；链接目标不可读时 Target 为空。
+type FdInfo struct {
+	FD     int
+	Target string
+}
+
+// Fds 列出 /proc/<pid>/fd 下的文件描述符及其链接目标，按 fd 编号排序。
+// 目录不可读时返回错误；单个 fd 在遍历期间被关闭或链接无权读取时保留该 fd、Target 为空。
+func (c *Collector) Fds(pid int) ([]FdInfo, error) {
+	dir := procPath(pid, "fd")
+	names, err := c.ReadDirNames(dir)
+	if err != nil {
+		return nil, err
+	}
+	fds := make([]FdInfo, 0, len(names))
+	for _, name := range names {
+		fd, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		target, _ := c.Readlink(path.Join(dir, name))
+		fds = append(fds, FdInfo{FD: fd, Target: target})
+	}
+	sort.Slice(fds, func(i, j int) bool { return fds[i].FD < fds[j].FD })
+	return fds, nil
+}
+
+// Cmdline 读取 /proc/<pid>/cmdline 并按 NUL 拆分为参数列表；内核线程的 cmdline 为空。
+func (c *Collector) Cmdline(pid int) ([]string, error) {
+	data, err := c.ReadFile(procPath(pid, "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(data), nil
+}
+
+// JavaOptionEnvs 为 JVM 启动时读取选项的环境变量。
+var JavaOptionEnvs = []string{"JAVA_TOOL_OPTIONS", "JDK_JAVA_OPTIONS", "_JAVA_OPTIONS"}
+
+// environKeys 为 Environ 返回、快照包记录的环境变量。进程的其他环境变量可能包含密码、令牌等敏感信息，
+// 一律不返回也不记录。
+var environKeys = append([]string{"GOMAXPROCS"}, JavaOptionEnvs...)
+
+// Environ 读取 /proc/<pid>/environ 中的环境变量，只返回 GOMAXPROCS 与 JavaOptionEnvs 中的变量。
+// 读取其他用户进程的环境变量需要 CAP_SYS_PTRACE。
+func (c *Collector) Environ(pid int) (map[string]string, error) {
+	data, err := c.ReadFile(procPath(pid, "environ"))
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for _, kv := range splitNUL(RedactEnviron(data)) {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	return env, nil
+}
+
+// IsEnvironPath 判断 name 是否为进程或线程的环境变量文件（/proc/<pid>/environ、/proc/<pid>/task/<tid>/environ）。
+func IsEnvironPath(name string) bool {
+	for _, pattern := range []string{"/proc/*/environ", "/proc/*/task/*/environ"} {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactEnviron 从 environ 文件内容中去掉 GOMAXPROCS 与 JavaOptionEnvs 以外的变量，保持以 NUL 分隔的格式。
+func RedactEnviron(data []byte) []byte {
+	var out []byte
+	for _, kv := range splitNUL(data) {
+		k, _, _ := strings.Cut(kv, "=")
+		for _, key := range environKeys {
+			if k == key {
+				out = append(append(out, kv...), 0)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func splitNUL(data []byte) []string {
+	var out []string
+	for _, s := range strings.Split(string(data), "\x00") {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// TaskCount 返回 /proc/<pid>/task 下的任务（线程）数，目录不可读时返回 0。
+func (c *Collector) TaskCount(pid int) int64 {
+	names, err := c.ReadDirNames(procPath(pid, "task"))
+	if err != nil {
+		return 0
+	}
+	return int64(len(names))
+}
+
+// PIDs 返回 /proc 下所有数字目录对应的 PID，升序排列。
+func (c *Collector) PIDs() ([]int, error) {
+	names, err := c.ReadDirNames("/proc")
+	if err != nil {
+		return nil, err
+	}
+	pids := make([]int, 0, len(names))
+	for _, name := range names {
+		if pid, err := strconv.Atoi(name); err == nil && pid > 0 {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	return pids, nil
+}
+
+// ProcExists 判断 /proc/<pid> 是否存在且为目录。
+func (c *Collector) ProcExists(pid int) bool {
+	st, err := c.Stat(procPath(pid, ""))
+	return err == nil && st.IsDir()
+}
+
+// ProcDir 返回宿主视角下的 /proc/<pid> 路径。
+func ProcDir(pid int) string {
+	return procPath(pid, "")
+}
+
+func procPath(pid int, name string) string {
+	return path.Join("/proc", strconv.Itoa(pid), name)
+}
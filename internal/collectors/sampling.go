@@ -0,0 +1,427 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Counters 为一次采样读取到的一组数值，键为 "<对象>/<指标>" 形式，如 "eth0/rx_bytes"、"Tcp/RetransSegs"、"sda/read_ms"。
+// 大多数为单调递增的累计计数器，通过 Samples.Rate 计算速率；少数为瞬时值（如 "sda/in_flight"），通过 Samples.Gauge 统计。
+type Counters map[string]uint64
+
+// CounterSource 从采集器读取一组计数器。Sample 每次采样都会传入缓存为空的采集器。
+type CounterSource func(c *Collector) (Counters, error)
+
+// Sample 立即采样一次，随后在 duration 内以 interval 为间隔轮询 sources，并在窗口结束时再采样一次。
+// 首次采样时所有来源均失败则返回错误；之后单次采样失败的来源在该时刻缺失，按设备热插拔处理。
+// ctx 取消时返回已采集的样本。
+func (c *Collector) Sample(ctx context.Context, interval, duration time.Duration, sources ...CounterSource) (*Samples, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no counter sources")
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid sampling interval %s", interval)
+	}
+
+	s := &Samples{}
+	sample := func() error {
+		fresh := c.Fresh()
+		values := make(Counters)
+		var firstErr error
+		for _, src := range sources {
+			cs, err := src(fresh)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			for k, v := range cs {
+				values[k] = v
+			}
+		}
+		if len(values) == 0 && firstErr != nil {
+			return firstErr
+		}
+		s.Add(time.Now(), values)
+		return nil
+	}
+
+	if err := sample(); err != nil {
+		return nil, err
+	}
+	if duration <= 0 {
+		return s, nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(duration)
+	defer deadline.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return s, nil
+		case <-deadline.C:
+			_ = sample()
+			return s, nil
+		case <-ticker.C:
+			_ = sample()
+		}
+	}
+}
+
+// Samples 为按时间顺序排列的计数器采样序列。
+type Samples struct {
+	points []samplePoint
+}
+
+type samplePoint struct {
+	at     time.Time
+	values Counters
+}
+
+// Add 追加一个采样点，at 应不早于上一个采样点。插件也可以用它汇总自行采集的数值。
+func (s *Samples) Add(at time.Time, values Counters) {
+	s.points = append(s.points, samplePoint{at: at, values: values})
+}
+
+// Len 返回采样点个数。
+func (s *Samples) Len() int {
+	return len(s.points)
+}
+
+// Elapsed 返回首尾采样点之间的时长。
+func (s *Samples) Elapsed() time.Duration {
+	if len(s.points) < 2 {
+		return 0
+	}
+	return s.points[len(s.points)-1].at.Sub(s.points[0].at)
+}
+
+// Keys 返回任一采样点中出现过的键，按名称排序。
+func (s *Samples) Keys() []string {
+	seen := make(map[string]bool)
+	for _, p := range s.points {
+		for k := range p.values {
+			seen[k] = true
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Delta 返回 key 在整个窗口内的累计增量，计数器回绕与重置的处理同 Rate；没有任何有效区间时 ok 为 false。
+func (s *Samples) Delta(key string) (delta uint64, ok bool) {
+	s.eachInterval(func(_ time.Duration, prev, cur Counters) {
+		if d, valid := intervalDelta(prev, cur, key); valid {
+			delta += d
+			ok = true
+		}
+	})
+	return delta, ok
+}
+
+// Rate 返回累计计数器 key 在各采样区间内的每秒速率统计。
+// 区间任一端缺少该键（设备热插拔）或计数器被重置时，该区间不计入。
+func (s *Samples) Rate(key string) Stats {
+	var values []float64
+	s.eachInterval(func(dt time.Duration, prev, cur Counters) {
+		if d, ok := intervalDelta(prev, cur, key); ok && dt > 0 {
+			values = append(values, float64(d)/dt.Seconds())
+		}
+	})
+	return newStats(values)
+}
+
+// Ratio 返回各采样区间内 num 增量之和与 den 增量之和的比值统计，den 增量为 0 的区间不计入。
+// 例如块设备平均等待时间 (await, ms) 为 Ratio([read_ms, write_ms], [reads, writes])。
+func (s *Samples) Ratio(num, den []string) Stats {
+	var values []float64
+	s.eachInterval(func(_ time.Duration, prev, cur Counters) {
+		n, okN := sumDeltas(prev, cur, num)
+		d, okD := sumDeltas(prev, cur, den)
+		if okN && okD && d > 0 {
+			values = append(values, float64(n)/float64(d))
+		}
+	})
+	return newStats(values)
+}
+
+// Gauge 返回瞬时值 key 在所有采样点上的统计。
+func (s *Samples) Gauge(key string) Stats {
+	var values []float64
+	for _, p := range s.points {
+		if v, ok := p.values[key]; ok {
+			values = append(values, float64(v))
+		}
+	}
+	return newStats(values)
+}
+
+// Slope 返回瞬时值 key 随时间的最小二乘线性拟合斜率（每秒变化量），用于按增长趋势预测耗尽时间；
+// 包含该键的采样点少于 2 个时 ok 为 false。
+func (s *Samples) Slope(key string) (perSecond float64, ok bool) {
+	var xs, ys []float64
+	for _, p := range s.points {
+		if v, found := p.values[key]; found {
+			xs = append(xs, p.at.Sub(s.points[0].at).Seconds())
+			ys = append(ys, float64(v))
+		}
+	}
+	if len(xs) < 2 {
+		return 0, false
+	}
+	// 以均值为中心计算，保证取值不变时斜率严格为 0
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+	var sxy, sxx float64
+	for i := range xs {
+		sxy += (xs[i] - meanX) * (ys[i] - meanY)
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	if sxx == 0 {
+		return 0, false
+	}
+	return sxy / sxx, true
+}
+
+// Endpoints 返回瞬时值 key 在首个与最后一个包含该键的采样点上的取值，没有采样点包含该键时 ok 为 false。
+func (s *Samples) Endpoints(key string) (first, last uint64, ok bool) {
+	for _, p := range s.points {
+		if v, found := p.values[key]; found {
+			if !ok {
+				first, ok = v, true
+			}
+			last = v
+		}
+	}
+	return first, last, ok
+}
+
+func (s *Samples) eachInterval(fn func(dt time.Duration, prev, cur Counters)) {
+	for i := 1; i < len(s.points); i++ {
+		fn(s.points[i].at.Sub(s.points[i-1].at), s.points[i-1].values, s.points[i].values)
+	}
+}
+
+// intervalDelta 计算 key 在相邻两个采样点之间的增量，任一端缺少该键或计数器被重置时 ok 为 false。
+func intervalDelta(prev, cur Counters, key string) (uint64, bool) {
+	p, ok1 := prev[key]
+	c, ok2 := cur[key]
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	return CounterDelta(p, c)
+}
+
+func sumDeltas(prev, cur Counters, keys []string) (uint64, bool) {
+	var sum uint64
+	for _, k := range keys {
+		d, ok := intervalDelta(prev, cur, k)
+		if !ok {
+			return 0, false
+		}
+		sum += d
+	}
+	return sum, true
+}
+
+// maxWrapDelta 为按 32 位回绕处理时允许的最大增量。回绕前的值接近 32 位上限、回绕后的值很小时增量才会落在该范围内；
+// 64 位计数器被重置为较小的值（网卡或磁盘重新添加、cgroup 重建）时按回绕计算的增量接近 2^32，超出该范围。
+const maxWrapDelta = 1 << 30
+
+// CounterDelta 计算累计计数器从 prev 到 cur 的增量。
+// cur 小于 prev 时，若按 32 位回绕计算的增量不超过 maxWrapDelta（prev 接近 32 位上限），视为 32 位计数器回绕
+// （部分网卡驱动与 32 位内核的统计为 32 位）；否则视为计数器被重置，ok 为 false。
+func CounterDelta(prev, cur uint64) (delta uint64, ok bool) {
+	if cur >= prev {
+		return cur - prev, true
+	}
+	if prev <= math.MaxUint32 {
+		if d := math.MaxUint32 - prev + cur + 1; d <= maxWrapDelta {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// Stats 为一组数值的统计摘要；Count 为 0 时其余字段均为 0。
+type Stats struct {
+	Count          int
+	Min, Max, Mean float64
+	P50, P95, P99  float64
+}
+
+func newStats(values []float64) Stats {
+	if len(values) == 0 {
+		return Stats{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	return Stats{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		Mean:  sum / float64(len(sorted)),
+		P50:   percentile(sorted, 50),
+		P95:   percentile(sorted, 95),
+		P99:   percentile(sorted, 99),
+	}
+}
+
+// percentile 对已排序的 sorted 按相邻秩线性插值计算第 p 百分位数。
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+// 以下为常用的计数器来源，键名与 /proc 中的字段一一对应，便于插件组合使用。
+
+// NetDevCounters 将 /proc/net/dev 转换为 "<网卡>/rx_bytes" 等计数器。
+func NetDevCounters(c *Collector) (Counters, error) {
+	devs, err := c.NetDev()
+	if err != nil {
+		return nil, err
+	}
+	out := make(Counters, len(devs)*8)
+	for _, d := range devs {
+		for name, v := range map[string]uint64{
+			"rx_bytes": d.RxBytes, "rx_packets": d.RxPackets, "rx_errors": d.RxErrors, "rx_dropped": d.RxDropped,
+			"tx_bytes": d.TxBytes, "tx_packets": d.TxPackets, "tx_errors": d.TxErrors, "tx_dropped": d.TxDropped,
+		} {
+			out[d.Name+"/"+name] = v
+		}
+	}
+	return out, nil
+}
+
+// NetSNMPCounters 将 /proc/net/snmp 与 /proc/net/netstat 转换为 "Tcp/RetransSegs"、"TcpExt/ListenDrops" 等计数器。
+// 负值（如 Tcp/MaxConn 的 -1）不是计数器，予以忽略。
+func NetSNMPCounters(c *Collector) (Counters, error) {
+	counters, err := c.NetSNMP()
+	if err != nil {
+		return nil, err
+	}
+	out := make(Counters)
+	for proto, m := range counters {
+		for name, v := range m {
+			if v >= 0 {
+				out[proto+"/"+name] = uint64(v)
+			}
+		}
+	}
+	return out, nil
+}
+
+// DiskstatsCounters 将 /proc/diskstats 转换为 "<设备>/reads"、"<设备>/read_ms" 等计数器；
+// "<设备>/in_flight" 为瞬时值。
+func DiskstatsCounters(c *Collector) (Counters, error) {
+	disks, err := c.Diskstats()
+	if err != nil {
+		return nil, err
+	}
+	out := make(Counters, len(disks)*11)
+	for _, d := range disks {
+		for name, v := range map[string]uint64{
+			"reads": d.Reads, "read_merges": d.ReadsMerged, "read_sectors": d.SectorsRead, "read_ms": d.ReadTimeMs,
+			"writes": d.Writes, "write_merges": d.WritesMerged, "write_sectors": d.SectorsWritten, "write_ms": d.WriteTimeMs,
+			"in_flight": d.InFlight, "io_ms": d.IOTimeMs, "weighted_io_ms": d.WeightedIOTimeMs,
+		} {
+			out[d.Name+"/"+name] = v
+		}
+	}
+	return out, nil
+}
+
+// SystemStatCounters 将 /proc/stat 转换为 "ctxt"、"processes"、"cpu/user" 等计数器（CPU 时间单位为 clock tick）；
+// "procs_running"、"procs_blocked" 为瞬时值。
+func SystemStatCounters(c *Collector) (Counters, error) {
+	st, err := c.SystemStat()
+	if err != nil {
+		return nil, err
+	}
+	out := Counters{
+		"ctxt":          st.ContextSw,
+		"processes":     st.Processes,
+		"procs_running": uint64(max(st.ProcsRunning, 0)),
+		"procs_blocked": uint64(max(st.ProcsBlocked, 0)),
+	}
+	addCPU := func(prefix string, t CPUTimes) {
+		for name, v := range map[string]uint64{
+			"user": t.User, "nice": t.Nice, "system": t.System, "idle": t.Idle,
+			"iowait": t.IOWait, "irq": t.IRQ, "softirq": t.SoftIRQ, "steal": t.Steal,
+			"total": t.Total(),
+		} {
+			out[prefix+"/"+name] = v
+		}
+	}
+	addCPU("cpu", st.CPU)
+	for i, t := range st.PerCPU {
+		addCPU("cpu"+strconv.Itoa(i), t)
+	}
+	return out, nil
+}
+
+// SoftnetCounters 将 /proc/net/softnet_stat 转换为 "cpu<N>/processed"、"cpu<N>/dropped"、"cpu<N>/time_squeeze" 计数器。
+// 各列为 32 位计数器，不预先求和，以免掩盖单个 CPU 的回绕。
+func SoftnetCounters(c *Collector) (Counters, error) {
+	stats, err := c.Softnet()
+	if err != nil {
+		return nil, err
+	}
+	out := make(Counters, len(stats)*3)
+	for _, s := range stats {
+		prefix := "cpu" + strconv.Itoa(s.CPU)
+		out[prefix+"/processed"] = s.Processed
+		out[prefix+"/dropped"] = s.Dropped
+		out[prefix+"/time_squeeze"] = s.TimeSqueeze
+	}
+	return out, nil
+}
+
+// CgroupCPUStatCounters 将进程所在 cgroup 的 cpu.stat 转换为 "cpu/nr_periods"、"cpu/nr_throttled"、
+// "cpu/throttled_usec"、"cpu/usage_usec" 计数器，CPU 使用时间不可读时不包含 "cpu/usage_usec"。
+func CgroupCPUStatCounters(pid int) CounterSource {
+	return func(c *Collector) (Counters, error) {
+		s := c.CgroupCPUStat(pid)
+		if s.Version == "none" {
+			return nil, fs.ErrNotExist
+		}
+		if s.Err != nil {
+			return nil, s.Err
+		}
+		counters := Counters{
+			"cpu/nr_periods":     s.NrPeriods,
+			"cpu/nr_throttled":   s.NrThrottled,
+			"cpu/throttled_usec": s.ThrottledUsec,
+		}
+		if s.UsageUsec > 0 {
+			counters["cpu/usage_usec"] = s.UsageUsec
+		}
+		return counters, nil
+	}
+}
@@ -0,0 +1,9 @@
+//go:build linux && !amd64 && !386
+// +build linux,!amd64,!386
+
+package collectors
+
+import "syscall"
+
+// sysSetns 为 setns 的系统调用号。
+const sysSetns = syscall.SYS_SETNS
@@ -0,0 +1,4 @@
+package collectors
+
+// sysSetns 为 setns 的系统调用号，标准库 syscall 在 386 上未定义 SYS_SETNS。
+const sysSetns = 346
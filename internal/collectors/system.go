@@ -0,0 +1,250 @@
+package collectors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Meminfo 为 /proc/meminfo 的解析结果，键为字段名（如 MemTotal），值单位为 kB。
+type Meminfo map[string]int64
+
+// Meminfo 读取并解析 /proc/meminfo。
+func (c *Collector) Meminfo() (Meminfo, error) {
+	data, err := c.ReadFile("/proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	m := make(Meminfo)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		if v, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+			m[key] = v
+		}
+	}
+	return m, nil
+}
+
+// CPUTimes 为 /proc/stat 中一行 cpu 统计，单位为 clock tick。
+type CPUTimes struct {
+	User, Nice, System, Idle, IOWait, IRQ, SoftIRQ, Steal uint64
+}
+
+// Total 返回各项 CPU 时间之和（guest 已计入 user，不重复累加）。
+func (t CPUTimes) Total() uint64 {
+	return t.User + t.Nice + t.System + t.Idle + t.IOWait + t.IRQ + t.SoftIRQ + t.Steal
+}
+
+// SystemStat 为 /proc/stat 的解析结果。
+type SystemStat struct {
+	// CPU 为所有 CPU 的汇总，PerCPU 按 cpuN 的编号顺序排列。
+	CPU          CPUTimes
+	PerCPU       []CPUTimes
+	ContextSw    uint64
+	BootTime     int64
+	Processes    uint64
+	ProcsRunning int64
+	ProcsBlocked int64
+}
+
+// SystemStat 读取并解析 /proc/stat。
+func (c *Collector) SystemStat() (SystemStat, error) {
+	data, err := c.ReadFile("/proc/stat")
+	if err != nil {
+		return SystemStat{}, err
+	}
+	var st SystemStat
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch {
+		case fields[0] == "cpu":
+			st.CPU = parseCPUTimes(fields[1:])
+		case strings.HasPrefix(fields[0], "cpu"):
+			st.PerCPU = append(st.PerCPU, parseCPUTimes(fields[1:]))
+		case fields[0] == "ctxt":
+			st.ContextSw, _ = strconv.ParseUint(fields[1], 10, 64)
+		case fields[0] == "btime":
+			st.BootTime, _ = strconv.ParseInt(fields[1], 10, 64)
+		case fields[0] == "processes":
+			st.Processes, _ = strconv.ParseUint(fields[1], 10, 64)
+		case fields[0] == "procs_running":
+			st.ProcsRunning, _ = strconv.ParseInt(fields[1], 10, 64)
+		case fields[0] == "procs_blocked":
+			st.ProcsBlocked, _ = strconv.ParseInt(fields[1], 10, 64)
+		}
+	}
+	return st, nil
+}
+
+func parseCPUTimes(fields []string) CPUTimes {
+	var v [8]uint64
+	for i := 0; i < len(v) && i < len(fields); i++ {
+		v[i], _ = strconv.ParseUint(fields[i], 10, 64)
+	}
+	return CPUTimes{
+		User: v[0], Nice: v[1], System: v[2], Idle: v[3],
+		IOWait: v[4], IRQ: v[5], SoftIRQ: v[6], Steal: v[7],
+	}
+}
+
+// Loadavg 为 /proc/loadavg 的解析结果。
+type Loadavg struct {
+	Load1, Load5, Load15 float64
+	Running              int64
+	// Total 为内核全局任务（线程）总数，即 nr_threads，不受 PID namespace 影响。
+	Total int64
+}
+
+// Loadavg 读取并解析 /proc/loadavg，文件格式示例：0.20 0.18 0.12 1/80 11206
+func (c *Collector) Loadavg() (Loadavg, error) {
+	data, err := c.ReadFile("/proc/loadavg")
+	if err != nil {
+		return Loadavg{}, err
+	}
+	return ParseLoadavg(string(data))
+}
+
+// ParseLoadavg 解析 /proc/loadavg 的内容。
+func ParseLoadavg(s string) (Loadavg, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 4 {
+		return Loadavg{}, fmt.Errorf("unexpected /proc/loadavg format: %q", s)
+	}
+	var (
+		l   Loadavg
+		err error
+	)
+	for i, dst := range []*float64{&l.Load1, &l.Load5, &l.Load15} {
+		if *dst, err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return Loadavg{}, fmt.Errorf("parse /proc/loadavg: %w", err)
+		}
+	}
+	running, total, ok := strings.Cut(fields[3], "/")
+	if !ok {
+		return Loadavg{}, fmt.Errorf("unexpected /proc/loadavg task field: %q", fields[3])
+	}
+	if l.Running, err = strconv.ParseInt(running, 10, 64); err != nil {
+		return Loadavg{}, fmt.Errorf("parse /proc/loadavg running tasks: %w", err)
+	}
+	if l.Total, err = strconv.ParseInt(total, 10, 64); err != nil {
+		return Loadavg{}, fmt.Errorf("parse /proc/loadavg task total: %w", err)
+	}
+	return l, nil
+}
+
+// Sysctl 通过 /proc/sys 读取 sysctl 参数的当前值，如 net.ipv4.tcp_syncookies -> /proc/sys/net/ipv4/tcp_syncookies。
+func (c *Collector) Sysctl(key string) (string, error) {
+	data, err := c.ReadFile(SysctlPath(key))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SysctlInt 读取整数类型的 sysctl 参数。
+func (c *Collector) SysctlInt(key string) (int64, error) {
+	s, err := c.Sysctl(key)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// SysctlPath 返回 sysctl 参数在 /proc/sys 下对应的文件路径。
+func SysctlPath(key string) string {
+	return "/proc/sys/" + strings.ReplaceAll(key, ".", "/")
+}
+
+// SysctlConfigFile 返回持久化配置了 key 的 sysctl 配置文件，未配置时返回空字符串。
+// 按 sysctl --system 的加载顺序依次查找 /etc/sysctl.d/*.conf（按文件名排序）与 /etc/sysctl.conf，
+// 同一参数被多个文件设置时以最后加载的文件为准。
+func (c *Collector) SysctlConfigFile(key string) string {
+	var files []string
+	if names, err := c.ReadDirNames("/etc/sysctl.d"); err == nil {
+		sorted := append([]string(nil), names...)
+		sort.Strings(sorted)
+		for _, name := range sorted {
+			if strings.HasSuffix(name, ".conf") {
+				files = append(files, "/etc/sysctl.d/"+name)
+			}
+		}
+	}
+	files = append(files, "/etc/sysctl.conf")
+
+	var found string
+	for _, name := range files {
+		data, err := c.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || line[0] == '#' || line[0] == ';' {
+				continue
+			}
+			k, _, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			// "-key = value" 表示忽略写入失败，"net/ipv4/x" 与 "net.ipv4.x" 等价
+			k = strings.ReplaceAll(strings.TrimPrefix(strings.TrimSpace(k), "-"), "/", ".")
+			if k == key {
+				found = name
+			}
+		}
+	}
+	return found
+}
+
+// FileNr 为 /proc/sys/fs/file-nr 的解析结果。
+type FileNr struct {
+	Allocated int64
+	Free      int64
+	Max       int64
+}
+
+// FileNr 读取并解析 /proc/sys/fs/file-nr。
+func (c *Collector) FileNr() (FileNr, error) {
+	s, err := c.Sysctl("fs.file-nr")
+	if err != nil {
+		return FileNr{}, err
+	}
+	fields := strings.Fields(s)
+	if len(fields) < 3 {
+		return FileNr{}, fmt.Errorf("unexpected file-nr format: %q", s)
+	}
+	var nr FileNr
+	for i, dst := range []*int64{&nr.Allocated, &nr.Free, &nr.Max} {
+		if *dst, err = strconv.ParseInt(fields[i], 10, 64); err != nil {
+			return FileNr{}, fmt.Errorf("parse file-nr: %w", err)
+		}
+	}
+	return nr, nil
+}
+
+// LookupUser 在 /etc/passwd 中查找用户名对应的 UID。
+func (c *Collector) LookupUser(name string) (int, error) {
+	data, err := c.ReadFile("/etc/passwd")
+	if err != nil {
+		return 0, fmt.Errorf("lookup user %q: %w", name, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 || fields[0] != name {
+			continue
+		}
+		return strconv.Atoi(fields[2])
+	}
+	return 0, fmt.Errorf("unknown user %q", name)
+}
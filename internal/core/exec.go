@@ -0,0 +1,305 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// ExecProtocolVersion 为外部插件协议版本，协议不兼容时递增。
+//
+// 外部插件是插件目录中的可执行文件，以第一个参数区分请求：
+//
+//	<exe> describe  在标准输出打印 ExecDescription JSON
+//	<exe> run       从标准输入读取 ExecRequest JSON，在标准输出打印 models.Result JSON
+//
+// 退出码非 0 表示运行失败，标准错误的内容会记录到日志。
+const ExecProtocolVersion = 1
+
+const (
+	// describe 请求的超时时间。
+	execDescribeTimeout = 5 * time.Second
+	// 标准输出与标准错误的大小上限，超出时视为插件异常。
+	execMaxStdout = 16 << 20
+	execMaxStderr = 64 << 10
+)
+
+// ExternalTag 为所有外部插件自动带有的标签。
+const ExternalTag = "external"
+
+// execNamePattern 为外部插件名称的格式，与内置插件一致。
+var execNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// ExecDescription 为外部插件对 describe 请求的应答。
+// Version 与 Tags 为可选字段，含义与 Metadata 相同。
+type ExecDescription struct {
+	Protocol    int      `json:"protocol"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Version     string   `json:"version,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// ExecRequest 为 run 请求通过标准输入传给外部插件的内容。
+type ExecRequest struct {
+	Protocol int                `json:"protocol"`
+	Target   Target             `json:"target"`
+	Paths    config.PathsConfig `json:"paths"`
+	// Timeout 为本次运行的超时时间（秒），超时后插件所在的进程组会被终止。
+	Timeout float64 `json:"timeout"`
+}
+
+// ExecPlugin 以子进程方式运行外部插件，实现 Plugin 接口。
+type ExecPlugin struct {
+	path        string
+	name        string
+	description string
+	meta        Metadata
+	cfg         config.PluginsConfig
+}
+
+// Path 返回外部插件可执行文件的路径。
+func (p *ExecPlugin) Path() string { return p.path }
+
+func (p *ExecPlugin) Name() string { return p.name }
+
+func (p *ExecPlugin) Description() string { return p.description }
+
+// Metadata 返回 describe 应答中的版本与标签，并附加 external 标签。
+func (p *ExecPlugin) Metadata() Metadata { return p.meta }
+
+// Run 以 run 请求启动外部插件并解析其输出的诊断结果。
+// 插件通过 rc.Config.Paths 而不是 rc.Collector 读取文件，因此不支持快照包等非本地文件系统。
+func (p *ExecPlugin) Run(ctx context.Context, rc *RunContext) (models.Result, error) {
+	timeout := p.cfg.Timeout
+	if timeout <= 0 {
+		timeout = config.NewDefault().Plugins.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := json.Marshal(ExecRequest{
+		Protocol: ExecProtocolVersion,
+		Target:   rc.Target,
+		Paths:    rc.Config.Paths,
+		Timeout:  timeout.Seconds(),
+	})
+	if err != nil {
+		return models.Result{}, err
+	}
+	stdout, err := p.exec(ctx, rc, "run", req)
+	if err != nil {
+		return models.Result{}, err
+	}
+
+	var result models.Result
+	if err := json.Unmarshal(stdout, &result); err != nil {
+		return models.Result{}, fmt.Errorf("external plugin %s: parse result: %w", p.name, err)
+	}
+	if err := p.validate(&result); err != nil {
+		return models.Result{}, fmt.Errorf("external plugin %s: %w", p.name, err)
+	}
+	return result, nil
+}
+
+// validate 校验外部插件输出的结果，并补全插件名与空切片，使其与内置插件的输出一致。
+func (p *ExecPlugin) validate(result *models.Result) error {
+	if result.Plugin != "" && result.Plugin != p.name {
+		return fmt.Errorf("result plugin %q does not match %q", result.Plugin, p.name)
+	}
+	result.Plugin = p.name
+	for i, f := range result.Findings {
+		if f.Title == "" {
+			return fmt.Errorf("finding %d has no title", i)
+		}
+		switch f.Severity {
+		case models.SeverityInfo, models.SeverityWarning, models.SeverityError, models.SeverityCritical:
+		default:
+			return fmt.Errorf("finding %q has unknown severity %q", f.ID, f.Severity)
+		}
+	}
+	if result.Findings == nil {
+		result.Findings = []models.Finding{}
+	}
+	if result.Suggestions == nil {
+		result.Suggestions = []models.Suggestion{}
+	}
+	return nil
+}
+
+// exec 以受限的环境运行外部插件：工作目录为 /，只保留 PATH、LANG 与 OSSRE_* 环境变量，
+// 在独立的进程组中运行并在超时后终止整个进程组；可通过配置以其他用户身份运行。
+func (p *ExecPlugin) exec(ctx context.Context, rc *RunContext, verb string, stdin []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, p.path, verb)
+	cmd.Dir = "/"
+	cmd.Env = execEnv(rc)
+	cmd.Stdin = bytes.NewReader(stdin)
+	stdout := &limitedBuffer{max: execMaxStdout}
+	stderr := &limitedBuffer{max: execMaxStderr}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := sandbox(cmd, p.cfg.User); err != nil {
+		return nil, fmt.Errorf("external plugin %s: %w", p.name, err)
+	}
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if stderr.Len() > 0 && rc != nil {
+		rc.Logger.Debug("external plugin stderr", "verb", verb, "stderr", strings.TrimSpace(stderr.String()))
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("external plugin %s: %s timed out", p.name, verb)
+	}
+	if err != nil {
+		msg := lastLine(stderr.String())
+		if msg != "" {
+			return nil, fmt.Errorf("external plugin %s: %s: %w: %s", p.name, verb, err, msg)
+		}
+		return nil, fmt.Errorf("external plugin %s: %s: %w", p.name, verb, err)
+	}
+	if stdout.overflow {
+		return nil, fmt.Errorf("external plugin %s: %s output exceeds %d bytes", p.name, verb, execMaxStdout)
+	}
+	return stdout.Bytes(), nil
+}
+
+// execEnv 返回外部插件的环境变量，便于脚本类插件不解析标准输入即可获取目标与数据位置。
+func execEnv(rc *RunContext) []string {
+	env := []string{
+		"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"LANG=C.UTF-8",
+		"OSSRE_PROTOCOL=" + strconv.Itoa(ExecProtocolVersion),
+	}
+	if rc == nil {
+		return env
+	}
+	pids := make([]string, 0, len(rc.Target.PIDs))
+	for _, pid := range rc.Target.PIDs {
+		pids = append(pids, strconv.Itoa(pid))
+	}
+	return append(env,
+		"OSSRE_PROC_ROOT="+rc.Config.Paths.ProcRoot,
+		"OSSRE_SYS_ROOT="+rc.Config.Paths.SysRoot,
+		"OSSRE_ETC_ROOT="+rc.Config.Paths.EtcRoot,
+		"OSSRE_TARGET_PIDS="+strings.Join(pids, ","),
+	)
+}
+
+// DiscoverExecPlugins 加载 cfg.Dir 中的外部插件：校验目录与文件的属主和权限，
+// 以 describe 请求获取插件名称与说明，并校验协议版本与名称格式。
+// 单个插件无效时记录到 errs 并跳过；目录不存在时返回空结果。
+func DiscoverExecPlugins(cfg config.PluginsConfig) (plugins []*ExecPlugin, errs []error) {
+	if cfg.Dir == "" {
+		return nil, nil
+	}
+	st, err := os.Stat(cfg.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, []error{err}
+	}
+	if err := checkOwnership(cfg.Dir, st); err != nil {
+		return nil, []error{err}
+	}
+	entries, err := os.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, []error{err}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	seen := make(map[string]string)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(cfg.Dir, e.Name())
+		st, err := os.Stat(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !st.Mode().IsRegular() || st.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		if err := checkOwnership(path, st); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		p, err := describeExecPlugin(path, cfg)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if prev, dup := seen[p.name]; dup {
+			errs = append(errs, fmt.Errorf("%s: plugin name %q already provided by %s", path, p.name, prev))
+			continue
+		}
+		seen[p.name] = path
+		plugins = append(plugins, p)
+	}
+	return plugins, errs
+}
+
+func describeExecPlugin(path string, cfg config.PluginsConfig) (*ExecPlugin, error) {
+	p := &ExecPlugin{path: path, name: filepath.Base(path), cfg: cfg}
+	ctx, cancel := context.WithTimeout(context.Background(), execDescribeTimeout)
+	defer cancel()
+	out, err := p.exec(ctx, nil, "describe", nil)
+	if err != nil {
+		return nil, err
+	}
+	var d ExecDescription
+	if err := json.Unmarshal(out, &d); err != nil {
+		return nil, fmt.Errorf("%s: parse describe output: %w", path, err)
+	}
+	if d.Protocol != ExecProtocolVersion {
+		return nil, fmt.Errorf("%s: unsupported protocol version %d, want %d", path, d.Protocol, ExecProtocolVersion)
+	}
+	if !execNamePattern.MatchString(d.Name) {
+		return nil, fmt.Errorf("%s: invalid plugin name %q", path, d.Name)
+	}
+	p.name = d.Name
+	p.description = d.Description
+	p.meta = Metadata{Version: d.Version, Tags: append(d.Tags, ExternalTag)}
+	return p, nil
+}
+
+// limitedBuffer 为有大小上限的输出缓冲，超出部分被丢弃并记录溢出。
+type limitedBuffer struct {
+	bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(data []byte) (int, error) {
+	if room := b.max - b.Len(); len(data) > room {
+		b.overflow = true
+		if room > 0 {
+			b.Buffer.Write(data[:room])
+		}
+		return len(data), nil
+	}
+	return b.Buffer.Write(data)
+}
+
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
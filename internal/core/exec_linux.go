@@ -0,0 +1,66 @@
+//go:build linux
+// +build linux
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// sandbox 让外部插件在独立的进程组中运行，ossre 退出或超时时终止整个进程组；
+// username 非空时以该用户身份运行，附加组替换为该用户自身所属的组，不继承 ossre 的附加组（如 root 的 disk、docker）。
+func sandbox(cmd *exec.Cmd, username string) error {
+	attr := &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGKILL}
+	if username != "" {
+		u, err := user.Lookup(username)
+		if err != nil {
+			return err
+		}
+		uid, err := strconv.ParseUint(u.Uid, 10, 32)
+		if err != nil {
+			return fmt.Errorf("user %s: invalid uid %q", username, u.Uid)
+		}
+		gid, err := strconv.ParseUint(u.Gid, 10, 32)
+		if err != nil {
+			return fmt.Errorf("user %s: invalid gid %q", username, u.Gid)
+		}
+		attr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid), Groups: userGroups(u)}
+	}
+	cmd.SysProcAttr = attr
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	return nil
+}
+
+// userGroups 返回用户所属的组，无法查询时返回空列表，即不保留任何附加组。
+func userGroups(u *user.User) []uint32 {
+	groups := []uint32{}
+	ids, err := u.GroupIds()
+	if err != nil {
+		return groups
+	}
+	for _, id := range ids {
+		if gid, err := strconv.ParseUint(id, 10, 32); err == nil {
+			groups = append(groups, uint32(gid))
+		}
+	}
+	return groups
+}
+
+// checkOwnership 拒绝不属于 root 或当前用户、或对组与其他用户可写的插件目录与文件，
+// 避免 ossre 以 root 运行时执行被他人替换的程序。
+func checkOwnership(path string, st os.FileInfo) error {
+	if sys, ok := st.Sys().(*syscall.Stat_t); ok && sys.Uid != 0 && int(sys.Uid) != os.Geteuid() {
+		return fmt.Errorf("%s: owned by uid %d, want root or the current user", path, sys.Uid)
+	}
+	if st.Mode().Perm()&0o022 != 0 {
+		return fmt.Errorf("%s: writable by group or others", path)
+	}
+	return nil
+}
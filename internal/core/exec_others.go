@@ -0,0 +1,26 @@
+//go:build !linux
+// +build !linux
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// sandbox 在非 Linux 平台上不支持进程组隔离与切换用户，只能以 ossre 自身的身份运行外部插件。
+func sandbox(cmd *exec.Cmd, username string) error {
+	if username != "" {
+		return fmt.Errorf("running plugins as user %s is only supported on linux", username)
+	}
+	return nil
+}
+
+// checkOwnership 拒绝对组与其他用户可写的插件目录与文件。
+func checkOwnership(path string, st os.FileInfo) error {
+	if st.Mode().Perm()&0o022 != 0 {
+		return fmt.Errorf("%s: writable by group or others", path)
+	}
+	return nil
+}
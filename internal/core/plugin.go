@@ -2,7 +2,12 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"strings"
 
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/pkg/config"
 	"github.com/supperghost/ossre/pkg/models"
 )
 
@@ -12,9 +17,59 @@ type Plugin interface {
 	Name() string
 	// Description 返回插件的简要说明，便于 list 命令展示。
 	Description() string
-	// Run 执行一次诊断。
-	// TODO: 后续可以接受配置、日志接口等参数。
-	Run(ctx context.Context) (models.Result, error)
+	// Run 执行一次诊断，rc 由 Runner 构造，保证非 nil。
+	Run(ctx context.Context, rc *RunContext) (models.Result, error)
+}
+
+// RunContext 携带一次运行所需的诊断目标、配置与日志接口，由 Runner 显式传递给插件。
+type RunContext struct {
+	// Target 为本次诊断的目标对象。
+	Target Target
+	// Config 为运行时配置，保证非 nil。
+	Config *config.Config
+	// Logger 为插件日志接口，已附带 plugin 属性，保证非 nil。
+	Logger *slog.Logger
+	// Collector 为插件读取 /proc、/sys、/etc 等数据的唯一入口，保证非 nil。
+	// 插件应始终使用宿主视角的绝对路径，由底层 FS 映射到备用根目录等实际位置；
+	// 同一次运行内的读取结果会被缓存，趋势采样等需要重新读取的场景应使用 Collector.Fresh()。
+	Collector *collectors.Collector
+	// Scenarios 为本次运行选中的场景 ID 模式，为空表示运行全部场景，插件应通过 ScenarioEnabled 判断。
+	Scenarios []string
+}
+
+// ScenarioEnabled 表示场景 id 是否被选中，插件在运行每个场景前调用以跳过未选中的场景。
+func (rc *RunContext) ScenarioEnabled(id string) bool {
+	if len(rc.Scenarios) == 0 {
+		return true
+	}
+	for _, pattern := range rc.Scenarios {
+		if MatchScenario(pattern, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Skip 返回案例 caseID 因读取 path 失败而未评估的记录，插件应将其放入 Result.Skipped 而不是按默认值继续评估。
+// 权限不足时原因中列出完整评估所需的权限 requires 与预检得到的当前身份。
+func (rc *RunContext) Skip(caseID, path string, err error, requires ...string) models.SkippedCheck {
+	s := models.SkippedCheck{Case: caseID, Path: path, Permission: collectors.IsPermission(err)}
+	if !s.Permission {
+		s.Reason = fmt.Sprintf("无法读取 %s: %v", path, err)
+		return s
+	}
+	s.Reason = fmt.Sprintf("权限不足，无法读取 %s", path)
+	if len(requires) > 0 {
+		s.Reason += fmt.Sprintf("（需要 root 或 %s）", strings.Join(requires, "、"))
+	}
+	pf := rc.Collector.Preflight()
+	if pf.EUID >= 0 {
+		s.Reason += fmt.Sprintf("，当前有效 UID 为 %d", pf.EUID)
+	}
+	if pf.UserNS == collectors.NamespaceNested {
+		s.Reason += "，运行在非初始 user namespace 中"
+	}
+	return s
 }
 
 // RunResult 表示单个插件执行后的结果，包含插件名称和诊断结果。
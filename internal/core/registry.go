@@ -0,0 +1,181 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/supperghost/ossre/internal/collectors"
+)
+
+// Metadata 描述插件的静态属性，供 list 展示与筛选、按平台选择插件以及按场景、案例 ID 检索。
+type Metadata struct {
+	// Version 为插件自身的版本，与 ossre 的版本无关。
+	Version string
+	// Tags 为插件的分类标签，如 network、storage、capacity。
+	Tags []string
+	// OS 为支持的操作系统（runtime.GOOS 的取值），为空表示不限。
+	OS []string
+	// MinKernel 为要求的最低内核版本，如 "2.6.24"，为空表示不限。
+	MinKernel string
+	// Privileges 为完整诊断所需的权限，如 CAP_SYS_PTRACE；缺少时插件降级运行而不是失败。
+	Privileges []string
+	// Scenarios 为插件包含的场景 ID。
+	Scenarios []string
+	// CaseIDs 为插件可能产生的案例 ID，以 * 结尾的表示前缀。
+	CaseIDs []string
+}
+
+// HasTag 表示插件是否带有给定标签中的任意一个。
+func (m Metadata) HasTag(tags ...string) bool {
+	for _, want := range tags {
+		for _, t := range m.Tags {
+			if t == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Platform 为运行诊断的平台，用于判断插件是否适用。
+type Platform struct {
+	OS string
+	// Kernel 为内核版本，如 "5.15.0-91-generic"，未知时为空。
+	Kernel string
+}
+
+// CurrentPlatform 返回当前平台；内核版本取自 c 中的 /proc/sys/kernel/osrelease，
+// 因此通过 --root 诊断宿主机时为宿主机的内核版本。
+func CurrentPlatform(c *collectors.Collector) Platform {
+	p := Platform{OS: runtime.GOOS}
+	if release, err := c.Sysctl("kernel.osrelease"); err == nil {
+		p.Kernel = release
+	}
+	return p
+}
+
+// Supports 判断插件是否适用于平台 p，不适用时返回原因。内核版本未知时不做内核版本检查。
+func (m Metadata) Supports(p Platform) (bool, string) {
+	if len(m.OS) > 0 {
+		ok := false
+		for _, goos := range m.OS {
+			ok = ok || goos == p.OS
+		}
+		if !ok {
+			return false, fmt.Sprintf("requires %s, running on %s", strings.Join(m.OS, "/"), p.OS)
+		}
+	}
+	if m.MinKernel != "" && p.Kernel != "" && compareKernel(p.Kernel, m.MinKernel) < 0 {
+		return false, fmt.Sprintf("requires kernel >= %s, running %s", m.MinKernel, p.Kernel)
+	}
+	return true, ""
+}
+
+// compareKernel 按数字逐段比较两个内核版本号，忽略首个非数字、非点号字符之后的部分（如 -91-generic）。
+func compareKernel(a, b string) int {
+	pa, pb := kernelParts(a), kernelParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func kernelParts(v string) []int {
+	if i := strings.IndexFunc(v, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); i >= 0 {
+		v = v[:i]
+	}
+	var parts []int
+	for _, s := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(s)
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// MatchScenario 表示场景或案例 ID 是否匹配模式 pattern。模式支持 path.Match 的通配符，
+// 且 * 可以匹配点号，如 kernel.net.*、*.trend。
+func MatchScenario(pattern, id string) bool {
+	ok, err := path.Match(pattern, id)
+	return ok && err == nil
+}
+
+// ScenarioOf 返回案例 ID 所属的场景：案例 ID 等于场景 ID 或以 "<场景 ID>." 开头。
+func (m Metadata) ScenarioOf(caseID string) (string, bool) {
+	for _, s := range m.Scenarios {
+		if caseID == s || strings.HasPrefix(caseID, s+".") {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// MetadataProvider 由自带元数据的插件（如外部插件）实现，未注册到全局注册表的插件通过它提供元数据。
+type MetadataProvider interface {
+	Metadata() Metadata
+}
+
+type registration struct {
+	newFn func() Plugin
+	meta  Metadata
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]registration)
+)
+
+// Register 将内置插件登记到全局注册表，应在插件包的 init 中调用。
+// 名称取自 newFn 创建的实例；名称为空或重复时 panic，与 database/sql.Register 的约定一致。
+func Register(newFn func() Plugin, meta Metadata) {
+	name := newFn().Name()
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if name == "" {
+		panic("core: Register plugin with empty name")
+	}
+	if _, dup := registry[name]; dup {
+		panic("core: Register called twice for plugin " + name)
+	}
+	registry[name] = registration{newFn: newFn, meta: meta}
+}
+
+// Registered 返回全局注册表中全部插件的新实例，按名称排序。
+func Registered() []Plugin {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	plugins := make([]Plugin, 0, len(names))
+	for _, name := range names {
+		plugins = append(plugins, registry[name].newFn())
+	}
+	return plugins
+}
+
+// LookupMetadata 返回全局注册表中插件的元数据。
+func LookupMetadata(name string) (Metadata, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	reg, ok := registry[name]
+	return reg.meta, ok
+}
@@ -3,18 +3,108 @@ package core
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"sort"
+	"time"
 
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/pkg/config"
 	"github.com/supperghost/ossre/pkg/models"
 )
 
 // Runner 负责插件注册、列出和按名称运行。
-// TODO: 后续可在此注入上下文信息（如主机信息）和配置对象。
 type Runner struct {
-	plugins map[string]Plugin
+	plugins  map[string]Plugin
+	config   *config.Config
+	logger   *slog.Logger
+	fs       collectors.FS
+	observer func(Event)
+	// execPlugins 表示是否从 config.Plugins.Dir 加载外部插件，pluginErrs 为加载失败的原因。
+	execPlugins bool
+	pluginErrs  []error
+	// scenarios 为选中的场景 ID 模式，为空表示运行全部场景。
+	scenarios []string
+}
+
+// Option 用于定制 Runner。
+type Option func(*Runner)
+
+// WithConfig 指定传递给插件的运行时配置。
+func WithConfig(cfg *config.Config) Option {
+	return func(r *Runner) {
+		if cfg != nil {
+			r.config = cfg
+		}
+	}
+}
+
+// WithLogger 指定 Runner 与插件使用的日志接口。
+func WithLogger(logger *slog.Logger) Option {
+	return func(r *Runner) {
+		if logger != nil {
+			r.logger = logger
+		}
+	}
+}
+
+// WithFS 指定插件读取文件所用的文件系统，未指定时按配置中的 Paths 访问本地文件系统。
+func WithFS(fsys collectors.FS) Option {
+	return func(r *Runner) {
+		if fsys != nil {
+			r.fs = fsys
+		}
+	}
+}
+
+// WithObserver 指定插件开始与结束时的回调，用于向调用方汇报运行进度。
+// 回调在插件所在的 goroutine 中同步调用，不应阻塞。
+func WithObserver(fn func(Event)) Option {
+	return func(r *Runner) {
+		r.observer = fn
+	}
+}
+
+// WithExecPlugins 使 Runner 在创建时从配置的 Plugins.Dir 发现外部插件，与内置插件一同注册。
+// 与内置插件同名的外部插件被忽略；通过 WithFS 指定了非本地文件系统（如快照包）时不加载外部插件，
+// 因为外部插件直接读取本地文件。
+func WithExecPlugins() Option {
+	return func(r *Runner) {
+		r.execPlugins = true
+	}
+}
+
+// WithScenarios 只运行与 patterns 匹配的场景（见 MatchScenario）。插件通过 RunContext.ScenarioEnabled
+// 跳过未选中的场景；对声明了场景的插件，Runner 还会移除不属于选中场景的发现及其建议。
+func WithScenarios(patterns []string) Option {
+	return func(r *Runner) {
+		r.scenarios = patterns
+	}
+}
+
+// EventPhase 为插件运行事件的阶段。
+type EventPhase string
+
+const (
+	EventStarted  EventPhase = "started"
+	EventFinished EventPhase = "finished"
+	EventFailed   EventPhase = "failed"
+)
+
+// Event 描述单个插件的一次运行进度。
+type Event struct {
+	Plugin string
+	Phase  EventPhase
+	// Elapsed 为插件运行耗时，仅在结束事件中有效。
+	Elapsed time.Duration
+	// Findings 为插件产出的发现数，仅在 EventFinished 中有效。
+	Findings int
+	// Err 为插件返回的错误，仅在 EventFailed 中有效。
+	Err error
 }
 
 // NewRunner 使用给定的插件集合创建一个新的 Runner。
-func NewRunner(plugins []Plugin) *Runner {
+// 未指定配置与日志时分别使用 config.NewDefault() 与丢弃所有输出的 Logger。
+func NewRunner(plugins []Plugin, opts ...Option) *Runner {
 	m := make(map[string]Plugin, len(plugins))
 	for _, p := range plugins {
 		if p == nil {
@@ -26,24 +116,204 @@ func NewRunner(plugins []Plugin) *Runner {
 		}
 		m[name] = p
 	}
-	return &Runner{plugins: m}
+	r := &Runner{
+		plugins: m,
+		config:  config.NewDefault(),
+		logger:  slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.execPlugins && r.fs == nil {
+		r.registerExecPlugins()
+	}
+	if r.fs == nil {
+		r.fs = collectors.NewHostFS(r.config.Paths)
+	}
+	return r
 }
 
-// ListPlugins 返回已注册的插件列表，遍历顺序未定义。
+// registerExecPlugins 发现并注册外部插件，失败原因记录到日志并可通过 PluginErrors 获取。
+func (r *Runner) registerExecPlugins() {
+	plugins, errs := DiscoverExecPlugins(r.config.Plugins)
+	for _, p := range plugins {
+		if _, dup := r.plugins[p.Name()]; dup {
+			errs = append(errs, fmt.Errorf("%s: plugin name %q conflicts with a built-in plugin", p.Path(), p.Name()))
+			continue
+		}
+		r.plugins[p.Name()] = p
+		r.logger.Debug("external plugin registered", "plugin", p.Name(), "path", p.Path())
+	}
+	for _, err := range errs {
+		r.logger.Warn("external plugin skipped", "error", err)
+	}
+	r.pluginErrs = errs
+}
+
+// PluginErrors 返回加载外部插件时遇到的错误，未启用外部插件时为空。
+func (r *Runner) PluginErrors() []error {
+	return r.pluginErrs
+}
+
+// ListPlugins 返回已注册的插件列表，按名称排序。
 func (r *Runner) ListPlugins() []Plugin {
 	result := make([]Plugin, 0, len(r.plugins))
 	for _, p := range r.plugins {
 		result = append(result, p)
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
 	return result
 }
 
-// Run 根据名称运行指定插件。
-func (r *Runner) Run(ctx context.Context, name string) (models.Result, error) {
+// Metadata 返回已注册插件的元数据：插件自带元数据时（如外部插件）以其为准，否则取自全局注册表。
+func (r *Runner) Metadata(name string) Metadata {
+	if mp, ok := r.plugins[name].(MetadataProvider); ok {
+		return mp.Metadata()
+	}
+	meta, _ := LookupMetadata(name)
+	return meta
+}
+
+// Applicable 按平台筛选已注册的插件，返回适用插件的名称（按名称排序）与不适用插件的原因。
+func (r *Runner) Applicable(p Platform) (names []string, skipped map[string]string) {
+	skipped = make(map[string]string)
+	for _, plugin := range r.ListPlugins() {
+		if ok, reason := r.Metadata(plugin.Name()).Supports(p); !ok {
+			skipped[plugin.Name()] = reason
+			continue
+		}
+		names = append(names, plugin.Name())
+	}
+	return names, skipped
+}
+
+// ScenarioPlugins 返回声明了与 patterns 匹配的场景的插件名称（按名称排序）。
+// 某个模式不匹配任何场景时返回错误，避免拼写错误导致静默地什么也不运行。
+func (r *Runner) ScenarioPlugins(patterns []string) ([]string, error) {
+	matched := make(map[string]bool)
+	var names []string
+	for _, p := range r.ListPlugins() {
+		found := false
+		for _, s := range r.Metadata(p.Name()).Scenarios {
+			for _, pattern := range patterns {
+				if MatchScenario(pattern, s) {
+					matched[pattern] = true
+					found = true
+				}
+			}
+		}
+		if found {
+			names = append(names, p.Name())
+		}
+	}
+	for _, pattern := range patterns {
+		if !matched[pattern] {
+			return nil, fmt.Errorf("no scenario matches %q", pattern)
+		}
+	}
+	return names, nil
+}
+
+// Platform 返回 Runner 读取数据的平台，见 CurrentPlatform。
+func (r *Runner) Platform() Platform {
+	return CurrentPlatform(collectors.NewCollector(r.fs))
+}
+
+// Run 根据名称运行指定插件，并将诊断目标、配置与日志显式传递给插件。
+// 每次调用使用独立的采集缓存。
+func (r *Runner) Run(ctx context.Context, name string, target Target) (models.Result, error) {
+	c := collectors.NewCollector(r.fs)
+	target, err := target.ResolveContainer(c)
+	if err != nil {
+		return models.Result{}, err
+	}
+	return r.run(ctx, name, target, c)
+}
+
+// RunAll 按顺序运行多个插件，插件之间共享同一份采集缓存，使每个文件在本次运行中只读取一次。
+// 遇到未知插件时立即返回错误；单个插件运行失败时返回已完成的结果与该错误。
+func (r *Runner) RunAll(ctx context.Context, names []string, target Target) ([]RunResult, error) {
+	for _, name := range names {
+		if _, ok := r.plugins[name]; !ok {
+			return nil, fmt.Errorf("unknown plugin: %s", name)
+		}
+	}
+	c := collectors.NewCollector(r.fs)
+	target, err := target.ResolveContainer(c)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]RunResult, 0, len(names))
+	for _, name := range names {
+		result, err := r.run(ctx, name, target, c)
+		if err != nil {
+			return results, fmt.Errorf("run plugin %s: %w", name, err)
+		}
+		results = append(results, RunResult{PluginName: name, Result: result})
+	}
+	return results, nil
+}
+
+func (r *Runner) run(ctx context.Context, name string, target Target, c *collectors.Collector) (models.Result, error) {
 	p, ok := r.plugins[name]
 	if !ok {
 		return models.Result{}, fmt.Errorf("unknown plugin: %s", name)
 	}
-	// TODO: 统一的前后钩子、日志、超时控制等
-	return p.Run(ctx)
+	rc := &RunContext{
+		Target:    target,
+		Config:    r.config,
+		Logger:    r.logger.With("plugin", name),
+		Collector: c,
+		Scenarios: r.scenarios,
+	}
+	// TODO: 统一的超时控制等
+	start := time.Now()
+	rc.Logger.Debug("plugin started", "target", target)
+	r.notify(Event{Plugin: name, Phase: EventStarted})
+	result, err := p.Run(ctx, rc)
+	elapsed := time.Since(start)
+	if len(r.scenarios) > 0 {
+		result = filterScenarios(result, r.Metadata(name), rc)
+	}
+	if err != nil {
+		rc.Logger.Debug("plugin failed", "elapsed", elapsed, "error", err)
+		r.notify(Event{Plugin: name, Phase: EventFailed, Elapsed: elapsed, Err: err})
+		return result, err
+	}
+	rc.Logger.Debug("plugin finished", "elapsed", elapsed, "findings", len(result.Findings))
+	r.notify(Event{Plugin: name, Phase: EventFinished, Elapsed: elapsed, Findings: len(result.Findings)})
+	return result, nil
+}
+
+// filterScenarios 移除属于未选中场景的发现、建议与未评估记录，不属于任何已声明场景的发现保留。
+func filterScenarios(result models.Result, meta Metadata, rc *RunContext) models.Result {
+	dropped := make(map[string]bool)
+	findings := result.Findings[:0:0]
+	for _, f := range result.Findings {
+		if s, ok := meta.ScenarioOf(f.ID); ok && !rc.ScenarioEnabled(s) {
+			dropped[f.ID] = true
+			continue
+		}
+		findings = append(findings, f)
+	}
+	suggestions := result.Suggestions[:0:0]
+	for _, s := range result.Suggestions {
+		if !dropped[s.FindingID] {
+			suggestions = append(suggestions, s)
+		}
+	}
+	skipped := result.Skipped[:0:0]
+	for _, sc := range result.Skipped {
+		if s, ok := meta.ScenarioOf(sc.Case); !ok || rc.ScenarioEnabled(s) {
+			skipped = append(skipped, sc)
+		}
+	}
+	result.Findings, result.Suggestions, result.Skipped = findings, suggestions, skipped
+	return result
+}
+
+func (r *Runner) notify(e Event) {
+	if r.observer != nil {
+		r.observer(e)
+	}
 }
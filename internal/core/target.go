@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/supperghost/ossre/internal/collectors"
+)
+
+// Target 描述一次诊断所针对的对象。
+// 各字段均为可选，插件按需读取自己关心的部分，未指定时由插件决定默认行为（如 maxproc 回退为 ossre 自身 PID）。
+type Target struct {
+	// PIDs 为目标进程列表，单进程插件只使用第一个。
+	PIDs []int
+	// AllProcesses 表示对主机上所有进程进行扫描。
+	AllProcesses bool
+	// PIDSelector 为全主机扫描的进程筛选表达式，如 "comm:java,user:app"。
+	PIDSelector string
+	// CgroupPath 为目标 cgroup 路径（相对于 cgroup 挂载点），如 "/system.slice/nginx.service"。
+	CgroupPath string
+	// ContainerID 为目标容器 ID，可以是短 ID 或带 "containerd://" 等运行时前缀的形式；
+	// 未指定 PIDs 时由 Runner 定位容器的 init 进程与 cgroup（见 ResolveContainer）。
+	ContainerID string
+	// NetNS 为目标网络 namespace，可以是名称（ip netns）或 /proc/<pid>/ns/net 形式的路径。
+	NetNS string
+	// MountRoot 为目标挂载 namespace 的根目录，用于读取容器内的配置文件。
+	MountRoot string
+}
+
+// PrimaryPID 返回首个目标 PID，未指定时返回 0。
+func (t Target) PrimaryPID() int {
+	for _, pid := range t.PIDs {
+		if pid > 0 {
+			return pid
+		}
+	}
+	return 0
+}
+
+// IsHostScan 表示目标是否为全主机或按条件筛选的多进程扫描。
+func (t Target) IsHostScan() bool {
+	return t.AllProcesses || t.PIDSelector != ""
+}
+
+// ResolveContainer 在指定了 ContainerID 而未指定 PIDs 时，扫描 /proc/*/cgroup 定位容器，
+// 以容器的 init 进程作为目标 PID，并将 ContainerID 与 CgroupPath 替换为完整的容器 ID 与其 cgroup 路径。
+func (t Target) ResolveContainer(c *collectors.Collector) (Target, error) {
+	if t.ContainerID == "" || len(t.PIDs) > 0 {
+		return t, nil
+	}
+	ct, err := c.FindContainer(t.ContainerID)
+	if err != nil {
+		return t, fmt.Errorf("resolve container: %w", err)
+	}
+	t.ContainerID = ct.ID
+	t.PIDs = []int{ct.PID}
+	t.CgroupPath = ct.CgroupPath
+	return t, nil
+}
+
+// LogValue 实现 slog.LogValuer，仅输出已指定的字段。
+func (t Target) LogValue() slog.Value {
+	var attrs []slog.Attr
+	if len(t.PIDs) > 0 {
+		attrs = append(attrs, slog.Any("pids", t.PIDs))
+	}
+	if t.AllProcesses {
+		attrs = append(attrs, slog.Bool("all_processes", true))
+	}
+	for _, kv := range [][2]string{
+		{"pid_selector", t.PIDSelector},
+		{"cgroup", t.CgroupPath},
+		{"container", t.ContainerID},
+		{"netns", t.NetNS},
+		{"mount_root", t.MountRoot},
+	} {
+		if kv[1] != "" {
+			attrs = append(attrs, slog.String(kv[0], kv[1]))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
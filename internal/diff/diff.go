@@ -0,0 +1,233 @@
+// Package diff 按案例 ID 对比两次诊断结果，用于验证修复是否生效以及发现升级后的回归。
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// Status 为单条发现在两次报告之间的变化类型。
+type Status string
+
+const (
+	StatusResolved  Status = "resolved"
+	StatusNew       Status = "new"
+	StatusChanged   Status = "changed"
+	StatusUnchanged Status = "unchanged"
+)
+
+// FindingChange 描述一条发现的变化。已解决的发现只有 Old 一侧的字段，新增的发现只有 New 一侧的字段。
+type FindingChange struct {
+	Plugin      string          `json:"plugin"`
+	ID          string          `json:"id"`
+	Title       string          `json:"title"`
+	Status      Status          `json:"status"`
+	OldSeverity models.Severity `json:"old_severity,omitempty"`
+	NewSeverity models.Severity `json:"new_severity,omitempty"`
+}
+
+// Severity 返回变化后的严重级别，已解决的发现返回原级别。
+func (c FindingChange) Severity() models.Severity {
+	if c.NewSeverity != "" {
+		return c.NewSeverity
+	}
+	return c.OldSeverity
+}
+
+// Regressed 表示该变化是否为回归：新增的发现，或级别升高的发现。
+func (c FindingChange) Regressed() bool {
+	switch c.Status {
+	case StatusNew:
+		return true
+	case StatusChanged:
+		return c.NewSeverity.Rank() > c.OldSeverity.Rank()
+	default:
+		return false
+	}
+}
+
+// MetricChange 描述一个数值证据的变化，Old 或 New 为 nil 表示该侧没有这个指标。
+type MetricChange struct {
+	Plugin string            `json:"plugin"`
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Old    *float64          `json:"old"`
+	New    *float64          `json:"new"`
+}
+
+// Series 返回 name{k="v",...} 形式的指标标识，标签按名称排序。
+func (m MetricChange) Series() string {
+	if len(m.Labels) == 0 {
+		return m.Name
+	}
+	keys := make([]string, 0, len(m.Labels))
+	for k := range m.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%q", k, m.Labels[k]))
+	}
+	return m.Name + "{" + strings.Join(parts, ",") + "}"
+}
+
+// Diff 为两次报告的对比结果，各列表保持发现在报告中的原始顺序。
+type Diff struct {
+	Resolved  []FindingChange `json:"resolved"`
+	New       []FindingChange `json:"new"`
+	Changed   []FindingChange `json:"changed"`
+	Unchanged []FindingChange `json:"unchanged"`
+	// Metrics 只包含取值发生变化、新出现或消失的数值证据。
+	Metrics []MetricChange `json:"metrics"`
+}
+
+// Regressions 返回新增或级别升高、且变化后级别不低于 min 的发现。
+func (d *Diff) Regressions(min models.Severity) []FindingChange {
+	var out []FindingChange
+	for _, list := range [][]FindingChange{d.New, d.Changed} {
+		for _, c := range list {
+			if c.Regressed() && c.Severity().Rank() >= min.Rank() {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// Compare 按 "插件/案例 ID" 对比 old 与 cur 中的发现，并对比两侧的数值证据。
+// 同一插件内重复的案例 ID 按出现顺序分别配对；没有 ID 的发现以标题代替。
+func Compare(old, cur []models.Result) *Diff {
+	d := &Diff{
+		Resolved:  []FindingChange{},
+		New:       []FindingChange{},
+		Changed:   []FindingChange{},
+		Unchanged: []FindingChange{},
+		Metrics:   []MetricChange{},
+	}
+
+	oldFindings, oldOrder := indexFindings(old)
+	curFindings, curOrder := indexFindings(cur)
+	for _, key := range oldOrder {
+		o := oldFindings[key]
+		n, ok := curFindings[key]
+		if !ok {
+			d.Resolved = append(d.Resolved, FindingChange{
+				Plugin: o.plugin, ID: o.ID, Title: o.Title, Status: StatusResolved, OldSeverity: o.Severity,
+			})
+			continue
+		}
+		c := FindingChange{
+			Plugin: n.plugin, ID: n.ID, Title: n.Title,
+			OldSeverity: o.Severity, NewSeverity: n.Severity,
+		}
+		if o.Severity != n.Severity {
+			c.Status = StatusChanged
+			d.Changed = append(d.Changed, c)
+		} else {
+			c.Status = StatusUnchanged
+			d.Unchanged = append(d.Unchanged, c)
+		}
+	}
+	for _, key := range curOrder {
+		if _, ok := oldFindings[key]; ok {
+			continue
+		}
+		n := curFindings[key]
+		d.New = append(d.New, FindingChange{
+			Plugin: n.plugin, ID: n.ID, Title: n.Title, Status: StatusNew, NewSeverity: n.Severity,
+		})
+	}
+
+	oldMetrics, oldSeries := indexMetrics(old)
+	curMetrics, curSeries := indexMetrics(cur)
+	for _, key := range oldSeries {
+		o := oldMetrics[key]
+		n, ok := curMetrics[key]
+		switch {
+		case !ok:
+			d.Metrics = append(d.Metrics, MetricChange{Plugin: o.plugin, Name: o.Name, Labels: o.Labels, Old: &o.Value})
+		case o.Value != n.Value:
+			d.Metrics = append(d.Metrics, MetricChange{Plugin: n.plugin, Name: n.Name, Labels: n.Labels, Old: &o.Value, New: &n.Value})
+		}
+	}
+	for _, key := range curSeries {
+		if _, ok := oldMetrics[key]; ok {
+			continue
+		}
+		n := curMetrics[key]
+		d.Metrics = append(d.Metrics, MetricChange{Plugin: n.plugin, Name: n.Name, Labels: n.Labels, New: &n.Value})
+	}
+	return d
+}
+
+type keyedFinding struct {
+	models.Finding
+	plugin string
+}
+
+func indexFindings(results []models.Result) (map[string]keyedFinding, []string) {
+	index := make(map[string]keyedFinding)
+	var order []string
+	for _, result := range results {
+		for _, f := range result.Findings {
+			id := f.ID
+			if id == "" {
+				id = f.Title
+			}
+			base := result.Plugin + "/" + id
+			key := base
+			for n := 2; ; n++ {
+				if _, dup := index[key]; !dup {
+					break
+				}
+				key = fmt.Sprintf("%s#%d", base, n)
+			}
+			index[key] = keyedFinding{Finding: f, plugin: result.Plugin}
+			order = append(order, key)
+		}
+	}
+	return index, order
+}
+
+type keyedMetric struct {
+	models.Metric
+	plugin string
+}
+
+// indexMetrics 按 "插件/指标标识" 索引指标。插件的指标只涉及一个进程时（如默认以 ossre 自身为目标、
+// 或修复后重启了目标服务），pid 标签不参与配对，否则两次运行的进程号不同会使全部指标显示为消失与新增；
+// 涉及多个进程时（如全主机扫描）保留 pid 以区分各进程。
+func indexMetrics(results []models.Result) (map[string]keyedMetric, []string) {
+	index := make(map[string]keyedMetric)
+	var order []string
+	for _, result := range results {
+		pids := make(map[string]bool)
+		for _, m := range result.Metrics {
+			if pid, ok := m.Labels["pid"]; ok {
+				pids[pid] = true
+			}
+		}
+		for _, m := range result.Metrics {
+			labels := m.Labels
+			if _, ok := labels["pid"]; ok && len(pids) == 1 {
+				labels = make(map[string]string, len(m.Labels))
+				for k, v := range m.Labels {
+					if k != "pid" {
+						labels[k] = v
+					}
+				}
+			}
+			key := result.Plugin + "/" + MetricChange{Name: m.Name, Labels: labels}.Series()
+			if _, dup := index[key]; dup {
+				continue
+			}
+			index[key] = keyedMetric{Metric: m, plugin: result.Plugin}
+			order = append(order, key)
+		}
+	}
+	return index, order
+}
@@ -0,0 +1,127 @@
+package diff
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/supperghost/ossre/internal/report"
+)
+
+// Formats 返回 diff 支持的输出格式，名称与 report 包一致。
+func Formats() []string {
+	return []string{report.FormatPlain, report.FormatJSON, report.FormatMarkdown}
+}
+
+// section 为输出中的一组发现及其标题。
+type section struct {
+	title   string
+	changes []FindingChange
+}
+
+func (d *Diff) sections() []section {
+	return []section{
+		{"新增", d.New},
+		{"级别变化", d.Changed},
+		{"已解决", d.Resolved},
+		{"未变化", d.Unchanged},
+	}
+}
+
+// Write 以 format 格式输出对比结果，oldName 与 newName 为两份报告的来源说明（如文件路径）。
+func Write(w io.Writer, format, oldName, newName string, d *Diff) error {
+	switch format {
+	case report.FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Old string `json:"old"`
+			New string `json:"new"`
+			*Diff
+		}{oldName, newName, d})
+	case report.FormatPlain:
+		return writePlain(w, oldName, newName, d)
+	case report.FormatMarkdown:
+		return writeMarkdown(w, oldName, newName, d)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func writePlain(w io.Writer, oldName, newName string, d *Diff) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "=== 诊断结果对比 ===")
+	fmt.Fprintf(bw, "旧报告: %s\n新报告: %s\n", oldName, newName)
+	fmt.Fprintf(bw, "新增 %d，级别变化 %d，已解决 %d，未变化 %d\n",
+		len(d.New), len(d.Changed), len(d.Resolved), len(d.Unchanged))
+
+	for _, s := range d.sections() {
+		if len(s.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "\n%s (%d):\n", s.title, len(s.changes))
+		for _, c := range s.changes {
+			fmt.Fprintf(bw, "  - [%s] %s %s\n    %s\n", severityText(c), c.Plugin, c.ID, c.Title)
+		}
+	}
+	if len(d.Metrics) > 0 {
+		fmt.Fprintf(bw, "\n数值变化 (%d):\n", len(d.Metrics))
+		for _, m := range d.Metrics {
+			fmt.Fprintf(bw, "  - %s %s: %s → %s\n", m.Plugin, m.Series(), valueText(m.Old), valueText(m.New))
+		}
+	}
+	return bw.Flush()
+}
+
+func writeMarkdown(w io.Writer, oldName, newName string, d *Diff) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# ossre 诊断结果对比")
+	fmt.Fprintln(bw)
+	fmt.Fprintf(bw, "- 旧报告: `%s`\n- 新报告: `%s`\n\n", oldName, newName)
+	fmt.Fprintln(bw, "| 新增 | 级别变化 | 已解决 | 未变化 |")
+	fmt.Fprintln(bw, "| ---: | ---: | ---: | ---: |")
+	fmt.Fprintf(bw, "| %d | %d | %d | %d |\n", len(d.New), len(d.Changed), len(d.Resolved), len(d.Unchanged))
+
+	for _, s := range d.sections() {
+		if len(s.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "\n## %s (%d)\n\n", s.title, len(s.changes))
+		fmt.Fprintln(bw, "| 级别 | 模块 | ID | 标题 |")
+		fmt.Fprintln(bw, "| --- | --- | --- | --- |")
+		for _, c := range s.changes {
+			fmt.Fprintf(bw, "| %s | %s | `%s` | %s |\n", severityText(c), c.Plugin, c.ID, mdCell(c.Title))
+		}
+	}
+	if len(d.Metrics) > 0 {
+		fmt.Fprintf(bw, "\n## 数值变化 (%d)\n\n", len(d.Metrics))
+		fmt.Fprintln(bw, "| 模块 | 指标 | 旧值 | 新值 |")
+		fmt.Fprintln(bw, "| --- | --- | ---: | ---: |")
+		for _, m := range d.Metrics {
+			fmt.Fprintf(bw, "| %s | `%s` | %s | %s |\n", m.Plugin, m.Series(), valueText(m.Old), valueText(m.New))
+		}
+	}
+	return bw.Flush()
+}
+
+// severityText 返回变化前后的级别，级别变化时以箭头连接。
+func severityText(c FindingChange) string {
+	if c.Status == StatusChanged {
+		return string(c.OldSeverity) + " → " + string(c.NewSeverity)
+	}
+	return string(c.Severity())
+}
+
+func valueText(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+func mdCell(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", `\|`)
+}
@@ -0,0 +1,23 @@
+// Package builtin 导入随 ossre 发布的全部内置插件，使其在 init 中登记到 core 的全局注册表，
+// 供 CLI 与公开的 pkg/ossre 共用。新增内置插件时只需在此处增加一行导入。
+package builtin
+
+import (
+	"github.com/supperghost/ossre/internal/core"
+
+	_ "github.com/supperghost/ossre/internal/plugins/container"
+	_ "github.com/supperghost/ossre/internal/plugins/cpu"
+	_ "github.com/supperghost/ossre/internal/plugins/io"
+	_ "github.com/supperghost/ossre/internal/plugins/kernel"
+	_ "github.com/supperghost/ossre/internal/plugins/maxfd"
+	_ "github.com/supperghost/ossre/internal/plugins/maxproc"
+	_ "github.com/supperghost/ossre/internal/plugins/net"
+	_ "github.com/supperghost/ossre/internal/plugins/rules"
+	_ "github.com/supperghost/ossre/internal/plugins/scan"
+	_ "github.com/supperghost/ossre/internal/plugins/system"
+)
+
+// Plugins 返回全部内置插件的新实例，按名称排序。
+func Plugins() []core.Plugin {
+	return core.Registered()
+}
@@ -0,0 +1,63 @@
+package container
+
+import (
+	"context"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// PluginName 是容器诊断插件的名称常量。
+const PluginName = "container"
+
+// 容器插件包含的场景 ID。
+const (
+	limitsScenarioID = "container.limits"
+	sysctlScenarioID = "container.sysctl"
+)
+
+// Plugin 实现了 core.Plugin 接口，用于报告目标容器的有效资源限制与 namespace 内的内核参数。
+type Plugin struct{}
+
+// New 创建一个新的容器诊断插件实例。
+func New() core.Plugin {
+	return &Plugin{}
+}
+
+func init() {
+	core.Register(New, core.Metadata{
+		Version: "1.0.0",
+		Tags:    []string{"container", "capacity"},
+		OS:      []string{"linux"},
+		// setns 自 3.0 起提供
+		MinKernel: "3.0",
+		// 进入容器的网络与 IPC namespace 读取内核参数需要 CAP_SYS_ADMIN
+		Privileges: []string{"CAP_SYS_PTRACE", "CAP_SYS_ADMIN"},
+		Scenarios:  []string{limitsScenarioID, sysctlScenarioID},
+		CaseIDs: []string{
+			limitsScenarioID, limitsScenarioID + ".pids", limitsScenarioID + ".memory", limitsScenarioID + ".cpu",
+			sysctlScenarioID + ".*",
+		},
+	})
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Description() string {
+	return "容器有效资源限制与 namespace 内核参数诊断（配合 --container 使用，Linux 专属）"
+}
+
+// Run 调用平台相关的场景实现，返回诊断结果。
+func (p *Plugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	findings, suggestions, metrics, skipped := runContainerScenarios(ctx, rc)
+
+	return models.Result{
+		Plugin:      PluginName,
+		Findings:    findings,
+		Suggestions: suggestions,
+		Metrics:     metrics,
+		Skipped:     skipped,
+	}, nil
+}
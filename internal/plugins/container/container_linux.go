@@ -0,0 +1,368 @@
+//go:build linux
+// +build linux
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// usageWarnRatio 为用量占上限的告警阈值。
+const usageWarnRatio = 0.9
+
+// namespacedSysctl 为在容器的 namespace 中独立取值的内核参数。
+type namespacedSysctl struct {
+	Key string
+	// Capacity 表示参数为容量类上限，容器内的值低于宿主机时给出 warning。
+	Capacity    bool
+	Description string
+}
+
+// namespacedSysctls 为需要与宿主机对比的参数。新建的网络与 IPC namespace 中这些参数取内核默认值，
+// 不继承宿主机 sysctl.conf 中的调优，是“宿主机已调优但容器内仍丢连接”的常见原因。
+var namespacedSysctls = []namespacedSysctl{
+	{"net.core.somaxconn", true, "监听队列（listen backlog）的上限"},
+	{"net.ipv4.tcp_max_syn_backlog", true, "半连接队列的大小"},
+	{"net.ipv4.tcp_max_tw_buckets", true, "处于 TIME_WAIT 状态的连接数上限"},
+	{"net.ipv4.ip_local_port_range", false, "本地临时端口的范围"},
+	{"net.ipv4.tcp_syncookies", false, "是否在半连接队列溢出时启用 SYN Cookies"},
+	{"net.ipv4.tcp_tw_reuse", false, "是否允许重用 TIME_WAIT socket"},
+	{"net.ipv4.tcp_fin_timeout", false, "连接处于 FIN-WAIT2 状态的超时时间"},
+	{"net.ipv4.tcp_keepalive_time", false, "发送 TCP keepalive 探测前的空闲时间"},
+	{"kernel.shmmax", true, "单个共享内存段的最大字节数"},
+	{"kernel.shmall", true, "共享内存的总页数上限"},
+	{"kernel.msgmnb", true, "单个消息队列的最大字节数"},
+}
+
+// k8sSafeSysctls 为 Kubernetes 默认允许在 Pod securityContext 中设置的参数，其余参数需要 kubelet 的
+// --allowed-unsafe-sysctls 放行。
+var k8sSafeSysctls = map[string]bool{
+	"net.ipv4.ip_local_port_range": true,
+	"net.ipv4.tcp_syncookies":      true,
+	"net.ipv4.tcp_fin_timeout":     true,
+	"net.ipv4.tcp_keepalive_time":  true,
+}
+
+// runContainerScenarios 报告目标容器（或未指定容器时目标进程所在 cgroup）的有效资源限制，
+// 并对比容器 namespace 内与宿主机的内核参数。
+func runContainerScenarios(ctx context.Context, rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.Metric, []models.SkippedCheck) {
+	_ = ctx
+	c := rc.Collector
+	pid := rc.Target.PrimaryPID()
+	if pid == 0 {
+		pid = collectors.SelfPID(c)
+	}
+	label := targetLabel(rc.Target, pid)
+
+	if !c.ProcExists(pid) {
+		finding := models.Finding{
+			ID:          limitsScenarioID,
+			Title:       "无法评估容器资源限制：目标进程不存在或 /proc 不可访问",
+			Description: fmt.Sprintf("%s对应的 %s 不存在或不可访问，无法定位其 cgroup 与 namespace。", label, collectors.ProcDir(pid)),
+			Severity:    models.SeverityError,
+			Impact:      "无法读取容器的 pids、内存、CPU 限制与 namespace 内的内核参数。",
+		}
+		return []models.Finding{finding}, nil, nil, nil
+	}
+
+	var (
+		findings    []models.Finding
+		suggestions []models.Suggestion
+		metrics     []models.Metric
+		skipped     []models.SkippedCheck
+	)
+	if rc.ScenarioEnabled(limitsScenarioID) {
+		f, s, m, k := runLimitsScenario(rc, pid, label)
+		findings = append(findings, f...)
+		suggestions = append(suggestions, s...)
+		metrics = append(metrics, m...)
+		skipped = append(skipped, k...)
+	}
+	if rc.ScenarioEnabled(sysctlScenarioID) {
+		f, s, m, k := runSysctlScenario(rc, pid, label)
+		findings = append(findings, f...)
+		suggestions = append(suggestions, s...)
+		metrics = append(metrics, m...)
+		skipped = append(skipped, k...)
+	}
+	return findings, suggestions, metrics, skipped
+}
+
+// targetLabel 返回描述中使用的目标名称，以全角括号结尾以便直接接中文；容器 ID 截取为 docker ps 显示的 12 位。
+func targetLabel(target core.Target, pid int) string {
+	if id := target.ContainerID; id != "" {
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		return fmt.Sprintf("容器 %s（init 进程 PID=%d）", id, pid)
+	}
+	return fmt.Sprintf("目标进程（PID=%d）", pid)
+}
+
+// runLimitsScenario 实现“容器有效资源限制”场景：汇总 pids.max、memory.max、cpu.max 配额与 cpuset，
+// 用量接近上限或配额超过 cpuset 可用 CPU 数时给出 warning。
+func runLimitsScenario(rc *core.RunContext, pid int, label string) ([]models.Finding, []models.Suggestion, []models.Metric, []models.SkippedCheck) {
+	c := rc.Collector
+	pids := c.CgroupPids(pid)
+	mem := c.CgroupMemory(pid)
+	cpu := c.CgroupCPU(pid)
+	cpuset := c.CgroupCpuset(pid)
+
+	var (
+		findings    []models.Finding
+		suggestions []models.Suggestion
+		skipped     []models.SkippedCheck
+		lines       []string
+	)
+	labels := func(resource string) map[string]string {
+		return map[string]string{"pid": strconv.Itoa(pid), "container": rc.Target.ContainerID, "resource": resource}
+	}
+	var metrics []models.Metric
+	limitMetric := func(resource string, v float64) {
+		metrics = append(metrics, models.Metric{Name: "container_limit", Help: "容器的有效资源上限（无限制的资源不输出）", Labels: labels(resource), Value: v})
+	}
+	usageMetric := func(resource string, v float64) {
+		metrics = append(metrics, models.Metric{Name: "container_usage", Help: "容器的资源当前用量", Labels: labels(resource), Value: v})
+	}
+
+	if cg := rc.Target.CgroupPath; cg != "" {
+		lines = append(lines, "cgroup: "+cg)
+	}
+
+	// pids
+	switch {
+	case pids.Version == "none":
+		lines = append(lines, "pids.max: 未找到 pids 控制器")
+	case pids.Err != nil:
+		lines = append(lines, "pids.max: 未评估（数据不可读）")
+		skipped = append(skipped, rc.Skip(limitsScenarioID+".pids", pids.Dir, pids.Err))
+	case pids.Max == collectors.Unlimited:
+		lines = append(lines, fmt.Sprintf("pids.max: 无限制（当前 %d 个任务）", pids.Current))
+		usageMetric("pids", float64(pids.Current))
+	default:
+		lines = append(lines, fmt.Sprintf("pids.max: %d（当前 %d 个任务，%s）", pids.Max, pids.Current, percent(pids.Current, pids.Max)))
+		limitMetric("pids", float64(pids.Max))
+		usageMetric("pids", float64(pids.Current))
+		if pids.Max > 0 && float64(pids.Current) >= usageWarnRatio*float64(pids.Max) {
+			id := limitsScenarioID + ".pids"
+			findings = append(findings, models.Finding{
+				ID:          id,
+				Title:       "容器任务数接近 pids.max",
+				Description: fmt.Sprintf("%s所在 cgroup 当前有 %d 个任务，pids.max 为 %d（%s）。达到上限后容器内 fork 与创建线程将失败（EAGAIN）。", label, pids.Current, pids.Max, percent(pids.Current, pids.Max)),
+				Severity:    models.SeverityWarning,
+				Impact:      "容器内进程无法再创建线程或子进程，可能表现为 resource temporarily unavailable 或 JVM 无法创建本地线程。",
+			})
+			suggestions = append(suggestions, models.Suggestion{
+				FindingID: id,
+				Title:     "提高容器的 pids 限制或排查线程泄漏",
+				Details: "1. 使用 maxproc 模块确认线程数是否持续增长（--sample-window）。\n" +
+					"2. Docker：docker update --pids-limit=<n> <容器> 或在 docker run 时指定 --pids-limit。\n" +
+					"3. Kubernetes：pids 限制由 kubelet 的 podPidsLimit 配置，按节点调整。",
+			})
+		}
+	}
+
+	// memory
+	switch {
+	case mem.Version == "none":
+		lines = append(lines, "memory.max: 未找到 memory 控制器")
+	case mem.Err != nil:
+		lines = append(lines, "memory.max: 未评估（数据不可读）")
+		skipped = append(skipped, rc.Skip(limitsScenarioID+".memory", mem.Dir, mem.Err))
+	case mem.Limit == collectors.Unlimited:
+		lines = append(lines, fmt.Sprintf("memory.max: 无限制（当前用量 %s）", formatBytes(mem.Usage)))
+		usageMetric("memory_bytes", float64(mem.Usage))
+	default:
+		lines = append(lines, fmt.Sprintf("memory.max: %s（当前用量 %s，%s）", formatBytes(mem.Limit), formatBytes(mem.Usage), percent(mem.Usage, mem.Limit)))
+		limitMetric("memory_bytes", float64(mem.Limit))
+		usageMetric("memory_bytes", float64(mem.Usage))
+		if mem.Limit > 0 && float64(mem.Usage) >= usageWarnRatio*float64(mem.Limit) {
+			id := limitsScenarioID + ".memory"
+			findings = append(findings, models.Finding{
+				ID:    id,
+				Title: "容器内存用量接近 memory.max",
+				Description: fmt.Sprintf("%s所在 cgroup 当前内存用量 %s，上限 %s（%s）。用量包含可回收的页缓存，"+
+					"但回收不及时时将触发 cgroup 内的 OOM killer。", label, formatBytes(mem.Usage), formatBytes(mem.Limit), percent(mem.Usage, mem.Limit)),
+				Severity: models.SeverityWarning,
+				Impact:   "容器内进程可能被 OOM killer 终止，或因频繁回收页缓存导致延迟升高。",
+			})
+			suggestions = append(suggestions, models.Suggestion{
+				FindingID: id,
+				Title:     "核实容器内存上限是否合理",
+				Details: fmt.Sprintf("1. 查看 %s 中的 anon 与 file，区分进程内存与页缓存；memory.events 中的 oom_kill 表示已发生 OOM。\n"+
+					"2. Docker：docker update --memory=<size> <容器>。\n"+
+					"3. Kubernetes：调整容器的 resources.limits.memory。", path.Join(mem.Dir, "memory.stat")),
+			})
+		}
+	}
+
+	// cpu.max 与 cpuset
+	quota := cpu.CPUs()
+	switch {
+	case cpu.Version == "none":
+		lines = append(lines, "cpu.max: 未找到 cpu 控制器")
+	case cpu.Err != nil:
+		lines = append(lines, "cpu.max: 未评估（数据不可读）")
+		skipped = append(skipped, rc.Skip(limitsScenarioID+".cpu", cpu.Dir, cpu.Err))
+	case quota == 0:
+		lines = append(lines, "cpu.max: 无限制")
+	default:
+		lines = append(lines, fmt.Sprintf("cpu.max: %.2f 个 CPU（每 %d 微秒周期内 %d 微秒）", quota, cpu.Period, cpu.Quota))
+		limitMetric("cpu_quota_cores", quota)
+	}
+	switch {
+	case cpuset.Version == "none":
+		lines = append(lines, "cpuset: 未找到 cpuset 控制器")
+	case cpuset.Err != nil:
+		lines = append(lines, "cpuset: 未评估（数据不可读）")
+		skipped = append(skipped, rc.Skip(limitsScenarioID+".cpu", cpuset.Dir, cpuset.Err))
+	default:
+		lines = append(lines, fmt.Sprintf("cpuset: %s（%d 个 CPU）", cpuset.CPUs, cpuset.Count))
+		limitMetric("cpuset_cpus", float64(cpuset.Count))
+	}
+	if effective := c.EffectiveCPUs(pid); effective > 0 {
+		lines = append(lines, fmt.Sprintf("有效 CPU 数: %.2f", effective))
+	}
+	if quota > 0 && cpuset.Count > 0 && quota > float64(cpuset.Count) {
+		id := limitsScenarioID + ".cpu"
+		findings = append(findings, models.Finding{
+			ID:    id,
+			Title: "CPU 配额超过 cpuset 可用的 CPU 数",
+			Description: fmt.Sprintf("%s的 cpu.max 配额折合 %.2f 个 CPU，但 cpuset 只允许在 %d 个 CPU（%s）上运行，"+
+				"超出部分的配额永远无法使用，有效 CPU 数为 %d。", label, quota, cpuset.Count, cpuset.CPUs, cpuset.Count),
+			Severity: models.SeverityWarning,
+			Impact:   "按配额估算的容量偏高；按配额设置线程池或 GOMAXPROCS 时会超过实际可用的 CPU 数。",
+		})
+		suggestions = append(suggestions, models.Suggestion{
+			FindingID: id,
+			Title:     "使 CPU 配额与 cpuset 保持一致",
+			Details: "1. Docker：--cpus 不应超过 --cpuset-cpus 中的 CPU 数。\n" +
+				"2. Kubernetes：启用 static CPU 管理策略时，Guaranteed Pod 的整数 CPU limits 会独占相应数量的 CPU，核实 limits 与节点 reservedSystemCPUs 等配置。",
+		})
+	}
+
+	title := "容器有效资源限制"
+	if rc.Target.ContainerID == "" {
+		title = "进程所在 cgroup 的有效资源限制"
+	}
+	summary := models.Finding{
+		ID:          limitsScenarioID,
+		Title:       title,
+		Description: fmt.Sprintf("%s的有效资源限制如下：\n%s", label, strings.Join(lines, "\n")),
+		Severity:    models.SeverityInfo,
+		Impact:      "容器内进程能使用的任务数、内存与 CPU 以这些限制为准，而不是宿主机的总量。",
+	}
+	return append([]models.Finding{summary}, findings...), suggestions, metrics, skipped
+}
+
+// runSysctlScenario 实现“容器 namespace 内核参数”场景：逐项读取容器网络与 IPC namespace 中的参数，
+// 与宿主机的值不同时给出发现，容量类参数低于宿主机时为 warning。
+func runSysctlScenario(rc *core.RunContext, pid int, label string) ([]models.Finding, []models.Suggestion, []models.Metric, []models.SkippedCheck) {
+	var (
+		c           = rc.Collector
+		findings    []models.Finding
+		suggestions []models.Suggestion
+		metrics     []models.Metric
+		skipped     []models.SkippedCheck
+	)
+	for _, item := range namespacedSysctls {
+		id := sysctlScenarioID + "." + strings.ReplaceAll(item.Key, ".", "_")
+		host, err := c.Sysctl(item.Key)
+		if err != nil {
+			// 当前内核没有该参数，宿主机上的读取问题由 kernel 模块报告
+			continue
+		}
+		inner, err := c.NamespacedSysctl(pid, item.Key)
+		if err != nil {
+			skipped = append(skipped, rc.Skip(id, collectors.NamespacedSysctlPath(pid, item.Key), err, "CAP_SYS_ADMIN"))
+			continue
+		}
+		innerV, innerNum := parseNumber(inner)
+		hostV, hostNum := parseNumber(host)
+		if innerNum {
+			metrics = append(metrics, models.Metric{
+				Name:   "container_sysctl_value",
+				Help:   "容器 namespace 内内核参数的当前值（仅单个数值的参数）",
+				Labels: map[string]string{"pid": strconv.Itoa(pid), "container": rc.Target.ContainerID, "key": item.Key},
+				Value:  innerV,
+			})
+		}
+		// ip_local_port_range 等多个数值的参数以制表符分隔，统一为单个空格后比较与展示
+		inner, host = strings.Join(strings.Fields(inner), " "), strings.Join(strings.Fields(host), " ")
+		if inner == host {
+			continue
+		}
+
+		severity := models.SeverityInfo
+		if item.Capacity && innerNum && hostNum && innerV < hostV {
+			severity = models.SeverityWarning
+		}
+		ns := "网络"
+		if strings.HasPrefix(item.Key, "kernel.") {
+			ns = "IPC"
+		}
+		findings = append(findings, models.Finding{
+			ID:    id,
+			Title: fmt.Sprintf("容器内的内核参数 %s 与宿主机不同", item.Key),
+			Description: fmt.Sprintf("%s中 %s 为 %q，宿主机为 %q。该参数控制%s，按%s namespace 隔离，"+
+				"容器不继承宿主机 sysctl.conf 中的设置，新建 namespace 时取内核默认值。", label, item.Key, inner, host, item.Description, ns),
+			Severity: severity,
+			Impact:   "宿主机上的调优在容器内不生效，高并发时容器内仍可能出现队列溢出、端口耗尽等问题。",
+		})
+		setting := item.Key + "=" + host
+		if strings.Contains(host, " ") {
+			setting = fmt.Sprintf("%s=%q", item.Key, host)
+		}
+		details := fmt.Sprintf("1. Docker：docker run --sysctl %s。\n2. Kubernetes：在 Pod 的 securityContext.sysctls 中设置 name: %s、value: %q",
+			setting, item.Key, host)
+		if k8sSafeSysctls[item.Key] {
+			details += "（该参数默认允许设置）。"
+		} else {
+			details += fmt.Sprintf("，该参数需要 kubelet 以 --allowed-unsafe-sysctls=%s 放行。", item.Key)
+		}
+		suggestions = append(suggestions, models.Suggestion{
+			FindingID: id,
+			Title:     fmt.Sprintf("在容器运行时中为容器设置 %s", item.Key),
+			Details:   details + "\n在宿主机上执行 sysctl -w 不会影响已有容器的 namespace。",
+		})
+	}
+	return findings, suggestions, metrics, skipped
+}
+
+// parseNumber 解析单个数值的参数值。
+func parseNumber(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return v, err == nil
+}
+
+// percent 返回 used 占 limit 的百分比文本。
+func percent(used, limit int64) string {
+	if limit <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", float64(used)*100/float64(limit))
+}
+
+// formatBytes 将字节数格式化为 KiB/MiB/GiB。
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	v, suffix := float64(n)/unit, "KiB"
+	for _, s := range []string{"MiB", "GiB", "TiB"} {
+		if v < unit {
+			break
+		}
+		v, suffix = v/unit, s
+	}
+	return fmt.Sprintf("%.1f %s", v, suffix)
+}
@@ -0,0 +1,27 @@
+//go:build !linux
+// +build !linux
+
+package container
+
+import (
+	"context"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// runContainerScenarios 在非 Linux 平台上提供降级实现。
+// 该模块依赖 Linux 的 cgroup 与 namespace，这里仅返回一条信息级别的 Finding，说明场景不适用。
+func runContainerScenarios(ctx context.Context, rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.Metric, []models.SkippedCheck) {
+	_, _ = ctx, rc
+
+	finding := models.Finding{
+		ID:          limitsScenarioID,
+		Title:       "容器资源限制场景当前操作系统不支持",
+		Description: "container 模块依赖 Linux 的 cgroup 与 namespace 接口，仅在 Linux 上可用；当前操作系统不支持，无法读取容器的有效资源限制。",
+		Severity:    models.SeverityInfo,
+		Impact:      "仅影响 container 模块的容器资源限制诊断，其他插件与场景不受影响。",
+	}
+
+	return []models.Finding{finding}, nil, nil, nil
+}
@@ -0,0 +1,60 @@
+package cpu
+
+import (
+	"context"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// PluginName 是 CPU 诊断插件的名称常量。
+const PluginName = "cpu"
+
+// throttleScenarioID 为 CFS 限流场景的 ID。
+const throttleScenarioID = "cpu.throttle"
+
+// Plugin 实现了 core.Plugin 接口，用于诊断 cgroup CPU 配额导致的 CFS 限流。
+type Plugin struct{}
+
+// New 创建一个新的 CPU 诊断插件实例。
+func New() core.Plugin {
+	return &Plugin{}
+}
+
+func init() {
+	core.Register(New, core.Metadata{
+		Version: "1.0.0",
+		Tags:    []string{"cpu", "latency", "process", "container"},
+		OS:      []string{"linux"},
+		// cpu.stat 中的 CFS 带宽统计自 3.2 起提供
+		MinKernel: "3.2",
+		// 读取其他用户进程的环境变量需要 CAP_SYS_PTRACE
+		Privileges: []string{"CAP_SYS_PTRACE"},
+		Scenarios:  []string{throttleScenarioID},
+		CaseIDs: []string{
+			throttleScenarioID, throttleScenarioID + ".runnable",
+			throttleScenarioID + ".gomaxprocs", throttleScenarioID + ".jvm",
+		},
+	})
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Description() string {
+	return "cgroup CPU 配额与 CFS 限流诊断（Linux 专属，其他平台降级提示）"
+}
+
+// Run 调用平台相关的场景实现，返回诊断结果。
+func (p *Plugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	findings, suggestions, metrics, skipped := runThrottleScenario(ctx, rc)
+
+	return models.Result{
+		Plugin:      PluginName,
+		Findings:    findings,
+		Suggestions: suggestions,
+		Metrics:     metrics,
+		Skipped:     skipped,
+	}, nil
+}
@@ -0,0 +1,400 @@
+//go:build linux
+// +build linux
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+const (
+	// 被限流的周期占有可运行任务的周期的比例超过这些阈值时分别给出 warning 与 error。
+	throttleWarnRatio  = 0.05
+	throttleErrorRatio = 0.25
+
+	defaultSampleInterval = 5 * time.Second
+)
+
+// runThrottleScenario 实现“CFS 限流”场景：读取目标进程所在 cgroup 的 CPU 配额与 cpu.stat，
+// 计算被限流周期的比例；指定采样窗口时按窗口内的增量计算，否则按 cgroup 创建以来的累计值计算。
+// 同时对比可运行线程数、GOMAXPROCS 与 JVM 可见的处理器数是否超过有效 CPU 数。
+func runThrottleScenario(ctx context.Context, rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.Metric, []models.SkippedCheck) {
+	c := rc.Collector
+	pid := rc.Target.PrimaryPID()
+	if pid == 0 {
+		pid = collectors.SelfPID(c)
+	}
+	if !c.ProcExists(pid) {
+		finding := models.Finding{
+			ID:          throttleScenarioID,
+			Title:       "无法评估 CFS 限流：目标进程不存在或 /proc 不可访问",
+			Description: fmt.Sprintf("目标 PID=%d 对应的 %s 不存在或不可访问，无法定位其 cpu cgroup。", pid, collectors.ProcDir(pid)),
+			Severity:    models.SeverityError,
+			Impact:      "无法评估 CPU 配额是否导致请求延迟升高。",
+		}
+		return []models.Finding{finding}, nil, nil, nil
+	}
+
+	e := &evaluation{rc: rc, pid: pid, effective: c.EffectiveCPUs(pid)}
+	if e.effective > 0 {
+		e.metric("cpu_effective_cpus", "目标进程实际可用的 CPU 数（配额、cpuset 与在线 CPU 数中的最小值）", nil, e.effective)
+	}
+
+	// 指定采样窗口时在窗口内同时采集 cpu.stat 与可运行线程数
+	var samples *collectors.Samples
+	if sampling := rc.Config.Sampling; sampling.Window > 0 {
+		interval := sampling.Interval
+		if interval <= 0 {
+			interval = defaultSampleInterval
+		}
+		samples, _ = c.Sample(ctx, interval, sampling.Window, collectors.CgroupCPUStatCounters(pid), runnableSource(pid))
+	}
+
+	e.evaluateThrottle(samples)
+	e.evaluateRunnable(samples)
+	e.evaluateRuntimes()
+	return e.findings, e.suggestions, e.metrics, e.skipped
+}
+
+// evaluation 汇总一次场景评估的输出。
+type evaluation struct {
+	rc        *core.RunContext
+	pid       int
+	effective float64
+
+	findings    []models.Finding
+	suggestions []models.Suggestion
+	metrics     []models.Metric
+	skipped     []models.SkippedCheck
+}
+
+func (e *evaluation) metric(name, help string, labels map[string]string, v float64) {
+	l := map[string]string{"pid": strconv.Itoa(e.pid)}
+	for k, val := range labels {
+		l[k] = val
+	}
+	e.metrics = append(e.metrics, models.Metric{Name: name, Help: help, Labels: l, Value: v})
+}
+
+// limit 返回按有效 CPU 数向上取整得到的并行度上限，有效 CPU 数未知时返回 0。
+func (e *evaluation) limit() int {
+	return int(math.Ceil(e.effective))
+}
+
+// evaluateThrottle 计算被限流周期的比例，并输出 cpu.throttle 发现。
+func (e *evaluation) evaluateThrottle(samples *collectors.Samples) {
+	c := e.rc.Collector
+	quota := c.CgroupCPU(e.pid)
+	stat := c.CgroupCPUStat(e.pid)
+	switch {
+	case quota.Version == "none" || stat.Version == "none":
+		e.findings = append(e.findings, models.Finding{
+			ID:          throttleScenarioID,
+			Title:       "未找到 cpu 控制器，不存在 CFS 限流",
+			Description: fmt.Sprintf("目标进程 PID=%d 所在 cgroup 中没有 cpu.max/cpu.cfs_quota_us 或 cpu.stat，进程不受 CPU 配额限制。", e.pid),
+			Severity:    models.SeverityInfo,
+			Impact:      "CPU 争用只来自宿主机上的其他负载，不会因配额用尽而暂停运行。",
+		})
+		return
+	case quota.Err != nil:
+		e.skipped = append(e.skipped, e.rc.Skip(throttleScenarioID, quota.Dir, quota.Err))
+		return
+	case stat.Err != nil:
+		e.skipped = append(e.skipped, e.rc.Skip(throttleScenarioID, path.Join(stat.Dir, "cpu.stat"), stat.Err))
+		return
+	case quota.CPUs() == 0:
+		e.findings = append(e.findings, models.Finding{
+			ID:    throttleScenarioID,
+			Title: "未设置 CPU 配额，不存在 CFS 限流",
+			Description: fmt.Sprintf("目标进程 PID=%d 所在 cgroup（%s）的 CPU 配额为无限制。父 cgroup（如 Kubernetes 的 Pod 层级）"+
+				"设置了配额时仍可能被限流，其统计记录在父 cgroup 的 cpu.stat 中。", e.pid, quota.Dir),
+			Severity: models.SeverityInfo,
+			Impact:   "该 cgroup 自身不会因配额用尽而暂停运行。",
+		})
+		return
+	}
+
+	quotaCPUs := quota.CPUs()
+	limit := e.limit()
+	if limit <= 0 {
+		limit = int(math.Ceil(quotaCPUs))
+	}
+	e.metric("cpu_quota_cores", "cgroup CPU 配额折合的 CPU 数", nil, quotaCPUs)
+	e.metric("cpu_throttled_periods", "cgroup 创建以来被限流的调度周期数", nil, float64(stat.NrThrottled))
+	e.metric("cpu_throttled_seconds", "cgroup 创建以来被限流的累计时间（秒）", nil, float64(stat.ThrottledUsec)/1e6)
+
+	lines := []string{
+		fmt.Sprintf("CPU 配额: %.2f 个 CPU（每 %d 微秒周期内 %d 微秒，%s）", quotaCPUs, quota.Period, quota.Quota, quota.Dir),
+		fmt.Sprintf("累计: %d 个有可运行任务的周期中 %d 个被限流（%s），被限流时间共 %.1f 秒",
+			stat.NrPeriods, stat.NrThrottled, ratioText(stat.NrThrottled, stat.NrPeriods), float64(stat.ThrottledUsec)/1e6),
+	}
+	if e.effective > 0 {
+		lines = append(lines, fmt.Sprintf("有效 CPU 数: %.2f", e.effective))
+	}
+
+	ratio, hasRatio := throttleRatio(stat.NrThrottled, stat.NrPeriods)
+	scope := "lifetime"
+	if samples != nil && samples.Len() >= 2 {
+		periods, okP := samples.Delta("cpu/nr_periods")
+		throttled, okT := samples.Delta("cpu/nr_throttled")
+		if okP && okT {
+			scope = "window"
+			ratio, hasRatio = throttleRatio(throttled, periods)
+			line := fmt.Sprintf("最近 %s: %d 个有可运行任务的周期中 %d 个被限流（%s）",
+				samples.Elapsed().Round(time.Second), periods, throttled, ratioText(throttled, periods))
+			if r := samples.Rate("cpu/throttled_usec"); r.Count > 0 {
+				line += fmt.Sprintf("，平均每秒被限流 %.3f 秒", r.Mean/1e6)
+			}
+			if r := samples.Rate("cpu/usage_usec"); r.Count > 0 {
+				line += fmt.Sprintf("，平均使用 %.2f 个 CPU（峰值区间 %.2f）", r.Mean/1e6, r.Max/1e6)
+			}
+			lines = append(lines, line)
+		}
+	}
+	if hasRatio {
+		e.metric("cpu_throttle_ratio", "被限流的周期占有可运行任务的周期的比例，scope 为 lifetime（累计）或 window（采样窗口）",
+			map[string]string{"scope": scope}, ratio)
+	}
+
+	severity := models.SeverityInfo
+	switch {
+	case !hasRatio:
+	case ratio >= throttleErrorRatio && scope == "window":
+		severity = models.SeverityError
+	case ratio >= throttleWarnRatio:
+		// 累计值包含历史上的突发，最高只给出 warning
+		severity = models.SeverityWarning
+	}
+	title := "CFS 限流比例评估"
+	if hasRatio {
+		title = fmt.Sprintf("CFS 限流比例为 %.1f%%", ratio*100)
+		if scope == "lifetime" {
+			title += "（cgroup 创建以来）"
+		}
+	}
+	desc := fmt.Sprintf("目标进程 PID=%d 所在 cgroup 的 CPU 配额与限流统计如下：\n%s", e.pid, strings.Join(lines, "\n"))
+	if scope == "lifetime" {
+		desc += "\n未指定 --sample-window，比例按 cgroup 创建以来的累计值计算，可能反映的是历史上的突发；指定采样窗口可评估当前的限流情况。"
+	}
+	e.findings = append(e.findings, models.Finding{
+		ID:          throttleScenarioID,
+		Title:       title,
+		Description: desc,
+		Severity:    severity,
+		Impact:      "被限流的周期内 cgroup 中的所有线程暂停运行直到下一个周期，即使平均 CPU 使用率不高，突发的并行计算也会使请求延迟增加数十毫秒。",
+	})
+	if severity != models.SeverityInfo {
+		e.suggestions = append(e.suggestions, models.Suggestion{
+			FindingID: throttleScenarioID,
+			Title:     "提高 CPU 配额或降低突发并行度",
+			Details: fmt.Sprintf("1. 提高配额：Docker 使用 docker update --cpus=<n> <容器>；Kubernetes 调整 resources.limits.cpu，"+
+				"或对延迟敏感的服务只设置 requests 不设置 limits。\n"+
+				"2. 降低并行度：使 GOMAXPROCS、JVM 处理器数与线程池大小不超过有效 CPU 数（当前为 %d），避免多个线程在一个周期内同时耗尽配额。\n"+
+				"3. 内核 5.14 及以上可以设置 %s 允许短时突发使用累积的配额。",
+				limit, burstFile(quota)),
+		})
+	}
+}
+
+// burstFile 返回突发配额的配置文件。
+func burstFile(q collectors.CgroupCPU) string {
+	if q.Version == "v1" {
+		return path.Join(q.Dir, "cpu.cfs_burst_us")
+	}
+	return path.Join(q.Dir, "cpu.max.burst")
+}
+
+// throttleRatio 返回被限流周期的比例，没有可运行任务的周期时 ok 为 false。
+func throttleRatio(throttled, periods uint64) (float64, bool) {
+	if periods == 0 {
+		return 0, false
+	}
+	return float64(throttled) / float64(periods), true
+}
+
+func ratioText(throttled, periods uint64) string {
+	if r, ok := throttleRatio(throttled, periods); ok {
+		return fmt.Sprintf("%.1f%%", r*100)
+	}
+	return "-"
+}
+
+// runnableSource 将 cgroup 中处于运行态（R）的线程数作为瞬时值 "threads/runnable"。
+func runnableSource(pid int) collectors.CounterSource {
+	return func(c *collectors.Collector) (collectors.Counters, error) {
+		n, err := runnableThreads(c, pid)
+		if err != nil {
+			return nil, err
+		}
+		return collectors.Counters{"threads/runnable": uint64(n)}, nil
+	}
+}
+
+// runnableThreads 统计 cgroup 中处于运行态的线程数；无法读取 cgroup 的线程列表时只统计目标进程的线程。
+func runnableThreads(c *collectors.Collector, pid int) (int, error) {
+	tids, err := c.CgroupThreads(pid)
+	if err != nil {
+		names, err := c.ReadDirNames(path.Join(collectors.ProcDir(pid), "task"))
+		if err != nil {
+			return 0, err
+		}
+		tids = tids[:0]
+		for _, name := range names {
+			if tid, err := strconv.Atoi(name); err == nil {
+				tids = append(tids, tid)
+			}
+		}
+	}
+	var n int
+	for _, tid := range tids {
+		if st, err := c.ProcStat(tid); err == nil && st.State == "R" {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// evaluateRunnable 对比可运行线程数与有效 CPU 数，采样时取窗口内的平均值。
+func (e *evaluation) evaluateRunnable(samples *collectors.Samples) {
+	if e.effective <= 0 {
+		return
+	}
+	var runnable float64
+	basis := "当前"
+	if g := samplesGauge(samples, "threads/runnable"); g.Count > 0 {
+		runnable, basis = g.Mean, fmt.Sprintf("采样窗口内平均（峰值 %.0f）", g.Max)
+	} else {
+		n, err := runnableThreads(e.rc.Collector, e.pid)
+		if err != nil {
+			return
+		}
+		runnable = float64(n)
+	}
+	e.metric("cpu_runnable_threads", "cgroup 中处于运行态（R）的线程数", nil, runnable)
+	// 与按有效 CPU 数向上取整的并行度比较，避免配额不足 1 个 CPU 时单个运行线程也被报告
+	if runnable <= float64(e.limit()) {
+		return
+	}
+	id := throttleScenarioID + ".runnable"
+	e.findings = append(e.findings, models.Finding{
+		ID:    id,
+		Title: "可运行线程数超过有效 CPU 数",
+		Description: fmt.Sprintf("目标进程 PID=%d 所在 cgroup 中%s有 %.1f 个线程处于运行态，有效 CPU 数为 %.2f。"+
+			"超出的线程需要排队等待 CPU，配额受限时还会更快耗尽每个周期的配额。", e.pid, basis, runnable, e.effective),
+		Severity: models.SeverityWarning,
+		Impact:   "线程在运行队列中等待，表现为请求延迟与尾延迟升高。",
+	})
+	e.suggestions = append(e.suggestions, models.Suggestion{
+		FindingID: id,
+		Title:     "按有效 CPU 数设置工作线程数",
+		Details:   fmt.Sprintf("将计算密集型线程池的大小设置为不超过 %d，或提高 CPU 配额与 cpuset。", e.limit()),
+	})
+}
+
+func samplesGauge(samples *collectors.Samples, key string) collectors.Stats {
+	if samples == nil {
+		return collectors.Stats{}
+	}
+	return samples.Gauge(key)
+}
+
+// evaluateRuntimes 从进程的环境变量与命令行推断 Go 与 JVM 运行时使用的处理器数，
+// 超过有效 CPU 数时给出 warning；环境变量不可读时 GOMAXPROCS 检查记为未评估。
+func (e *evaluation) evaluateRuntimes() {
+	limit := e.limit()
+	if limit <= 0 {
+		return
+	}
+	c := e.rc.Collector
+	env, envErr := c.Environ(e.pid)
+	if envErr != nil && collectors.IsPermission(envErr) {
+		e.skipped = append(e.skipped, e.rc.Skip(throttleScenarioID+".gomaxprocs",
+			path.Join(collectors.ProcDir(e.pid), "environ"), envErr, "CAP_SYS_PTRACE"))
+	}
+
+	if v, ok := env["GOMAXPROCS"]; ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n > limit {
+			id := throttleScenarioID + ".gomaxprocs"
+			e.findings = append(e.findings, models.Finding{
+				ID:    id,
+				Title: "GOMAXPROCS 超过有效 CPU 数",
+				Description: fmt.Sprintf("目标进程 PID=%d 的环境变量 GOMAXPROCS=%d，有效 CPU 数为 %.2f。Go 运行时会同时运行 %d 个线程执行 goroutine，"+
+					"在一个调度周期内以 %d 倍的速度消耗配额。", e.pid, n, e.effective, n, int(math.Ceil(float64(n)/e.effective))),
+				Severity: models.SeverityWarning,
+				Impact:   "GC 与并行计算在周期开始时迅速耗尽配额，随后整个进程暂停到下一个周期，尾延迟显著升高。",
+			})
+			e.suggestions = append(e.suggestions, models.Suggestion{
+				FindingID: id,
+				Title:     fmt.Sprintf("将 GOMAXPROCS 设置为 %d", limit),
+				Details: fmt.Sprintf("设置环境变量 GOMAXPROCS=%d，或去掉该变量：Go 1.25 起运行时默认按 cgroup CPU 配额设置 GOMAXPROCS，"+
+					"更早的版本可使用 go.uber.org/automaxprocs。", limit),
+			})
+		}
+	}
+
+	cmdline, _ := c.Cmdline(e.pid)
+	if len(cmdline) == 0 || path.Base(cmdline[0]) != "java" {
+		return
+	}
+	opts := append([]string(nil), cmdline[1:]...)
+	for _, name := range collectors.JavaOptionEnvs {
+		opts = append(opts, strings.Fields(env[name])...)
+	}
+	var problems []string
+	for _, opt := range opts {
+		switch {
+		case strings.HasPrefix(opt, "-XX:ActiveProcessorCount="):
+			if n, err := strconv.Atoi(strings.TrimPrefix(opt, "-XX:ActiveProcessorCount=")); err == nil && n > limit {
+				problems = append(problems, fmt.Sprintf("%s：JVM 按 %d 个处理器设置 GC、JIT 线程与 ForkJoinPool 的并行度", opt, n))
+			}
+		case strings.HasPrefix(opt, "-XX:ParallelGCThreads="):
+			if n, err := strconv.Atoi(strings.TrimPrefix(opt, "-XX:ParallelGCThreads=")); err == nil && n > limit {
+				problems = append(problems, fmt.Sprintf("%s：并行 GC 线程数超过有效 CPU 数", opt))
+			}
+		case opt == "-XX:-UseContainerSupport":
+			if n := e.visibleCPUs(); n > limit {
+				problems = append(problems, fmt.Sprintf("%s：JVM 不读取 cgroup 配额，按 CPU 亲和性看到 %d 个处理器", opt, n))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return
+	}
+	id := throttleScenarioID + ".jvm"
+	e.findings = append(e.findings, models.Finding{
+		ID:    id,
+		Title: "JVM 可见的处理器数超过有效 CPU 数",
+		Description: fmt.Sprintf("目标进程 PID=%d 为 JVM，有效 CPU 数为 %.2f，以下选项（来自命令行或 %s）使其按更多的处理器运行：\n%s",
+			e.pid, e.effective, strings.Join(collectors.JavaOptionEnvs, "、"), strings.Join(problems, "\n")),
+		Severity: models.SeverityWarning,
+		Impact:   "GC 暂停期间大量线程同时消耗配额，容易触发 CFS 限流，使 GC 停顿与请求延迟成倍增加。",
+	})
+	e.suggestions = append(e.suggestions, models.Suggestion{
+		FindingID: id,
+		Title:     "使 JVM 处理器数与 CPU 配额一致",
+		Details: fmt.Sprintf("去掉 -XX:-UseContainerSupport（JDK 10 及 8u191 起默认开启），将 -XX:ActiveProcessorCount 设置为不超过 %d 或去掉该选项，"+
+			"ParallelGCThreads 同样不应超过 %d。", limit, limit),
+	})
+}
+
+// visibleCPUs 返回不感知 cgroup 配额的程序看到的 CPU 数：cpuset 中的 CPU 数，未知时为在线 CPU 数。
+func (e *evaluation) visibleCPUs() int {
+	c := e.rc.Collector
+	if s := c.CgroupCpuset(e.pid); s.Err == nil && s.Count > 0 {
+		return s.Count
+	}
+	if st, err := c.SystemStat(); err == nil {
+		return len(st.PerCPU)
+	}
+	return 0
+}
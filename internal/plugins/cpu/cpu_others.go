@@ -0,0 +1,27 @@
+//go:build !linux
+// +build !linux
+
+package cpu
+
+import (
+	"context"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// runThrottleScenario 在非 Linux 平台上提供降级实现。
+// 该模块依赖 Linux 的 cgroup cpu 控制器，这里仅返回一条信息级别的 Finding，说明场景不适用。
+func runThrottleScenario(ctx context.Context, rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.Metric, []models.SkippedCheck) {
+	_, _ = ctx, rc
+
+	finding := models.Finding{
+		ID:          throttleScenarioID,
+		Title:       "CFS 限流场景当前操作系统不支持",
+		Description: "cpu 模块依赖 Linux 的 cgroup cpu 控制器（cpu.max、cpu.stat），仅在 Linux 上可用；当前操作系统不支持，无法评估 CPU 配额与限流比例。",
+		Severity:    models.SeverityInfo,
+		Impact:      "仅影响 cpu 模块的 CFS 限流诊断，其他插件与场景不受影响。",
+	}
+
+	return []models.Finding{finding}, nil, nil, nil
+}
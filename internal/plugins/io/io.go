@@ -18,6 +18,13 @@ func New() core.Plugin {
 	return &Plugin{}
 }
 
+func init() {
+	core.Register(New, core.Metadata{
+		Version: "0.1.0",
+		Tags:    []string{"storage"},
+	})
+}
+
 func (p *Plugin) Name() string {
 	return PluginName
 }
@@ -26,7 +33,7 @@ func (p *Plugin) Description() string {
 	return "磁盘与文件系统 I/O 诊断（占位实现）"
 }
 
-func (p *Plugin) Run(ctx context.Context) (models.Result, error) {
+func (p *Plugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
 	_ = ctx
 	// TODO: 实现对磁盘、文件系统和 I/O 延迟的采集与诊断。
 	return models.Result{
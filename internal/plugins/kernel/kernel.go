@@ -1,14 +1,12 @@
 package kernel
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
-	"syscall"
 
+	"github.com/supperghost/ossre/internal/collectors"
 	"github.com/supperghost/ossre/internal/core"
 	"github.com/supperghost/ossre/pkg/models"
 )
@@ -16,75 +14,12 @@ import (
 // PluginName 是内核诊断插件的名称常量。
 const PluginName = "kernel"
 
-// 根据不同操作系统定义正确的资源常量
-var (
-	// 进程数限制常量
-	RLIMIT_PROCESS_COUNT int
+// 内核插件包含的场景 ID。
+const (
+	netBaselineScenarioID   = "kernel.net.baseline"
+	limitBaselineScenarioID = "kernel.limit.baseline"
 )
 
-type RLimit struct {
-	Soft int64
-	Hard int64
-}
-
-func GetRlimitNprocFromProc() (*RLimit, error) {
-	f, err := os.Open("/proc/self/limits")
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// 示例行：
-		// Max processes            4096                 4096                 processes
-		if strings.HasPrefix(line, "Max processes") {
-			fields := strings.Fields(line)
-			if len(fields) < 4 {
-				return nil, fmt.Errorf("unexpected format: %q", line)
-			}
-
-			parse := func(s string) (int64, error) {
-				if s == "unlimited" {
-					return -1, nil
-				}
-				return strconv.ParseInt(s, 10, 64)
-			}
-
-			soft, err := parse(fields[2])
-			if err != nil {
-				return nil, err
-			}
-			hard, err := parse(fields[3])
-			if err != nil {
-				return nil, err
-			}
-
-			return &RLimit{
-				Soft: soft,
-				Hard: hard,
-			}, nil
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
-	return nil, fmt.Errorf("Max processes not found in /proc/self/limits")
-}
-
-// 初始化进程数限制常量
-func init() {
-	// 在Linux上使用RLIMIT_NPROC，在macOS上使用RLIMIT_MAXPROC
-	// 这里使用运行时判断而不是编译时条件编译，以便在跨平台编译时更灵活
-	if nr, err := GetRlimitNprocFromProc(); err == nil {
-		RLIMIT_PROCESS_COUNT = int(nr.Soft)
-	}
-}
-
 // Plugin 实现了 core.Plugin 接口，用于执行内核相关诊断。
 type Plugin struct{}
 
@@ -93,6 +28,15 @@ func New() core.Plugin {
 	return &Plugin{}
 }
 
+func init() {
+	core.Register(New, core.Metadata{
+		Version:   "1.0.0",
+		Tags:      []string{"kernel", "network", "capacity"},
+		Scenarios: []string{netBaselineScenarioID, limitBaselineScenarioID},
+		CaseIDs:   []string{"kernel.net.baseline.sysctl.*", "kernel.limit.baseline.ulimit.nofile", "kernel.limit.baseline.ulimit.nproc"},
+	})
+}
+
 func (p *Plugin) Name() string {
 	return PluginName
 }
@@ -170,45 +114,69 @@ var netSysctlBaseline = []sysctlExpectation{
 
 // Run 执行一次诊断。
 // 这里我们实现一个“场景”：网络相关内核参数基线检查 + ulimit 基线检查。
-func (p *Plugin) Run(ctx context.Context) (models.Result, error) {
+func (p *Plugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
 	_ = ctx
 
 	var (
 		allFindings    []models.Finding
 		allSuggestions []models.Suggestion
+		allSkipped     []models.SkippedCheck
 	)
 
 	// 场景 1：网络相关内核参数基线
-	f1, s1 := runNetSysctlBaselineScenario()
-	allFindings = append(allFindings, f1...)
-	allSuggestions = append(allSuggestions, s1...)
+	var metrics []models.Metric
+	if rc.ScenarioEnabled(netBaselineScenarioID) {
+		var f1 []models.Finding
+		var s1 []models.Suggestion
+		var k1 []models.SkippedCheck
+		f1, s1, metrics, k1 = runNetSysctlBaselineScenario(rc)
+		allFindings = append(allFindings, f1...)
+		allSuggestions = append(allSuggestions, s1...)
+		allSkipped = append(allSkipped, k1...)
+	}
 
 	// 场景 2：进程/文件句柄 ulimit 基线
-	f2, s2 := runLimitBaselineScenario()
-	allFindings = append(allFindings, f2...)
-	allSuggestions = append(allSuggestions, s2...)
+	if rc.ScenarioEnabled(limitBaselineScenarioID) {
+		f2, s2, k2 := runLimitBaselineScenario(rc)
+		allFindings = append(allFindings, f2...)
+		allSuggestions = append(allSuggestions, s2...)
+		allSkipped = append(allSkipped, k2...)
+	}
 
 	return models.Result{
 		Plugin:      PluginName,
 		Findings:    allFindings,
 		Suggestions: allSuggestions,
+		Metrics:     metrics,
+		Skipped:     allSkipped,
 	}, nil
 }
 
 // runNetSysctlBaselineScenario 实现“网络相关内核参数基线检查”场景。
 // 场景 ID 示例：kernel.net.baseline
-func runNetSysctlBaselineScenario() ([]models.Finding, []models.Suggestion) {
-	const scenarioID = "kernel.net.baseline"
+// 每个可读取的参数同时输出一个 kernel_sysctl_compliant 指标，符合推荐值为 1，否则为 0；
+// 值为单个数值时另输出 kernel_sysctl_value 指标记录当前值。
+// 因权限不足无法读取的参数记为未评估，其他读取失败给出 warning 级别的发现。
+func runNetSysctlBaselineScenario(rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.Metric, []models.SkippedCheck) {
+	const scenarioID = netBaselineScenarioID
 
 	var (
+		c           = rc.Collector
 		findings    []models.Finding
 		suggestions []models.Suggestion
+		metrics     []models.Metric
+		skipped     []models.SkippedCheck
 	)
 
 	for _, item := range netSysctlBaseline {
-		current, err := readSysctl(item.Key)
+		current, err := c.Sysctl(item.Key)
+		if collectors.IsPermission(err) {
+			id := fmt.Sprintf("%s.sysctl.%s", scenarioID, sanitizeID(item.Key))
+			skipped = append(skipped, rc.Skip(id, collectors.SysctlPath(item.Key), err))
+			continue
+		}
 		if err != nil {
-			// 无法读取时给出 warning，方便后续排查权限或环境问题
+			// 参数不存在等环境问题给出 warning，方便后续排查
 			id := fmt.Sprintf("%s.sysctl.%s.read_error", scenarioID, sanitizeID(item.Key))
 			findings = append(findings, models.Finding{
 				ID:          id,
@@ -221,14 +189,38 @@ func runNetSysctlBaselineScenario() ([]models.Finding, []models.Suggestion) {
 		}
 
 		// 对 ip_local_port_range 这类带空格的值直接按字符串比较即可
-		if strings.TrimSpace(current) == item.Expected {
+		compliant := strings.TrimSpace(current) == item.Expected
+		// 单个数值的参数同时输出当前值，便于对比修复前后的变化
+		if v, err := strconv.ParseFloat(strings.TrimSpace(current), 64); err == nil {
+			metrics = append(metrics, models.Metric{
+				Name:   "kernel_sysctl_value",
+				Help:   "网络基线内核参数的当前值（仅单个数值的参数）",
+				Labels: map[string]string{"key": item.Key},
+				Value:  v,
+			})
+		}
+		metrics = append(metrics, models.Metric{
+			Name:   "kernel_sysctl_compliant",
+			Help:   "内核参数是否符合网络基线推荐值（1 为符合）",
+			Labels: map[string]string{"key": item.Key},
+			Value:  boolValue(compliant),
+		})
+		if compliant {
 			continue
 		}
 
+		// 已在某个配置文件中持久化时指向该文件，否则指向推荐写入的 /etc/sysctl.conf
+		configFile := c.SysctlConfigFile(item.Key)
+		dropIn := configFile
+		if configFile == "" {
+			configFile = "/etc/sysctl.conf"
+			dropIn = models.SysctlDropInFile
+		}
 		findingID := fmt.Sprintf("%s.sysctl.%s", scenarioID, sanitizeID(item.Key))
 		findings = append(findings, models.Finding{
-			ID:    findingID,
-			Title: fmt.Sprintf("内核参数 %s 不符合推荐值", item.Key),
+			ID:         findingID,
+			ConfigFile: configFile,
+			Title:      fmt.Sprintf("内核参数 %s 不符合推荐值", item.Key),
 			Description: fmt.Sprintf(
 				"当前值为 %q，推荐值为 %q。该参数用于：%s。",
 				current, item.Expected, item.Description,
@@ -245,19 +237,34 @@ func runNetSysctlBaselineScenario() ([]models.Finding, []models.Suggestion) {
 					"持久化配置（推荐）：\n  1. 编辑 /etc/sysctl.conf，确保存在如下配置行：\n     %s = %s\n  2. 执行 sysctl -p 使配置立即生效。\n",
 				item.Key, item.Expected, item.Key, item.Expected,
 			),
+			// 已持久化的参数原地修改所在文件，避免被加载顺序靠后的文件覆盖
+			Actions: []models.Action{
+				{Type: models.ActionSysctl, Key: item.Key, Value: item.Expected},
+				{Type: models.ActionSysctlDropIn, Key: item.Key, Value: item.Expected, Path: dropIn},
+			},
 		})
 	}
 
-	return findings, suggestions
+	return findings, suggestions, metrics, skipped
+}
+
+// boolValue 将布尔值转换为 0/1 指标值。
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
 }
 
 // runLimitBaselineScenario 实现“进程/文件句柄 ulimit 基线检查”场景。
 // 对应原 Python 脚本中对 max open files / max user processes 的检查和优化。
-func runLimitBaselineScenario() ([]models.Finding, []models.Suggestion) {
+// 限制值读取自 ossre 自身的 /proc/<pid>/limits，以便离线快照包中也能复现同样的结果；
+// 无法读取时两项检查均记为未评估，而不是按无限制处理。
+func runLimitBaselineScenario(rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.SkippedCheck) {
 	const (
-		scenarioID        = "kernel.limit.baseline"
-		targetMaxOpenFile = uint64(655350)
-		targetMaxProc     = uint64(655350)
+		scenarioID        = limitBaselineScenarioID
+		targetMaxOpenFile = int64(655350)
+		targetMaxProc     = int64(655350)
 	)
 
 	var (
@@ -265,89 +272,92 @@ func runLimitBaselineScenario() ([]models.Finding, []models.Suggestion) {
 		suggestions []models.Suggestion
 	)
 
-	// 检查 RLIMIT_NOFILE（相当于 ulimit -n）
-	if rl, err := getRlimit(syscall.RLIMIT_NOFILE); err == nil {
-		if rl.Cur < targetMaxOpenFile {
-			id := scenarioID + ".ulimit.nofile"
-			findings = append(findings, models.Finding{
-				ID:    id,
-				Title: "进程最大文件句柄数 (RLIMIT_NOFILE) 低于推荐值",
-				Description: fmt.Sprintf(
-					"当前进程软限制为 %d，推荐不小于 %d。过低时在高并发网络/IO 场景下容易触发 'too many open files'。",
-					rl.Cur, targetMaxOpenFile,
-				),
-				Severity: models.SeverityWarning,
-				Impact:   "可能导致服务在峰值流量下无法建立足够多的网络连接或打开文件句柄。",
-			})
-			suggestions = append(suggestions, models.Suggestion{
-				FindingID: id,
-				Title:     "提升最大文件句柄数到 655350",
-				Details: "建议：\n" +
-					"1. 临时调整（当前 shell 会话）：\n" +
-					"   ulimit -SHn 655350\n\n" +
-					"2. 持久化配置（/etc/security/limits.conf）：\n" +
-					"   root soft nofile 655350\n" +
-					"   root hard nofile 655350\n" +
-					"   *    soft nofile 655350\n" +
-					"   *    hard nofile 655350\n" +
-					"修改后需要重新登录或重启对应服务进程生效。",
-			})
+	c := rc.Collector
+	pid := collectors.SelfPID(c)
+	limits, err := c.Limits(pid)
+	if err != nil {
+		path := collectors.ProcDir(pid) + "/limits"
+		return nil, nil, []models.SkippedCheck{
+			rc.Skip(scenarioID+".ulimit.nofile", path, err),
+			rc.Skip(scenarioID+".ulimit.nproc", path, err),
 		}
 	}
-
-	// 检查进程数限制（相当于 ulimit -u）
-	if rl, err := getRlimit(RLIMIT_PROCESS_COUNT); err == nil {
-		if rl.Cur < targetMaxProc {
-			id := scenarioID + ".ulimit.nproc"
-			findings = append(findings, models.Finding{
-				ID:    id,
-				Title: "进程最大数 (RLIMIT_PROCESS_COUNT) 低于推荐值",
-				Description: fmt.Sprintf(
-					"当前进程软限制为 %d，推荐不小于 %d。过低时在多进程/多线程场景下容易触发 'resource temporarily unavailable' 等错误。",
-					rl.Cur, targetMaxProc,
-				),
-				Severity: models.SeverityWarning,
-				Impact:   "可能限制业务水平扩展能力，并在压力场景下导致服务无法拉起新的工作进程。",
-			})
-			suggestions = append(suggestions, models.Suggestion{
-				FindingID: id,
-				Title:     "提升最大进程数到 655350",
-				Details: "建议：\n" +
-					"1. 临时调整（当前 shell 会话）：\n" +
-					"   ulimit -SHu 655350\n\n" +
-					"2. 持久化配置（/etc/security/limits.conf）：\n" +
-					"   root soft nproc 655350\n" +
-					"   root hard nproc 655350\n" +
-					"   *    soft nproc 655350\n" +
-					"   *    hard nproc 655350\n" +
-					"修改后需要重新登录或重启对应服务进程生效。",
-			})
-		}
+	// below 判断软限制是否低于推荐值，unlimited 或缺失时视为满足
+	below := func(name string, target int64) (int64, bool) {
+		soft := limits.Soft(name)
+		return soft, soft != collectors.Unlimited && soft < target
 	}
 
-	return findings, suggestions
-}
-
-// readSysctl 通过 /proc/sys 读取 sysctl 参数的当前值。
-func readSysctl(key string) (string, error) {
-	// 例如 net.ipv4.tcp_syncookies -> /proc/sys/net/ipv4/tcp_syncookies
-	path := "/proc/sys/" + strings.ReplaceAll(key, ".", "/")
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
+	// 检查 RLIMIT_NOFILE（相当于 ulimit -n）
+	if cur, ok := below(collectors.LimitNofile, targetMaxOpenFile); ok {
+		id := scenarioID + ".ulimit.nofile"
+		findings = append(findings, models.Finding{
+			ID:         id,
+			ConfigFile: limitsConfFile,
+			Title:      "进程最大文件句柄数 (RLIMIT_NOFILE) 低于推荐值",
+			Description: fmt.Sprintf(
+				"当前进程软限制为 %d，推荐不小于 %d。过低时在高并发网络/IO 场景下容易触发 'too many open files'。",
+				cur, targetMaxOpenFile,
+			),
+			Severity: models.SeverityWarning,
+			Impact:   "可能导致服务在峰值流量下无法建立足够多的网络连接或打开文件句柄。",
+		})
+		suggestions = append(suggestions, models.Suggestion{
+			FindingID: id,
+			Title:     "提升最大文件句柄数到 655350",
+			Details: "建议：\n" +
+				"1. 临时调整（当前 shell 会话）：\n" +
+				"   ulimit -SHn 655350\n\n" +
+				"2. 持久化配置（/etc/security/limits.conf）：\n" +
+				"   root soft nofile 655350\n" +
+				"   root hard nofile 655350\n" +
+				"   *    soft nofile 655350\n" +
+				"   *    hard nofile 655350\n" +
+				"修改后需要重新登录或重启对应服务进程生效。",
+			Actions: []models.Action{
+				{Type: models.ActionLimitsDropIn, Key: "nofile", Value: strconv.FormatInt(targetMaxOpenFile, 10), Path: models.LimitsDropInFile},
+			},
+		})
 	}
-	return strings.TrimSpace(string(data)), nil
-}
 
-// getRlimit 读取指定资源的 RLIMIT。
-func getRlimit(resource int) (*syscall.Rlimit, error) {
-	var rl syscall.Rlimit
-	if err := syscall.Getrlimit(resource, &rl); err != nil {
-		return nil, err
+	// 检查进程数限制（相当于 ulimit -u）
+	if cur, ok := below(collectors.LimitNproc, targetMaxProc); ok {
+		id := scenarioID + ".ulimit.nproc"
+		findings = append(findings, models.Finding{
+			ID:         id,
+			ConfigFile: limitsConfFile,
+			Title:      "进程最大数 (RLIMIT_NPROC) 低于推荐值",
+			Description: fmt.Sprintf(
+				"当前进程软限制为 %d，推荐不小于 %d。过低时在多进程/多线程场景下容易触发 'resource temporarily unavailable' 等错误。",
+				cur, targetMaxProc,
+			),
+			Severity: models.SeverityWarning,
+			Impact:   "可能限制业务水平扩展能力，并在压力场景下导致服务无法拉起新的工作进程。",
+		})
+		suggestions = append(suggestions, models.Suggestion{
+			FindingID: id,
+			Title:     "提升最大进程数到 655350",
+			Details: "建议：\n" +
+				"1. 临时调整（当前 shell 会话）：\n" +
+				"   ulimit -SHu 655350\n\n" +
+				"2. 持久化配置（/etc/security/limits.conf）：\n" +
+				"   root soft nproc 655350\n" +
+				"   root hard nproc 655350\n" +
+				"   *    soft nproc 655350\n" +
+				"   *    hard nproc 655350\n" +
+				"修改后需要重新登录或重启对应服务进程生效。",
+			Actions: []models.Action{
+				{Type: models.ActionLimitsDropIn, Key: "nproc", Value: strconv.FormatInt(targetMaxProc, 10), Path: models.LimitsDropInFile},
+			},
+		})
 	}
-	return &rl, nil
+
+	return findings, suggestions, nil
 }
 
+// limitsConfFile 为 pam_limits 的系统级配置文件。
+const limitsConfFile = "/etc/security/limits.conf"
+
 // sanitizeID 将 sysctl key 转成适合作为 Finding.ID 的形式。
 func sanitizeID(key string) string {
 	// net.ipv4.tcp_syncookies -> net_ipv4_tcp_syncookies
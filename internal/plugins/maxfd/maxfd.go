@@ -0,0 +1,53 @@
+package maxfd
+
+import (
+	"context"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// PluginName 是 maxfd 诊断插件的名称常量。
+const PluginName = "maxfd"
+
+// Plugin 实现了 core.Plugin 接口，用于执行进程文件描述符余量诊断。
+type Plugin struct{}
+
+// New 创建一个新的 maxfd 诊断插件实例。
+func New() core.Plugin {
+	return &Plugin{}
+}
+
+func init() {
+	core.Register(New, core.Metadata{
+		Version: "1.0.0",
+		Tags:    []string{"capacity", "process", "container"},
+		OS:      []string{"linux"},
+		// /proc/<pid>/limits 自 2.6.24 起提供
+		MinKernel:  "2.6.24",
+		Privileges: []string{"CAP_SYS_PTRACE"},
+		Scenarios:  []string{"maxfd.fd.headroom", "maxfd.fd.trend"},
+		CaseIDs:    []string{"maxfd.fd.headroom", "maxfd.fd.trend"},
+	})
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Description() string {
+	return "进程文件描述符余量诊断（Linux 专属，其他平台降级提示）"
+}
+
+// Run 调用平台相关的场景实现，返回诊断结果。
+func (p *Plugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	findings, suggestions, metrics, skipped := runMaxfdScenario(ctx, rc)
+
+	return models.Result{
+		Plugin:      PluginName,
+		Findings:    findings,
+		Suggestions: suggestions,
+		Metrics:     metrics,
+		Skipped:     skipped,
+	}, nil
+}
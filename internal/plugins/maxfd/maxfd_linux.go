@@ -0,0 +1,531 @@
+//go:build linux
+// +build linux
+
+package maxfd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+const (
+	fdHeadroomFindingID = "maxfd.fd.headroom"
+	fdTrendFindingID    = "maxfd.fd.trend"
+	fdHeadroomUnlimited = int64(999999999)
+
+	defaultSampleInterval    = 5 * time.Second
+	defaultForecastThreshold = time.Hour
+)
+
+// fdTypes 定义 fd 分类的固定输出顺序。
+var fdTypes = []string{"socket", "pipe", "regular file", "anon_inode", "eventfd", "other"}
+
+// runMaxfdScenario 在 Linux 上实现“还能打开多少文件描述符”与“首个阻断因素”场景。
+// 与 maxproc 的线程余量模型一致：逐维度估算剩余量，取最小值作为首个阻断因素。
+// fd 目录因权限不足不可读时整个场景记为未评估；某个维度的数据不可读时该维度记为未评估，其余维度照常估算。
+func runMaxfdScenario(ctx context.Context, rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.Metric, []models.SkippedCheck) {
+	c := rc.Collector
+	pid := resolveTargetPID(c, rc.Target)
+
+	first, err := measureFdHeadroom(c, pid)
+	if collectors.IsPermission(err) {
+		fdDir := path.Join(collectors.ProcDir(pid), "fd")
+		skipped := []models.SkippedCheck{rc.Skip(fdHeadroomFindingID, fdDir, err, "CAP_SYS_PTRACE")}
+		if rc.Config.Sampling.Window > 0 && rc.ScenarioEnabled(fdTrendFindingID) {
+			skipped = append(skipped, rc.Skip(fdTrendFindingID, fdDir, err, "CAP_SYS_PTRACE"))
+		}
+		return nil, nil, nil, skipped
+	}
+	if err != nil {
+		finding := models.Finding{
+			ID:          fdHeadroomFindingID,
+			Title:       "无法评估文件描述符余量：目标进程不存在或 fd 目录不可读",
+			Description: fmt.Sprintf("读取目标 PID=%d 的 %s 失败: %v", pid, path.Join(collectors.ProcDir(pid), "fd"), err),
+			Severity:    models.SeverityError,
+			Impact:      "无法基于该进程的资源限制估算可打开的文件描述符数，请确认 PID 是否正确且具备读取权限。",
+		}
+		suggestion := models.Suggestion{
+			FindingID: fdHeadroomFindingID,
+			Title:     "检查 PID 是否正确以及读取权限",
+			Details:   fmt.Sprintf("请确认 PID=%d 对应的进程是否仍在运行；读取其他用户进程的 /proc/<pid>/fd 需要 root 或 CAP_SYS_PTRACE 权限。", pid),
+		}
+		return []models.Finding{finding}, []models.Suggestion{suggestion}, nil, nil
+	}
+
+	findings := []models.Finding{buildFdHeadroomFinding(pid, first)}
+	metrics := fdHeadroomMetrics(pid, first)
+	var suggestions []models.Suggestion
+	var skipped []models.SkippedCheck
+	for _, u := range first.Unread {
+		skipped = append(skipped, rc.Skip(fdHeadroomFindingID+"."+u.Dimension, u.Path, u.Err, u.Requires...))
+	}
+	if s := buildFdHeadroomSuggestion(first.Reason); s.FindingID != "" {
+		suggestions = append(suggestions, s)
+	}
+
+	// 指定采样窗口时，在窗口内按间隔采样，拟合 fd 增长速率
+	if sampling := rc.Config.Sampling; sampling.Window > 0 && first.MinLeft > 0 && rc.ScenarioEnabled(fdTrendFindingID) {
+		interval := sampling.Interval
+		if interval <= 0 {
+			interval = defaultSampleInterval
+		}
+		threshold := sampling.ForecastThreshold
+		if threshold <= 0 {
+			threshold = defaultForecastThreshold
+		}
+		samples, err := c.Sample(ctx, interval, sampling.Window, fdSource(pid))
+		if err != nil {
+			skipped = append(skipped, rc.Skip(fdTrendFindingID, path.Join(collectors.ProcDir(pid), "fd"), err, "CAP_SYS_PTRACE"))
+			return findings, suggestions, metrics, skipped
+		}
+		if samples.Len() < 2 {
+			// ctx 取消时只保留快照评估
+			return findings, suggestions, metrics, skipped
+		}
+		tf, ts := evaluateFdTrend(pid, samples, threshold)
+		findings = append(findings, tf)
+		if ts.FindingID != "" {
+			suggestions = append(suggestions, ts)
+		}
+	}
+
+	return findings, suggestions, metrics, skipped
+}
+
+// resolveTargetPID 返回诊断目标中的首个 PID；若未指定，则回退为 ossre 自身在采集视角下的 PID。
+func resolveTargetPID(c *collectors.Collector, target core.Target) int {
+	if pid := target.PrimaryPID(); pid > 0 {
+		return pid
+	}
+	return collectors.SelfPID(c)
+}
+
+// fdHeadroom 保存一次文件描述符余量估算的结果。
+type fdHeadroom struct {
+	OpenFds int64
+	ByType  map[string]int64
+
+	// 进程级：Max open files 软限制
+	NofileSoft      int64
+	NofileUnlimited bool
+	// 系统级：已分配文件句柄数（file-nr 第 1 列）与 fs.file-max
+	SysAllocated int64
+	FileMax      int64
+	// fs.nr_open：单进程 RLIMIT_NOFILE 可设置的上限
+	NrOpen int64
+
+	// A/B/C 三个维度的剩余量，无限制时为 fdHeadroomUnlimited。
+	ALeft, BLeft, CLeft int64
+	MinLeft             int64
+	Reason              string
+	Used                int64
+	Limit               int64
+	// Unread 为无法读取数据、按无限制估算的维度。
+	Unread []unreadDimension
+}
+
+// unreadDimension 表示余量估算中因数据不可读而未评估的一个维度。
+type unreadDimension struct {
+	// Dimension 与 maxfd_fd_headroom 指标的 dimension 标签一致，如 nofile。
+	Dimension string
+	Path      string
+	Err       error
+	// Requires 为读取该维度数据所需的权限。
+	Requires []string
+}
+
+// unreadDimensions 返回未评估维度的名称。
+func unreadDimensions(unread []unreadDimension) []string {
+	dims := make([]string, 0, len(unread))
+	for _, u := range unread {
+		dims = append(dims, u.Dimension)
+	}
+	return dims
+}
+
+// Headroom 描述目标进程文件描述符余量的首个阻断因素，供全主机扫描等其他插件复用。
+type Headroom struct {
+	OpenFds int64
+	Left    int64
+	Reason  string
+	Used    int64
+	Limit   int64
+}
+
+// MeasureHeadroom 估算指定 PID 的文件描述符余量，fd 目录不可读时返回错误。
+func MeasureHeadroom(c *collectors.Collector, pid int) (Headroom, error) {
+	h, err := measureFdHeadroom(c, pid)
+	if err != nil {
+		return Headroom{}, err
+	}
+	return Headroom{
+		OpenFds: h.OpenFds,
+		Left:    h.MinLeft,
+		Reason:  h.Reason,
+		Used:    h.Used,
+		Limit:   h.Limit,
+	}, nil
+}
+
+// measureFdHeadroom 采集一次 /proc/<pid>/fd、limits 与 /proc/sys/fs 数据并计算各维度余量。
+func measureFdHeadroom(c *collectors.Collector, pid int) (fdHeadroom, error) {
+	fds, err := c.Fds(pid)
+	if err != nil {
+		return fdHeadroom{}, err
+	}
+	byType := classifyFds(fds)
+	openFds := int64(len(fds))
+
+	h := fdHeadroom{
+		OpenFds: openFds,
+		ByType:  byType,
+	}
+	// 无法读取的维度按“无上限”参与估算以避免错误告警，同时记入 Unread
+	unread := func(dimension, name string, err error, requires ...string) {
+		if err == nil {
+			return
+		}
+		for _, u := range h.Unread {
+			if u.Dimension == dimension {
+				return
+			}
+		}
+		h.Unread = append(h.Unread, unreadDimension{Dimension: dimension, Path: name, Err: err, Requires: requires})
+	}
+	limits, err := c.Limits(pid)
+	unread("nofile", path.Join(collectors.ProcDir(pid), "limits"), err, "CAP_SYS_PTRACE")
+	if soft := limits.Soft(collectors.LimitNofile); soft == collectors.Unlimited {
+		h.NofileUnlimited = true
+	} else {
+		h.NofileSoft = soft
+	}
+	nr, err := c.FileNr()
+	unread("file_max", collectors.SysctlPath("fs.file-nr"), err)
+	h.SysAllocated = nr.Allocated
+	if h.FileMax, err = c.SysctlInt("fs.file-max"); err != nil {
+		unread("file_max", collectors.SysctlPath("fs.file-max"), err)
+	}
+	h.NrOpen, err = c.SysctlInt("fs.nr_open")
+	unread("nr_open", collectors.SysctlPath("fs.nr_open"), err)
+
+	// A: 进程 Max open files 软限制剩余
+	if h.NofileUnlimited {
+		h.ALeft = fdHeadroomUnlimited
+	} else {
+		h.ALeft = h.NofileSoft - openFds
+	}
+
+	// B: 系统级 fs.file-max 剩余（file-max - 已分配句柄数）
+	if h.FileMax <= 0 || h.SysAllocated <= 0 {
+		h.BLeft = fdHeadroomUnlimited
+	} else {
+		h.BLeft = h.FileMax - h.SysAllocated
+	}
+
+	// C: fs.nr_open 剩余（即使放宽软/硬限制也无法超过该值）
+	if h.NrOpen <= 0 {
+		h.CLeft = fdHeadroomUnlimited
+	} else {
+		h.CLeft = h.NrOpen - openFds
+	}
+
+	h.MinLeft, h.Reason, h.Used, h.Limit = h.ALeft, "nofile", openFds, h.NofileSoft
+	if h.BLeft < h.MinLeft {
+		h.MinLeft, h.Reason, h.Used, h.Limit = h.BLeft, "fs.file-max", h.SysAllocated, h.FileMax
+	}
+	if h.CLeft < h.MinLeft {
+		h.MinLeft, h.Reason, h.Used, h.Limit = h.CLeft, "fs.nr_open", openFds, h.NrOpen
+	}
+
+	return h, nil
+}
+
+// buildFdHeadroomFinding 根据一次余量估算结果生成快照 Finding。
+func buildFdHeadroomFinding(pid int, h fdHeadroom) models.Finding {
+	severity := models.SeverityInfo
+	switch {
+	case h.MinLeft <= 0:
+		severity = models.SeverityError
+	case h.Limit > 0 && h.Used*10 >= h.Limit*9:
+		severity = models.SeverityWarning
+	}
+
+	desc := fmt.Sprintf("目标进程 PID=%d 当前打开文件描述符 %d 个。按 nofile 软限制、fs.file-max、fs.nr_open 三个维度估算，还可打开约 %d 个，首个阻断因素为 %s（用量 %d / 上限 %d）。",
+		pid, h.OpenFds, h.MinLeft, h.Reason, h.Used, h.Limit)
+	descDetails := fmt.Sprintf("A(nofile) 剩余: %d\nB(fs.file-max) 剩余: %d\nC(fs.nr_open) 剩余: %d", h.ALeft, h.BLeft, h.CLeft)
+
+	parts := make([]string, 0, len(fdTypes))
+	for _, t := range fdTypes {
+		if n := h.ByType[t]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%d", t, n))
+		}
+	}
+	if len(parts) > 0 {
+		descDetails += "\nfd 类型分布: " + strings.Join(parts, ", ")
+	}
+	if dims := unreadDimensions(h.Unread); len(dims) > 0 {
+		descDetails += "\n未评估的维度（数据不可读，按无限制估算）: " + strings.Join(dims, ", ")
+	}
+
+	return models.Finding{
+		ID:          fdHeadroomFindingID,
+		Title:       "文件描述符余量评估",
+		Description: desc + "\n" + descDetails,
+		Severity:    severity,
+		Impact:      "当文件描述符余量耗尽时，目标进程的 open/accept/socket/pipe 等调用将返回 EMFILE 或 ENFILE（'too many open files'），表现为新连接被拒绝或文件无法打开。",
+	}
+}
+
+// fdHeadroomMetrics 将一次余量估算转换为指标，包括目标进程的 fd 用量与系统级文件句柄用量；
+// 无限制或读取失败的维度不输出。
+func fdHeadroomMetrics(pid int, h fdHeadroom) []models.Metric {
+	p := strconv.Itoa(pid)
+	metrics := []models.Metric{{
+		Name:   "maxfd_open_fds",
+		Help:   "目标进程当前打开的文件描述符数",
+		Labels: map[string]string{"pid": p},
+		Value:  float64(h.OpenFds),
+	}}
+	for _, d := range []struct {
+		dimension string
+		left      int64
+	}{
+		{"nofile", h.ALeft},
+		{"file_max", h.BLeft},
+		{"nr_open", h.CLeft},
+	} {
+		if d.left == fdHeadroomUnlimited {
+			continue
+		}
+		metrics = append(metrics, models.Metric{
+			Name:   "maxfd_fd_headroom",
+			Help:   "目标进程按各维度估算的还可打开文件描述符数",
+			Labels: map[string]string{"pid": p, "dimension": d.dimension},
+			Value:  float64(d.left),
+		})
+	}
+	if h.SysAllocated > 0 {
+		metrics = append(metrics, models.Metric{
+			Name:  "maxfd_system_file_handles_allocated",
+			Help:  "系统已分配的文件句柄数（/proc/sys/fs/file-nr 第 1 列）",
+			Value: float64(h.SysAllocated),
+		})
+	}
+	if h.FileMax > 0 {
+		metrics = append(metrics, models.Metric{
+			Name:  "maxfd_system_file_max",
+			Help:  "系统文件句柄上限 fs.file-max",
+			Value: float64(h.FileMax),
+		})
+	}
+	return metrics
+}
+
+// fdDimensions 为参与趋势预测的余量维度：采样键为 "headroom/<dimension>"，与 maxfd_fd_headroom 指标的
+// dimension 标签一致，reason 与快照评估中的阻断因素名称一致。
+var fdDimensions = []struct {
+	dimension, reason string
+	left              func(fdHeadroom) int64
+}{
+	{"nofile", "nofile", func(h fdHeadroom) int64 { return h.ALeft }},
+	{"file_max", "fs.file-max", func(h fdHeadroom) int64 { return h.BLeft }},
+	{"nr_open", "fs.nr_open", func(h fdHeadroom) int64 { return h.CLeft }},
+}
+
+// fdSource 将一次文件描述符余量估算转换为采样值："fds/open" 与 "fds/<类型>" 为当前 fd 数，
+// "headroom/<dimension>" 为各维度剩余量（已耗尽时为 0，无限制时不包含该键）。
+func fdSource(pid int) collectors.CounterSource {
+	return func(c *collectors.Collector) (collectors.Counters, error) {
+		h, err := measureFdHeadroom(c, pid)
+		if err != nil {
+			return nil, err
+		}
+		values := collectors.Counters{"fds/open": uint64(h.OpenFds)}
+		for _, t := range fdTypes {
+			values["fds/"+t] = uint64(h.ByType[t])
+		}
+		for _, d := range fdDimensions {
+			if left := d.left(h); left < fdHeadroomUnlimited {
+				values["headroom/"+d.dimension] = uint64(max(left, 0))
+			}
+		}
+		return values, nil
+	}
+}
+
+// evaluateFdTrend 按采样窗口内 fd 数与各维度剩余量的拟合速率预测耗尽时间。
+func evaluateFdTrend(pid int, samples *collectors.Samples, threshold time.Duration) (models.Finding, models.Suggestion) {
+	rate, _ := samples.Slope("fds/open")
+	firstFds, lastFds, _ := samples.Endpoints("fds/open")
+
+	desc := fmt.Sprintf("在 %s 内对目标进程 PID=%d 采集了 %d 个样本，文件描述符数由 %d 变为 %d，拟合增长速率约为 %.2f 个/分钟。",
+		samples.Elapsed().Round(time.Second), pid, samples.Len(), firstFds, lastFds, rate*60)
+
+	var changed []string
+	for _, t := range fdTypes {
+		if first, last, ok := samples.Endpoints("fds/" + t); ok && first != last {
+			changed = append(changed, fmt.Sprintf("%s %+d", t, int64(last)-int64(first)))
+		}
+	}
+	if len(changed) > 0 {
+		desc += "\n按类型变化: " + strings.Join(changed, ", ")
+	}
+
+	// 各维度剩余量的下降速率；窗口内曾为无限制的维度不参与预测
+	var (
+		eta       time.Duration
+		etaReason string
+	)
+	for _, d := range fdDimensions {
+		key := "headroom/" + d.dimension
+		if samples.Gauge(key).Count != samples.Len() {
+			continue
+		}
+		dimRate, ok := samples.Slope(key)
+		if !ok {
+			continue
+		}
+		_, last, _ := samples.Endpoints(key)
+		var dimETA time.Duration
+		switch {
+		case last == 0:
+			dimETA = 0
+		case dimRate < 0:
+			seconds := float64(last) / -dimRate
+			if seconds >= math.MaxInt64/float64(time.Second) {
+				continue
+			}
+			dimETA = time.Duration(seconds * float64(time.Second))
+		default:
+			continue
+		}
+		if etaReason == "" || dimETA < eta {
+			eta, etaReason = dimETA, d.reason
+		}
+	}
+
+	if etaReason == "" {
+		finding := models.Finding{
+			ID:          fdTrendFindingID,
+			Title:       "文件描述符增长趋势评估：未发现持续消耗",
+			Description: desc + "\n采样窗口内各受限维度的剩余量均未呈下降趋势，无法预测耗尽时间。",
+			Severity:    models.SeverityInfo,
+			Impact:      "当前负载下文件描述符消耗稳定；若业务存在周期性波动，建议拉长采样窗口复核。",
+		}
+		return finding, models.Suggestion{}
+	}
+
+	severity := models.SeverityInfo
+	if eta < threshold {
+		severity = models.SeverityError
+	}
+	finding := models.Finding{
+		ID:    fdTrendFindingID,
+		Title: "文件描述符增长趋势评估：预计耗尽时间",
+		Description: desc + fmt.Sprintf("\n按当前趋势，%s 维度预计 %s 后耗尽（告警阈值 %s）。",
+			etaReason, eta.Round(time.Second), threshold),
+		Severity: severity,
+		Impact:   "若增长持续，目标进程将在预计时间点后无法打开新的文件或建立新连接。",
+	}
+	if severity == models.SeverityInfo {
+		return finding, models.Suggestion{}
+	}
+
+	limitSuggestion := buildFdHeadroomSuggestion(etaReason)
+	suggestion := models.Suggestion{
+		FindingID: fdTrendFindingID,
+		Title:     "排查文件描述符泄漏并为首个耗尽维度预留余量",
+		Details: "检测到文件描述符预计将在告警阈值内耗尽。" +
+			"\n\n" +
+			"1. 确认是否存在 fd 泄漏（重点关注增长最快的类型）：\n" +
+			fmt.Sprintf("   ls -l /proc/%d/fd | awk '{print $NF}' | sed 's/:.*//' | sort | uniq -c | sort -rn\n", pid) +
+			fmt.Sprintf("   lsof -p %d\n\n", pid) +
+			"2. 在根因修复前，可按以下方式临时扩大首个耗尽维度的上限：\n\n" +
+			limitSuggestion.Details,
+	}
+	return finding, suggestion
+}
+
+// classifyFds 按链接目标对 fd 进行分类计数。
+func classifyFds(fds []collectors.FdInfo) map[string]int64 {
+	byType := make(map[string]int64, len(fdTypes))
+	for _, fd := range fds {
+		byType[classifyFdTarget(fd.Target)]++
+	}
+	return byType
+}
+
+// classifyFdTarget 根据 /proc/<pid>/fd/<n> 的链接目标判断 fd 类型。
+func classifyFdTarget(target string) string {
+	switch {
+	case strings.HasPrefix(target, "socket:"):
+		return "socket"
+	case strings.HasPrefix(target, "pipe:"):
+		return "pipe"
+	case target == "anon_inode:[eventfd]":
+		return "eventfd"
+	case strings.HasPrefix(target, "anon_inode:"):
+		return "anon_inode"
+	case strings.HasPrefix(target, "/"):
+		return "regular file"
+	default:
+		return "other"
+	}
+}
+
+// buildFdHeadroomSuggestion 根据首个阻断因素生成对应的建议。
+func buildFdHeadroomSuggestion(reason string) models.Suggestion {
+	switch reason {
+	case "nofile":
+		return models.Suggestion{
+			FindingID: fdHeadroomFindingID,
+			Title:     "提升进程最大文件句柄数 (nofile) 以扩展 fd 余量",
+			Details: "检测到首个阻断因素为 Max open files (nofile) 软限制。" +
+				"\n\n" +
+				"1. 临时提升当前会话限制（仅对当前 shell/服务进程生效）：\n" +
+				"   ulimit -SHn 655350\n\n" +
+				"2. 持久化为系统级配置（/etc/security/limits.conf 示例）：\n" +
+				"   * soft nofile 655350\n" +
+				"   * hard nofile 655350\n\n" +
+				"3. systemd 托管的服务需在 unit 文件中设置 LimitNOFILE=655350，limits.conf 对其不生效。\n\n" +
+				"修改完成后需重新登录或重启相关服务，使新的 nofile 限制生效。",
+		}
+	case "fs.file-max":
+		return models.Suggestion{
+			FindingID: fdHeadroomFindingID,
+			Title:     "调整 fs.file-max 提升系统级文件句柄上限",
+			Details: "检测到首个阻断因素为内核参数 fs.file-max（系统已分配句柄数接近上限）。" +
+				"\n\n" +
+				"1. 临时调整（重启失效）：\n" +
+				"   sysctl -w fs.file-max=<新上限>\n\n" +
+				"2. 持久化配置（/etc/sysctl.conf 示例）：\n" +
+				"   fs.file-max = <新上限>\n" +
+				"   sysctl -p\n\n" +
+				"同时建议通过 cat /proc/sys/fs/file-nr 与 lsof 定位占用句柄最多的进程。",
+		}
+	case "fs.nr_open":
+		return models.Suggestion{
+			FindingID: fdHeadroomFindingID,
+			Title:     "调整 fs.nr_open 放宽单进程文件句柄上限",
+			Details: "检测到首个阻断因素为内核参数 fs.nr_open（单进程 nofile 限制无法超过该值）。" +
+				"\n\n" +
+				"1. 临时调整（重启失效）：\n" +
+				"   sysctl -w fs.nr_open=<新上限>\n\n" +
+				"2. 持久化配置（/etc/sysctl.conf 示例）：\n" +
+				"   fs.nr_open = <新上限>\n" +
+				"   sysctl -p\n\n" +
+				"调整后还需同步提升进程的 nofile 软/硬限制才能实际生效。",
+		}
+	default:
+		return models.Suggestion{}
+	}
+}
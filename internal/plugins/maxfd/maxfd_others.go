@@ -0,0 +1,27 @@
+//go:build !linux
+// +build !linux
+
+package maxfd
+
+import (
+	"context"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// runMaxfdScenario 在非 Linux 平台上提供降级实现。
+// 该模块依赖 Linux 的 /proc 接口，这里仅返回一条信息级别的 Finding，说明场景不适用。
+func runMaxfdScenario(ctx context.Context, rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.Metric, []models.SkippedCheck) {
+	_, _ = ctx, rc
+
+	finding := models.Finding{
+		ID:          "maxfd.fd.headroom",
+		Title:       "文件描述符余量场景当前操作系统不支持",
+		Description: "maxfd 模块依赖 Linux 的 /proc/<pid>/fd 与 /proc/sys/fs 接口，仅在 Linux 上可用；当前操作系统不支持，无法评估可打开文件描述符数与首个阻断因素。",
+		Severity:    models.SeverityInfo,
+		Impact:      "仅影响 maxfd 模块的文件描述符余量诊断，其他插件与场景不受影响。",
+	}
+
+	return []models.Finding{finding}, nil, nil, nil
+}
@@ -18,6 +18,19 @@ func New() core.Plugin {
 	return &Plugin{}
 }
 
+func init() {
+	core.Register(New, core.Metadata{
+		Version: "1.0.0",
+		Tags:    []string{"capacity", "process", "container"},
+		OS:      []string{"linux"},
+		// /proc/<pid>/limits 自 2.6.24 起提供
+		MinKernel:  "2.6.24",
+		Privileges: []string{"CAP_SYS_PTRACE"},
+		Scenarios:  []string{"maxproc.thread.headroom", "maxproc.thread.trend"},
+		CaseIDs:    []string{"maxproc.thread.headroom", "maxproc.thread.trend"},
+	})
+}
+
 func (p *Plugin) Name() string {
 	return PluginName
 }
@@ -27,12 +40,14 @@ func (p *Plugin) Description() string {
 }
 
 // Run 调用平台相关的场景实现，返回诊断结果。
-func (p *Plugin) Run(ctx context.Context) (models.Result, error) {
-	findings, suggestions := runMaxprocScenario(ctx)
+func (p *Plugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	findings, suggestions, metrics, skipped := runMaxprocScenario(ctx, rc)
 
 	return models.Result{
 		Plugin:      PluginName,
 		Findings:    findings,
 		Suggestions: suggestions,
+		Metrics:     metrics,
+		Skipped:     skipped,
 	}, nil
 }
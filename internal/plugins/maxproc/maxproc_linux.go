@@ -4,15 +4,17 @@
 package maxproc
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
+	"path"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
 	"github.com/supperghost/ossre/pkg/models"
 )
 
@@ -23,54 +25,57 @@ const (
 
 // runMaxprocScenario 在 Linux 上实现“还能创建多少线程”与“首个阻断因素”场景。
 // 逻辑等同于 kernel.thread.headroom 的 Linux 版本，通过 /proc、/sys 以及 cgroup v1/v2 估算线程创建余量。
-func runMaxprocScenario(ctx context.Context) ([]models.Finding, []models.Suggestion) {
-	pid := resolveTargetPID(ctx)
+// 某个维度的数据不可读时该维度记为未评估（案例 ID 为 maxproc.thread.headroom.<维度>），其余维度照常估算。
+func runMaxprocScenario(ctx context.Context, rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.Metric, []models.SkippedCheck) {
+	c := rc.Collector
+	pid := resolveTargetPID(c, rc.Target)
 
-	finding, suggestion := evaluateThreadCreationHeadroom(ctx, pid)
+	finding, suggestion, metrics, unread := evaluateThreadCreationHeadroom(ctx, c, pid)
 
 	findings := []models.Finding{finding}
 	var suggestions []models.Suggestion
 	if suggestion.FindingID != "" || suggestion.Title != "" || suggestion.Details != "" {
 		suggestions = append(suggestions, suggestion)
 	}
+	var skipped []models.SkippedCheck
+	for _, u := range unread {
+		skipped = append(skipped, rc.Skip(threadHeadroomFindingID+"."+u.Dimension, u.Path, u.Err, u.Requires...))
+	}
 
-	return findings, suggestions
-}
-
-// resolveTargetPID 从 ctx 中解析目标 PID；若未指定或非法，则回退为当前进程 PID。
-func resolveTargetPID(ctx context.Context) int {
-	v := ctx.Value("ossre.pid")
-	switch vv := v.(type) {
-	case int:
-		if vv > 0 {
-			return vv
-		}
-	case int32:
-		if vv > 0 {
-			return int(vv)
-		}
-	case int64:
-		if vv > 0 {
-			return int(vv)
+	// 指定采样窗口时，追加线程增长趋势与耗尽时间预测
+	// 余量未评估（没有指标）时同样不做趋势采样
+	if sampling := rc.Config.Sampling; sampling.Window > 0 && finding.Severity != models.SeverityError && len(metrics) > 0 && rc.ScenarioEnabled(threadTrendFindingID) {
+		interval := sampling.Interval
+		if interval <= 0 {
+			interval = defaultSampleInterval
 		}
-	case float64:
-		if vv > 0 {
-			return int(vv)
+		threshold := sampling.ForecastThreshold
+		if threshold <= 0 {
+			threshold = defaultForecastThreshold
 		}
-	case string:
-		if vv != "" {
-			if n, err := strconv.Atoi(vv); err == nil && n > 0 {
-				return n
-			}
+		tf, ts := evaluateThreadHeadroomTrend(ctx, c, pid, sampling.Window, interval, threshold)
+		findings = append(findings, tf)
+		if ts.FindingID != "" {
+			suggestions = append(suggestions, ts)
 		}
 	}
-	return os.Getpid()
+
+	return findings, suggestions, metrics, skipped
+}
+
+// resolveTargetPID 返回诊断目标中的首个 PID；若未指定，则回退为 ossre 自身在采集视角下的 PID。
+func resolveTargetPID(c *collectors.Collector, target core.Target) int {
+	if pid := target.PrimaryPID(); pid > 0 {
+		return pid
+	}
+	return collectors.SelfPID(c)
 }
 
 // evaluateThreadCreationHeadroom 基于 /proc 与 cgroup 信息估算线程创建余量。
-func evaluateThreadCreationHeadroom(ctx context.Context, pid int) (models.Finding, models.Suggestion) {
-	procDir := fmt.Sprintf("/proc/%d", pid)
-	if st, err := os.Stat(procDir); err != nil || !st.IsDir() {
+// 目标进程可访问时同时返回当前线程数与各维度余量指标，以及数据不可读的维度。
+func evaluateThreadCreationHeadroom(ctx context.Context, c *collectors.Collector, pid int) (models.Finding, models.Suggestion, []models.Metric, []unreadDimension) {
+	procDir := collectors.ProcDir(pid)
+	if !c.ProcExists(pid) {
 		desc := fmt.Sprintf("目标 PID=%d 对应的 %s 不存在或不可访问，无法评估线程创建余量。", pid, procDir)
 		finding := models.Finding{
 			ID:          threadHeadroomFindingID,
@@ -84,29 +89,202 @@ func evaluateThreadCreationHeadroom(ctx context.Context, pid int) (models.Findin
 			Title:     "检查 PID 是否正确以及 /proc 是否挂载",
 			Details:   fmt.Sprintf("请确认 PID=%d 对应的进程是否仍在运行；在容器场景中，确保 /proc 已正确挂载为宿主的 /proc。", pid),
 		}
-		return finding, suggestion
+		return finding, suggestion, nil, nil
 	}
 
+	h := measureThreadHeadroom(ctx, c, pid)
+
+	// 线程列表不可读或四个维度均不可读时，按无限制估算得到的余量没有意义，只说明未评估，不给出建议与指标
+	if h.CurThreads <= 0 || len(h.Unread) == len(threadHeadroomDimensions) {
+		finding := models.Finding{
+			ID:    threadHeadroomFindingID,
+			Title: "线程创建余量未评估：所需数据不可读",
+			Description: fmt.Sprintf("目标进程 PID=%d 的线程列表（%s）或全部估算维度的数据不可读，无法估算线程创建余量。未评估的维度: %s，原因见未评估的检查。",
+				pid, path.Join(procDir, "task"), strings.Join(unreadDimensions(h.Unread), ", ")),
+			Severity: models.SeverityInfo,
+			Impact:   "无法判断目标进程能否继续创建线程，线程耗尽类问题可能不会被发现。",
+		}
+		return finding, models.Suggestion{}, nil, h.Unread
+	}
+
+	severity := models.SeverityInfo
+	if h.MinLeft <= 0 {
+		severity = models.SeverityError
+	}
+
+	desc := fmt.Sprintf("目标进程 PID=%d 当前线程数约为 %d。按 nproc、cgroup pids、kernel.threads-max 以及虚拟内存/栈尺寸四个维度估算，可额外创建线程数约为 %d，首个阻断因素为 %s。", pid, h.CurThreads, h.MinLeft, h.Reason)
+	descDetails := fmt.Sprintf("A(nproc) 剩余: %d\nB(cgroup pids) 剩余: %d (类型: %s)\nC(kernel.threads-max) 剩余: %d\nD(虚拟内存/栈) 剩余: %d", h.ALeft, h.BLeft, h.CgroupType, h.CLeft, h.DLeft)
+	if dims := unreadDimensions(h.Unread); len(dims) > 0 {
+		descDetails += "\n未评估的维度（数据不可读，按无限制估算）: " + strings.Join(dims, ", ")
+	}
+
+	finding := models.Finding{
+		ID:          threadHeadroomFindingID,
+		Title:       "线程创建余量评估",
+		Description: desc + "\n" + descDetails,
+		Severity:    severity,
+		Impact:      "当线程创建余量为 0 或负数时，目标进程后续创建线程将立即失败，可能表现为 OOM、资源暂时不可用或请求无法被处理。",
+	}
+
+	suggestion := buildThreadHeadroomSuggestion(h.Reason)
+	if h.MinLeft <= 0 {
+		suggestion.Actions = threadHeadroomActions(h)
+	}
+
+	return finding, suggestion, threadHeadroomMetrics(pid, h), h.Unread
+}
+
+// threadHeadroomDimensions 为线程创建余量估算的维度，与 maxproc_thread_headroom 指标的 dimension 标签一致。
+var threadHeadroomDimensions = []string{"nproc", "cgroup_pids", "threads_max", "vm_stack"}
+
+// threadHeadroomMetrics 将一次余量估算转换为指标；无限制的维度没有有意义的余量，不输出。
+func threadHeadroomMetrics(pid int, h threadHeadroom) []models.Metric {
+	p := strconv.Itoa(pid)
+	metrics := []models.Metric{{
+		Name:   "maxproc_threads",
+		Help:   "目标进程当前线程数",
+		Labels: map[string]string{"pid": p},
+		Value:  float64(h.CurThreads),
+	}}
+	for _, d := range []struct {
+		dimension string
+		left      int64
+	}{
+		{"nproc", h.ALeft},
+		{"cgroup_pids", h.BLeft},
+		{"threads_max", h.CLeft},
+		{"vm_stack", h.DLeft},
+	} {
+		if d.left == threadHeadroomUnlimited {
+			continue
+		}
+		metrics = append(metrics, models.Metric{
+			Name:   "maxproc_thread_headroom",
+			Help:   "目标进程按各维度估算的可额外创建线程数",
+			Labels: map[string]string{"pid": p, "dimension": d.dimension},
+			Value:  float64(d.left),
+		})
+	}
+	return metrics
+}
+
+// Headroom 描述目标进程线程创建余量的首个阻断因素，供全主机扫描等其他插件复用。
+type Headroom struct {
+	Threads int64
+	Left    int64
+	Reason  string
+	Used    int64
+	Limit   int64
+}
+
+// MeasureHeadroom 估算指定 PID 的线程创建余量，进程不存在或 /proc 不可访问时返回错误。
+func MeasureHeadroom(ctx context.Context, c *collectors.Collector, pid int) (Headroom, error) {
+	if _, err := c.Stat(collectors.ProcDir(pid)); err != nil {
+		return Headroom{}, err
+	}
+	h := measureThreadHeadroom(ctx, c, pid)
+	return Headroom{
+		Threads: h.CurThreads,
+		Left:    h.MinLeft,
+		Reason:  h.Reason,
+		Used:    h.Used,
+		Limit:   h.Limit,
+	}, nil
+}
+
+// threadHeadroom 保存一次线程创建余量估算的中间结果，供快照评估与趋势采样复用。
+type threadHeadroom struct {
+	CurThreads int64
+	CgroupType string
+	// CgroupDir 为 pids.max 所在的 cgroup 目录，未找到 pids 限制时为空。
+	CgroupDir string
+	// A/B/C/D 四个维度的剩余可创建线程数，无限制时为 threadHeadroomUnlimited。
+	ALeft, BLeft, CLeft, DLeft int64
+	// 四个维度中的最小剩余量及其对应的阻断因素。
+	MinLeft int64
+	Reason  string
+	// 阻断因素对应资源的当前用量与上限（虚拟内存/栈维度单位为 kB，其余为任务数）。
+	Used  int64
+	Limit int64
+	// Unread 为无法读取数据、按无限制估算的维度。
+	Unread []unreadDimension
+}
+
+// unreadDimension 表示余量估算中因数据不可读而未评估的一个维度。
+type unreadDimension struct {
+	// Dimension 与 maxproc_thread_headroom 指标的 dimension 标签一致，如 nproc。
+	Dimension string
+	Path      string
+	Err       error
+	// Requires 为读取该维度数据所需的权限。
+	Requires []string
+}
+
+// unreadDimensions 返回未评估维度的名称。
+func unreadDimensions(unread []unreadDimension) []string {
+	dims := make([]string, 0, len(unread))
+	for _, u := range unread {
+		dims = append(dims, u.Dimension)
+	}
+	return dims
+}
+
+// measureThreadHeadroom 采集一次 /proc 与 cgroup 数据并计算各维度的线程创建余量。
+func measureThreadHeadroom(ctx context.Context, c *collectors.Collector, pid int) threadHeadroom {
+	// 无法读取的维度按“无上限”参与估算以避免错误告警，同时记入 unread
+	var unread []unreadDimension
+	markUnread := func(name string, err error, requires []string, dimensions ...string) {
+		if err == nil {
+			return
+		}
+	next:
+		for _, d := range dimensions {
+			for _, u := range unread {
+				if u.Dimension == d {
+					continue next
+				}
+			}
+			unread = append(unread, unreadDimension{Dimension: d, Path: name, Err: err, Requires: requires})
+		}
+	}
+	ptrace := []string{"CAP_SYS_PTRACE"}
+	procDir := collectors.ProcDir(pid)
+
 	// 1. 当前线程数：统计 /proc/<pid>/task 条目数
-	curThreads := countThreadsOfProcess(procDir)
+	curThreads := c.TaskCount(pid)
+	if curThreads <= 0 {
+		_, err := c.ReadDirNames(path.Join(procDir, "task"))
+		markUnread(path.Join(procDir, "task"), err, ptrace, "nproc", "cgroup_pids")
+	}
 
 	// 2. /proc/<pid>/limits：Max processes、Max stack size、Max address space
-	maxProc, maxProcUnlimited, stackBytes, stackUnlimited, addrBytes, addrUnlimited := parseProcLimits(procDir)
+	limits, err := c.Limits(pid)
+	markUnread(path.Join(procDir, "limits"), err, ptrace, "nproc", "vm_stack")
+	maxProc := limits.Soft(collectors.LimitNproc)
+	stackBytes := limits.Soft(collectors.LimitStack)
+	addrBytes := limits.Soft(collectors.LimitAddressSpace)
 
 	// 3. /proc/<pid>/status：VmSize（kB）
-	vmSizeKB := readVmSizeKB(procDir)
+	status, err := c.Status(pid)
+	markUnread(path.Join(procDir, "status"), err, ptrace, "vm_stack")
+	vmSizeKB := status.VmSize
 
 	// 4. cgroup pids：v2 或 v1
-	cgInfo := readCgroupPidsInfo(pid)
+	cgPids := c.CgroupPids(pid)
+	markUnread(path.Join(cgPids.Dir, "pids.max"), cgPids.Err, nil, "cgroup_pids")
 
 	// 5. 系统级：threads-max 与系统当前线程数
-	kernelThreadsMax := readIntFromFile("/proc/sys/kernel/threads-max")
-	sysThreads := countSystemThreads(ctx)
+	kernelThreadsMax, err := c.SysctlInt("kernel.threads-max")
+	markUnread(collectors.SysctlPath("kernel.threads-max"), err, nil, "threads_max")
+	sysThreads := countSystemThreads(ctx, c)
+	if sysThreads <= 0 {
+		markUnread("/proc/loadavg", fmt.Errorf("cannot count system threads from /proc/loadavg or /proc/<pid>/task"), nil, "threads_max")
+	}
 
 	// 6. 逐项计算还能创建多少线程：A/B/C/D
 	// A: nproc 剩余
 	var aLeft int64
-	if maxProcUnlimited || curThreads <= 0 {
+	if maxProc == collectors.Unlimited || curThreads <= 0 {
 		aLeft = threadHeadroomUnlimited
 	} else {
 		aLeft = maxProc - curThreads
@@ -114,10 +292,10 @@ func evaluateThreadCreationHeadroom(ctx context.Context, pid int) (models.Findin
 
 	// B: cgroup 剩余（PIDS_MAX - PIDS_CUR + CUR_THREADS）
 	var bLeft int64
-	if cgInfo.PidsMaxUnlimited || cgInfo.Type == "none" || curThreads <= 0 {
+	if cgPids.Max == collectors.Unlimited || curThreads <= 0 {
 		bLeft = threadHeadroomUnlimited
 	} else {
-		bLeft = cgInfo.PidsMax - cgInfo.PidsCurrent + curThreads
+		bLeft = cgPids.Max - cgPids.Current + curThreads
 	}
 
 	// C: kernel threads-max 剩余（threads-max - SYS_THREADS）
@@ -130,7 +308,7 @@ func evaluateThreadCreationHeadroom(ctx context.Context, pid int) (models.Findin
 
 	// D: (MaxAddressSpace - VmSize) / StackSize
 	var dLeft int64
-	if addrUnlimited || stackUnlimited || stackBytes <= 0 || vmSizeKB <= 0 {
+	if addrBytes == collectors.Unlimited || stackBytes <= 0 || vmSizeKB <= 0 {
 		dLeft = threadHeadroomUnlimited
 	} else {
 		vmLimitKB := addrBytes / 1024
@@ -147,300 +325,113 @@ func evaluateThreadCreationHeadroom(ctx context.Context, pid int) (models.Findin
 		}
 	}
 
-	// 7. 取最小值及对应原因
-	minLeft := aLeft
-	reason := "nproc"
-
-	if bLeft < minLeft {
-		minLeft = bLeft
-		reason = "cgroup pids"
-	}
-	if cLeft < minLeft {
-		minLeft = cLeft
-		reason = "kernel threads-max"
-	}
-	if dLeft < minLeft {
-		minLeft = dLeft
-		reason = "virtual memory / stack"
+	h := threadHeadroom{
+		CurThreads: curThreads,
+		CgroupType: cgPids.Version,
+		CgroupDir:  cgPids.Dir,
+		ALeft:      aLeft,
+		BLeft:      bLeft,
+		CLeft:      cLeft,
+		DLeft:      dLeft,
+		Unread:     unread,
 	}
 
-	severity := models.SeverityInfo
-	if minLeft <= 0 {
-		severity = models.SeverityError
+	// 7. 取最小值及对应原因
+	h.MinLeft, h.Reason, h.Used, h.Limit = aLeft, "nproc", curThreads, max(maxProc, 0)
+	if bLeft < h.MinLeft {
+		h.MinLeft, h.Reason, h.Used, h.Limit = bLeft, "cgroup pids", cgPids.Current, cgPids.Max
 	}
-
-	desc := fmt.Sprintf("目标进程 PID=%d 当前线程数约为 %d。按 nproc、cgroup pids、kernel.threads-max 以及虚拟内存/栈尺寸四个维度估算，可额外创建线程数约为 %d，首个阻断因素为 %s。", pid, curThreads, minLeft, reason)
-	descDetails := fmt.Sprintf("A(nproc) 剩余: %d\nB(cgroup pids) 剩余: %d (类型: %s)\nC(kernel.threads-max) 剩余: %d\nD(虚拟内存/栈) 剩余: %d", aLeft, bLeft, cgInfo.Type, cLeft, dLeft)
-
-	finding := models.Finding{
-		ID:          threadHeadroomFindingID,
-		Title:       "线程创建余量评估",
-		Description: desc + "\n" + descDetails,
-		Severity:    severity,
-		Impact:      "当线程创建余量为 0 或负数时，目标进程后续创建线程将立即失败，可能表现为 OOM、资源暂时不可用或请求无法被处理。",
+	if cLeft < h.MinLeft {
+		h.MinLeft, h.Reason, h.Used, h.Limit = cLeft, "kernel threads-max", sysThreads, kernelThreadsMax
 	}
-
-	suggestion := buildThreadHeadroomSuggestion(reason)
-
-	return finding, suggestion
-}
-
-// countThreadsOfProcess 统计 /proc/<pid>/task 目录下的任务数量。
-func countThreadsOfProcess(procDir string) int64 {
-	taskDir := filepath.Join(procDir, "task")
-	entries, err := os.ReadDir(taskDir)
-	if err != nil {
-		return 0
+	if dLeft < h.MinLeft {
+		h.MinLeft, h.Reason, h.Used, h.Limit = dLeft, "virtual memory / stack", vmSizeKB, addrBytes/1024
 	}
-	return int64(len(entries))
-}
 
-// parseProcLimits 解析 /proc/<pid>/limits 中的关键限制项。
-func parseProcLimits(procDir string) (maxProc int64, maxProcUnlimited bool, stackBytes int64, stackUnlimited bool, addrBytes int64, addrUnlimited bool) {
-	path := filepath.Join(procDir, "limits")
-	data, err := os.ReadFile(path)
-	if err != nil {
-		// 无法读取时按“无上限”处理，以避免错误告警
-		maxProcUnlimited, stackUnlimited, addrUnlimited = true, true, true
-		return
-	}
-
-	lines := strings.Split(string(data), "\n")
-	var sawMaxProc, sawStack, sawAddr bool
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Limit") {
-			continue
-		}
-
-		fields := strings.Fields(line)
-		if len(fields) < 4 {
-			continue
-		}
-
-		// Soft/Hard/Unit 固定在行尾三列
-		soft := fields[len(fields)-3]
-
-		switch {
-		case strings.HasPrefix(line, "Max processes"):
-			sawMaxProc = true
-			if soft == "unlimited" {
-				maxProcUnlimited = true
-			} else if v, err := strconv.ParseInt(soft, 10, 64); err == nil {
-				maxProc = v
-			} else {
-				maxProcUnlimited = true
-			}
-		case strings.HasPrefix(line, "Max stack size"):
-			sawStack = true
-			if soft == "unlimited" {
-				stackUnlimited = true
-			} else if v, err := strconv.ParseInt(soft, 10, 64); err == nil {
-				stackBytes = v
-			} else {
-				stackUnlimited = true
-			}
-		case strings.HasPrefix(line, "Max address space"):
-			sawAddr = true
-			if soft == "unlimited" {
-				addrUnlimited = true
-			} else if v, err := strconv.ParseInt(soft, 10, 64); err == nil {
-				addrBytes = v
-			} else {
-				addrUnlimited = true
-			}
-		}
-	}
+	return h
+}
 
-	// 若未找到对应行，则按无限制处理
-	if !sawMaxProc {
-		maxProcUnlimited = true
-	}
-	if !sawStack {
-		stackUnlimited = true
+// countSystemThreads 统计系统当前任务（线程）总数，用于与 kernel.threads-max 比较。
+// 优先读取 /proc/loadavg 第 4 列 "running/total" 中的 total，该值即内核全局的 nr_threads，
+// 与 threads-max 的计数口径一致且不受 PID namespace 影响；读取失败时回退为并发遍历 /proc/<pid>/task。
+func countSystemThreads(ctx context.Context, c *collectors.Collector) int64 {
+	if l, err := c.Loadavg(); err == nil && l.Total > 0 {
+		return l.Total
 	}
-	if !sawAddr {
-		addrUnlimited = true
+	if n, err := countSystemThreadsByProc(ctx, c); err == nil && n > 0 {
+		return n
 	}
-
-	return
+	return 0
 }
 
-// readVmSizeKB 从 /proc/<pid>/status 中读取 VmSize（kB）。
-func readVmSizeKB(procDir string) int64 {
-	path := filepath.Join(procDir, "status")
-	f, err := os.Open(path)
+// countSystemThreadsByProc 并发遍历 /proc/<pid>/task 统计线程总数。
+// 在 PID namespace 内只能看到本 namespace 的任务，因此仅作为 /proc/loadavg 不可用时的回退手段。
+func countSystemThreadsByProc(ctx context.Context, c *collectors.Collector) (int64, error) {
+	all, err := c.PIDs()
 	if err != nil {
-		return 0
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(line, "VmSize:") {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				if v, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
-					return v
-				}
-			}
-			break
-		}
+		return 0, err
 	}
 
-	return 0
-}
-
-// cgroupPidsInfo 保存 pids cgroup 相关信息。
-type cgroupPidsInfo struct {
-	Type             string
-	PidsMax          int64
-	PidsMaxUnlimited bool
-	PidsCurrent      int64
-}
-
-// readCgroupPidsInfo 读取 cgroup v2 或 v1 的 pids.max 与 pids.current。
-func readCgroupPidsInfo(pid int) cgroupPidsInfo {
-	info := cgroupPidsInfo{
-		Type:             "none",
-		PidsMaxUnlimited: true, // 默认视为无限制
-	}
-
-	// cgroup v2：/sys/fs/cgroup/pids.max 存在
-	v2MaxPath := "/sys/fs/cgroup/pids.max"
-	if st, err := os.Stat(v2MaxPath); err == nil && !st.IsDir() {
-		info.Type = "v2"
-		info.PidsMax, info.PidsMaxUnlimited = readPidsLimitFile(v2MaxPath)
-		info.PidsCurrent = readIntFromFile("/sys/fs/cgroup/pids.current")
-		return info
-	}
-
-	// cgroup v1：根据 /proc/<pid>/cgroup 查找 pids 控制器路径
-	cgroupPath := ""
-	cgFile := fmt.Sprintf("/proc/%d/cgroup", pid)
-	if data, err := os.ReadFile(cgFile); err == nil {
-		lines := strings.Split(string(data), "\n")
-		for _, line := range lines {
-			if line == "" {
-				continue
-			}
-			parts := strings.SplitN(line, ":", 3)
-			if len(parts) != 3 {
-				continue
+	pids := make(chan int)
+	var (
+		total atomic.Int64
+		wg    sync.WaitGroup
+	)
+	workers := runtime.NumCPU()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pid := range pids {
+				total.Add(c.TaskCount(pid))
 			}
-			controllers := parts[1]
-			if strings.Contains(controllers, "pids") {
-				cgroupPath = parts[2]
-				break
-			}
-		}
+		}()
 	}
 
-	if cgroupPath != "" {
-		base := "/sys/fs/cgroup/pids"
-		maxPath := filepath.Join(base, cgroupPath, "pids.max")
-		curPath := filepath.Join(base, cgroupPath, "pids.current")
-		if st, err := os.Stat(maxPath); err == nil && !st.IsDir() {
-			info.Type = "v1"
-			info.PidsMax, info.PidsMaxUnlimited = readPidsLimitFile(maxPath)
-			info.PidsCurrent = readIntFromFile(curPath)
-			return info
+dispatch:
+	for _, pid := range all {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case pids <- pid:
 		}
 	}
+	close(pids)
+	wg.Wait()
 
-	return info
-}
-
-// readPidsLimitFile 解析 pids.max 文件，支持 "max"/"unlimited" 语义。
-func readPidsLimitFile(path string) (int64, bool) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return 0, true
-	}
-	s := strings.TrimSpace(string(data))
-	if s == "max" || s == "unlimited" {
-		return 0, true
-	}
-	v, err := strconv.ParseInt(s, 10, 64)
-	if err != nil {
-		return 0, true
-	}
-	return v, false
-}
-
-// readIntFromFile 从给定文件中读取 int64 数值，失败时返回 0。
-func readIntFromFile(path string) int64 {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return 0
-	}
-	s := strings.TrimSpace(string(data))
-	if s == "" {
-		return 0
-	}
-	v, err := strconv.ParseInt(s, 10, 64)
-	if err != nil {
-		return 0
-	}
-	return v
-}
-
-// countSystemThreads 优先使用 "ps -eLf | wc -l"，失败时回退为遍历 /proc/*/task 计数。
-func countSystemThreads(ctx context.Context) int64 {
-	if n, err := countSystemThreadsWithPs(ctx); err == nil && n > 0 {
-		return n
-	}
-	if n, err := countSystemThreadsByProc(); err == nil && n > 0 {
-		return n
-	}
-	return 0
-}
-
-func countSystemThreadsWithPs(ctx context.Context) (int64, error) {
-	cmd := exec.CommandContext(ctx, "ps", "-eLf")
-	out, err := cmd.Output()
-	if err != nil {
+	if err := ctx.Err(); err != nil {
 		return 0, err
 	}
-	if len(out) == 0 {
-		return 0, fmt.Errorf("ps 输出为空")
-	}
-	var lines int64
-	for _, b := range out {
-		if b == '\n' {
-			lines++
-		}
-	}
-	if lines == 0 {
-		return 0, fmt.Errorf("ps 输出没有有效行")
-	}
-	return lines, nil
+	return total.Load(), nil
 }
 
-func countSystemThreadsByProc() (int64, error) {
-	entries, err := os.ReadDir("/proc")
-	if err != nil {
-		return 0, err
-	}
-	var total int64
-	for _, e := range entries {
-		if !e.IsDir() {
-			continue
+// threadHeadroomActions 返回解除首个阻断因素的修复动作：nproc 提升到 655350，
+// cgroup pids.max 与 kernel.threads-max 提升到当前上限的两倍；虚拟内存/栈维度需要结合应用评估，不自动修复。
+func threadHeadroomActions(h threadHeadroom) []models.Action {
+	switch h.Reason {
+	case "nproc":
+		return []models.Action{
+			{Type: models.ActionLimitsDropIn, Key: "nproc", Value: "655350", Path: models.LimitsDropInFile},
 		}
-		name := e.Name()
-		if _, err := strconv.Atoi(name); err != nil {
-			continue
+	case "cgroup pids":
+		if h.CgroupDir == "" || h.Limit <= 0 {
+			return nil
 		}
-		taskDir := filepath.Join("/proc", name, "task")
-		threads, err := os.ReadDir(taskDir)
-		if err != nil {
-			continue
+		return []models.Action{
+			{Type: models.ActionCgroupPidsMax, Value: strconv.FormatInt(h.Limit*2, 10), Path: path.Join(h.CgroupDir, "pids.max")},
 		}
-		total += int64(len(threads))
+	case "kernel threads-max":
+		if h.Limit <= 0 {
+			return nil
+		}
+		value := strconv.FormatInt(h.Limit*2, 10)
+		return []models.Action{
+			{Type: models.ActionSysctl, Key: "kernel.threads-max", Value: value},
+			{Type: models.ActionSysctlDropIn, Key: "kernel.threads-max", Value: value, Path: models.SysctlDropInFile},
+		}
+	default:
+		return nil
 	}
-	return total, nil
 }
 
 // buildThreadHeadroomSuggestion 根据首个阻断因素生成对应的建议。
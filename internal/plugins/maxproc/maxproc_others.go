@@ -6,13 +6,14 @@ package maxproc
 import (
 	"context"
 
+	"github.com/supperghost/ossre/internal/core"
 	"github.com/supperghost/ossre/pkg/models"
 )
 
 // runMaxprocScenario 在非 Linux 平台上提供降级实现。
 // 该模块依赖 Linux 的 /proc 与 cgroup 语义，这里仅返回一条信息级别的 Finding，说明场景不适用。
-func runMaxprocScenario(ctx context.Context) ([]models.Finding, []models.Suggestion) {
-	_ = ctx
+func runMaxprocScenario(ctx context.Context, rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.Metric, []models.SkippedCheck) {
+	_, _ = ctx, rc
 
 	finding := models.Finding{
 		ID:          "maxproc.thread.headroom",
@@ -22,5 +23,5 @@ func runMaxprocScenario(ctx context.Context) ([]models.Finding, []models.Suggest
 		Impact:      "仅影响 maxproc 模块的线程创建余量诊断，其他插件与场景不受影响。",
 	}
 
-	return []models.Finding{finding}, nil
+	return []models.Finding{finding}, nil, nil, nil
 }
@@ -0,0 +1,160 @@
+//go:build linux
+// +build linux
+
+package maxproc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+const (
+	threadTrendFindingID = "maxproc.thread.trend"
+
+	defaultSampleInterval    = 5 * time.Second
+	defaultForecastThreshold = time.Hour
+)
+
+// headroomDimensions 为参与趋势预测的余量维度：采样键为 "headroom/<dimension>"，与 maxproc_thread_headroom 指标的
+// dimension 标签一致，reason 与快照评估中的阻断因素名称一致。
+var headroomDimensions = []struct {
+	dimension, reason string
+	left              func(threadHeadroom) int64
+}{
+	{"nproc", "nproc", func(h threadHeadroom) int64 { return h.ALeft }},
+	{"cgroup_pids", "cgroup pids", func(h threadHeadroom) int64 { return h.BLeft }},
+	{"threads_max", "kernel threads-max", func(h threadHeadroom) int64 { return h.CLeft }},
+	{"vm_stack", "virtual memory / stack", func(h threadHeadroom) int64 { return h.DLeft }},
+}
+
+// headroomSource 将一次线程创建余量估算转换为采样值："threads/current" 为当前线程数，"headroom/<dimension>"
+// 为各维度剩余量（已耗尽时为 0，无限制时不包含该键）。last 保存最近一次估算，供描述首个阻断因素；
+// 目标进程退出时调用 stop 结束采样。
+func headroomSource(ctx context.Context, pid int, last *threadHeadroom, stop func()) collectors.CounterSource {
+	return func(c *collectors.Collector) (collectors.Counters, error) {
+		h := measureThreadHeadroom(ctx, c, pid)
+		if h.CurThreads <= 0 {
+			// 目标进程已退出，后续样本无意义
+			stop()
+			return nil, fmt.Errorf("process %d has exited", pid)
+		}
+		*last = h
+		values := collectors.Counters{"threads/current": uint64(h.CurThreads)}
+		for _, d := range headroomDimensions {
+			if left := d.left(h); left < threadHeadroomUnlimited {
+				values["headroom/"+d.dimension] = uint64(max(left, 0))
+			}
+		}
+		return values, nil
+	}
+}
+
+// evaluateThreadHeadroomTrend 在给定窗口内周期性采样目标进程的线程数与各维度余量，
+// 通过最小二乘拟合增长速率，预测首个阻断因素的耗尽时间。
+func evaluateThreadHeadroomTrend(ctx context.Context, c *collectors.Collector, pid int, window, interval, threshold time.Duration) (models.Finding, models.Suggestion) {
+	sampleCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	var last threadHeadroom
+	samples, err := c.Sample(sampleCtx, interval, window, headroomSource(ctx, pid, &last, stop))
+
+	if err != nil || samples.Len() < 2 {
+		n := 0
+		if samples != nil {
+			n = samples.Len()
+		}
+		finding := models.Finding{
+			ID:          threadTrendFindingID,
+			Title:       "线程增长趋势评估：有效样本不足",
+			Description: fmt.Sprintf("在 %s 的采样窗口内仅获得 %d 个有效样本（采样间隔 %s），无法拟合线程增长速率。", window, n, interval),
+			Severity:    models.SeverityInfo,
+			Impact:      "仅影响耗尽时间预测，线程创建余量快照评估不受影响。",
+		}
+		return finding, models.Suggestion{}
+	}
+
+	threadRate, _ := samples.Slope("threads/current")
+	firstThreads, lastThreads, _ := samples.Endpoints("threads/current")
+
+	// 逐维度拟合剩余量的变化速率，取预计最早耗尽的维度；窗口内曾为无限制的维度不参与预测
+	eta := time.Duration(math.MaxInt64)
+	etaReason := ""
+	etaRate := 0.0
+	for _, d := range headroomDimensions {
+		key := "headroom/" + d.dimension
+		if samples.Gauge(key).Count != samples.Len() {
+			continue
+		}
+		rate, ok := samples.Slope(key)
+		if !ok {
+			continue
+		}
+		cur := d.left(last)
+		var dimETA time.Duration
+		switch {
+		case cur <= 0:
+			dimETA = 0
+		case rate < 0:
+			seconds := float64(cur) / -rate
+			if seconds >= math.MaxInt64/float64(time.Second) {
+				continue
+			}
+			dimETA = time.Duration(seconds * float64(time.Second))
+		default:
+			continue
+		}
+		if dimETA < eta {
+			eta, etaReason, etaRate = dimETA, d.reason, rate
+		}
+	}
+
+	desc := fmt.Sprintf("在 %s 内以 %s 间隔对目标进程 PID=%d 采集了 %d 个样本，线程数由 %d 变为 %d，拟合增长速率约为 %.2f 个/分钟。当前首个阻断因素为 %s，用量 %d / 上限 %d。",
+		samples.Elapsed().Round(time.Second), interval, pid, samples.Len(),
+		firstThreads, lastThreads, threadRate*60, last.Reason, last.Used, last.Limit)
+
+	if etaReason == "" {
+		finding := models.Finding{
+			ID:          threadTrendFindingID,
+			Title:       "线程增长趋势评估：未发现持续消耗",
+			Description: desc + "\n采样窗口内各受限维度的剩余量均未呈下降趋势，无法预测耗尽时间。",
+			Severity:    models.SeverityInfo,
+			Impact:      "当前负载下线程资源消耗稳定；若业务存在周期性波动，建议拉长采样窗口复核。",
+		}
+		return finding, models.Suggestion{}
+	}
+
+	severity := models.SeverityInfo
+	if eta < threshold {
+		severity = models.SeverityError
+	}
+
+	finding := models.Finding{
+		ID:    threadTrendFindingID,
+		Title: "线程增长趋势评估：预计耗尽时间",
+		Description: desc + fmt.Sprintf("\n按当前趋势，%s 维度剩余量以约 %.2f 个/分钟的速率下降，预计 %s 后耗尽（告警阈值 %s）。",
+			etaReason, -etaRate*60, eta.Round(time.Second), threshold),
+		Severity: severity,
+		Impact:   "若增长持续，目标进程将在预计时间点后无法创建新线程，表现为 'resource temporarily unavailable' 或请求堆积。",
+	}
+	if severity == models.SeverityInfo {
+		return finding, models.Suggestion{}
+	}
+
+	limitSuggestion := buildThreadHeadroomSuggestion(etaReason)
+	suggestion := models.Suggestion{
+		FindingID: threadTrendFindingID,
+		Title:     "排查线程泄漏并为首个耗尽维度预留余量",
+		Details: "检测到线程资源预计将在告警阈值内耗尽。" +
+			"\n\n" +
+			"1. 确认是否存在线程泄漏：\n" +
+			fmt.Sprintf("   watch -n 5 'ls /proc/%d/task | wc -l'\n", pid) +
+			"   Java 进程可使用 jstack，其他进程可使用 gdb/pstack 导出线程栈，按线程名或栈顶函数聚合定位泄漏来源。\n\n" +
+			"2. 在根因修复前，可按以下方式临时扩大首个耗尽维度的上限：\n\n" +
+			limitSuggestion.Details,
+	}
+	return finding, suggestion
+}
@@ -18,6 +18,13 @@ func New() core.Plugin {
 	return &Plugin{}
 }
 
+func init() {
+	core.Register(New, core.Metadata{
+		Version: "0.1.0",
+		Tags:    []string{"network"},
+	})
+}
+
 func (p *Plugin) Name() string {
 	return PluginName
 }
@@ -26,7 +33,7 @@ func (p *Plugin) Description() string {
 	return "网络连通性与性能诊断（占位实现）"
 }
 
-func (p *Plugin) Run(ctx context.Context) (models.Result, error) {
+func (p *Plugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
 	_ = ctx
 	// TODO: 实现对网络连通性、带宽、丢包等指标的采集与诊断。
 	return models.Result{
@@ -0,0 +1,346 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// 规则支持的解析方式。
+const (
+	ParserString   = "string"   // 去掉首尾空白后的整个文件内容
+	ParserInt      = "int"      // 整数
+	ParserFields   = "fields"   // 按空白拆分后的第 field 个字段（从 0 开始）
+	ParserKeyValue = "keyvalue" // "Key: value"、"Key value" 或 "key=value" 形式的行中 key 对应值的第一个字段
+	ParserSelected = "selected" // "always [madvise] never" 形式中方括号标出的当前选项
+)
+
+// 规则支持的比较方式，规则描述的是期望状态，比较不成立时产生发现。
+var comparators = map[string]bool{
+	"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true,
+	"contains": true, "not_contains": true, "matches": true, "in": true, "not_in": true,
+}
+
+var ruleIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// Rule 为一条声明式检查规则：读取 Source 文件（或 sysctl 参数 Sysctl），按 Parser 解析出取值，
+// 与 Expected 按 Op 比较，不满足时产生 ID 为案例 ID 的发现。
+type Rule struct {
+	ID          string
+	Title       string
+	Description string
+	Impact      string
+	Severity    models.Severity
+	// Source 为宿主视角的绝对路径，与 Sysctl 二选一。
+	Source string
+	Sysctl string
+	Parser string
+	Field  int
+	Key    string
+	Op     string
+	// Expected 为期望值，in/not_in 时可有多个。
+	Expected   []string
+	ConfigFile string
+	// SuggestionTitle 与 SuggestionDetails 为建议模板，可使用 {{value}}、{{expected}}、{{op}}、{{source}}、{{id}} 占位符。
+	SuggestionTitle   string
+	SuggestionDetails string
+	// File 为规则所在的文件。
+	File string
+
+	pattern *regexp.Regexp
+}
+
+// path 返回规则读取的宿主视角路径。
+func (r *Rule) path() string {
+	if r.Sysctl != "" {
+		return collectors.SysctlPath(r.Sysctl)
+	}
+	return r.Source
+}
+
+// LoadDirs 按文件名顺序加载 dirs 下的全部 *.yaml 与 *.yml 规则文件，不存在的目录被忽略。
+// 单个文件无效时记录到 errs 并跳过该文件，其余文件照常加载；规则 ID 在全部文件中必须唯一。
+func LoadDirs(dirs []string) (rules []*Rule, errs []error) {
+	seen := make(map[string]string)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		var names []string
+		for _, e := range entries {
+			if ext := filepath.Ext(e.Name()); !e.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				names = append(names, e.Name())
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			file := filepath.Join(dir, name)
+			loaded, err := LoadFile(file)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for _, r := range loaded {
+				if prev, dup := seen[r.ID]; dup {
+					errs = append(errs, fmt.Errorf("%s: rule %q already defined in %s", file, r.ID, prev))
+					continue
+				}
+				seen[r.ID] = file
+				rules = append(rules, r)
+			}
+		}
+	}
+	return rules, errs
+}
+
+// LoadFile 加载一个规则文件，文件的顶层为 rules 序列。
+func LoadFile(file string) ([]*Rule, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := config.ParseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	root, ok := tree.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: top level must be a mapping with a rules list", file)
+	}
+	list, ok := root["rules"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: rules must be a list", file)
+	}
+	rules := make([]*Rule, 0, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: rules[%d] must be a mapping", file, i)
+		}
+		r, err := parseRule(m)
+		if err != nil {
+			return nil, fmt.Errorf("%s: rules[%d]: %w", file, i, err)
+		}
+		r.File = file
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func parseRule(m map[string]any) (*Rule, error) {
+	str := func(key string) string {
+		s, _ := m[key].(string)
+		return s
+	}
+	r := &Rule{
+		ID:          str("id"),
+		Title:       str("title"),
+		Description: str("description"),
+		Impact:      str("impact"),
+		Severity:    models.Severity(str("severity")),
+		Source:      str("source"),
+		Sysctl:      str("sysctl"),
+		Parser:      str("parser"),
+		Key:         str("key"),
+		Op:          str("op"),
+		ConfigFile:  str("config_file"),
+	}
+	if !ruleIDPattern.MatchString(r.ID) {
+		return nil, fmt.Errorf("invalid id %q, want a dotted case ID such as site.vm.swappiness", r.ID)
+	}
+	if r.Title == "" {
+		return nil, fmt.Errorf("%s: title is required", r.ID)
+	}
+	switch r.Severity {
+	case "":
+		r.Severity = models.SeverityWarning
+	case models.SeverityInfo, models.SeverityWarning, models.SeverityError, models.SeverityCritical:
+	default:
+		return nil, fmt.Errorf("%s: unknown severity %q", r.ID, r.Severity)
+	}
+	if (r.Source == "") == (r.Sysctl == "") {
+		return nil, fmt.Errorf("%s: exactly one of source and sysctl is required", r.ID)
+	}
+	if r.Source != "" && !filepath.IsAbs(r.Source) {
+		return nil, fmt.Errorf("%s: source must be an absolute path", r.ID)
+	}
+
+	switch r.Parser {
+	case "":
+		r.Parser = ParserString
+	case ParserString, ParserInt, ParserSelected:
+	case ParserFields:
+		n, err := strconv.Atoi(str("field"))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s: fields parser requires a non-negative field index", r.ID)
+		}
+		r.Field = n
+	case ParserKeyValue:
+		if r.Key == "" {
+			return nil, fmt.Errorf("%s: keyvalue parser requires key", r.ID)
+		}
+	default:
+		return nil, fmt.Errorf("%s: unknown parser %q", r.ID, r.Parser)
+	}
+
+	if !comparators[r.Op] {
+		return nil, fmt.Errorf("%s: unknown op %q", r.ID, r.Op)
+	}
+	switch v := m["expected"].(type) {
+	case string:
+		r.Expected = []string{v}
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: expected list must contain scalars", r.ID)
+			}
+			r.Expected = append(r.Expected, s)
+		}
+	}
+	if len(r.Expected) == 0 {
+		return nil, fmt.Errorf("%s: expected is required", r.ID)
+	}
+	if len(r.Expected) > 1 && r.Op != "in" && r.Op != "not_in" {
+		return nil, fmt.Errorf("%s: op %s takes a single expected value", r.ID, r.Op)
+	}
+	if r.Op == "matches" {
+		re, err := regexp.Compile(r.Expected[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.ID, err)
+		}
+		r.pattern = re
+	}
+
+	if s, ok := m["suggestion"].(map[string]any); ok {
+		r.SuggestionTitle, _ = s["title"].(string)
+		r.SuggestionDetails, _ = s["details"].(string)
+	}
+	return r, nil
+}
+
+// parse 按规则的解析方式从文件内容中取出待比较的值。
+func (r *Rule) parse(data []byte) (string, error) {
+	content := strings.TrimSpace(string(data))
+	switch r.Parser {
+	case ParserInt:
+		if _, err := strconv.ParseInt(content, 10, 64); err != nil {
+			return "", fmt.Errorf("not an integer: %q", content)
+		}
+		return content, nil
+	case ParserFields:
+		fields := strings.Fields(content)
+		if r.Field >= len(fields) {
+			return "", fmt.Errorf("field %d out of range, content has %d fields", r.Field, len(fields))
+		}
+		return fields[r.Field], nil
+	case ParserKeyValue:
+		for _, line := range strings.Split(content, "\n") {
+			k, v, ok := strings.Cut(line, ":")
+			if !ok {
+				k, v, ok = strings.Cut(line, "=")
+			}
+			if !ok {
+				k, v, _ = strings.Cut(strings.TrimSpace(line), " ")
+			}
+			if strings.TrimSpace(k) != r.Key {
+				continue
+			}
+			if fields := strings.Fields(v); len(fields) > 0 {
+				return fields[0], nil
+			}
+			return "", nil
+		}
+		return "", fmt.Errorf("key %q not found", r.Key)
+	case ParserSelected:
+		for _, f := range strings.Fields(content) {
+			if strings.HasPrefix(f, "[") && strings.HasSuffix(f, "]") {
+				return strings.Trim(f, "[]"), nil
+			}
+		}
+		return "", fmt.Errorf("no [selected] option in %q", content)
+	default:
+		return content, nil
+	}
+}
+
+// evaluate 解析数据源内容并判断是否满足期望状态，返回解析出的取值。
+func (r *Rule) evaluate(data []byte) (value string, ok bool, err error) {
+	if value, err = r.parse(data); err != nil {
+		return "", false, err
+	}
+	ok, err = r.satisfied(value)
+	return value, ok, err
+}
+
+// satisfied 判断取值是否满足规则的期望状态。两侧均为数字时按数值比较，否则按字符串比较；
+// 大小比较要求两侧均为数字。
+func (r *Rule) satisfied(value string) (bool, error) {
+	expected := r.Expected[0]
+	v, vErr := strconv.ParseFloat(value, 64)
+	e, eErr := strconv.ParseFloat(expected, 64)
+	numeric := vErr == nil && eErr == nil
+
+	switch r.Op {
+	case "==":
+		return numeric && v == e || !numeric && value == expected, nil
+	case "!=":
+		return numeric && v != e || !numeric && value != expected, nil
+	case "<", "<=", ">", ">=":
+		if !numeric {
+			return false, fmt.Errorf("op %s needs numeric values, got %q and %q", r.Op, value, expected)
+		}
+		switch r.Op {
+		case "<":
+			return v < e, nil
+		case "<=":
+			return v <= e, nil
+		case ">":
+			return v > e, nil
+		default:
+			return v >= e, nil
+		}
+	case "contains":
+		return strings.Contains(value, expected), nil
+	case "not_contains":
+		return !strings.Contains(value, expected), nil
+	case "matches":
+		return r.pattern.MatchString(value), nil
+	case "in", "not_in":
+		found := false
+		for _, x := range r.Expected {
+			found = found || x == value
+		}
+		return found == (r.Op == "in"), nil
+	default:
+		return false, fmt.Errorf("unknown op %q", r.Op)
+	}
+}
+
+// expand 替换模板中的占位符。
+func (r *Rule) expand(tmpl, value string) string {
+	source := r.Sysctl
+	if source == "" {
+		source = r.Source
+	}
+	return strings.NewReplacer(
+		"{{value}}", value,
+		"{{expected}}", strings.Join(r.Expected, ", "),
+		"{{op}}", r.Op,
+		"{{source}}", source,
+		"{{id}}", r.ID,
+	).Replace(tmpl)
+}
@@ -0,0 +1,119 @@
+// Package rules 实现声明式检查规则插件：从规则目录加载 YAML 规则，读取 /proc、/sys 文件或 sysctl 参数，
+// 比较取值并产生普通的发现与建议，无需编写 Go 代码即可增加站点自定义的检查。
+package rules
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// PluginName 是规则插件的名称常量。
+const PluginName = "rules"
+
+// loadFindingID 为规则文件无效时发现的案例 ID。
+const loadFindingID = "rules.load.invalid"
+
+// Plugin 实现了 core.Plugin 接口，执行规则目录中的声明式检查规则。
+type Plugin struct{}
+
+// New 创建一个新的规则插件实例，规则目录由配置中的 rules.dirs 指定。
+func New() core.Plugin {
+	return &Plugin{}
+}
+
+func init() {
+	core.Register(New, core.Metadata{
+		Version: "1.0.0",
+		Tags:    []string{"custom"},
+		// 规则中的案例 ID 由站点自行定义
+		CaseIDs: []string{"rules.load.invalid"},
+	})
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Description() string {
+	return "声明式检查规则（从规则目录加载 YAML 规则）"
+}
+
+// Run 加载规则并逐条评估。无效的规则文件产生 error 级别的发现，不影响其他规则；
+// 读取不到数据源的规则被跳过，取值无法比较的规则记录警告日志。
+func (p *Plugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	result := models.Result{Plugin: PluginName}
+
+	rules, errs := LoadDirs(rc.Config.Rules.Dirs)
+	for _, err := range errs {
+		result.Findings = append(result.Findings, models.Finding{
+			ID:          loadFindingID,
+			Title:       "规则文件无效，其中的规则未执行",
+			Description: err.Error(),
+			Severity:    models.SeverityError,
+			Impact:      "该文件中的全部检查都没有执行，对应的问题不会被发现。",
+		})
+	}
+	if len(rules) == 0 {
+		rc.Logger.Debug("no rules loaded", "dirs", rc.Config.Rules.Dirs)
+	}
+
+	for _, r := range rules {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		data, err := rc.Collector.ReadFile(r.path())
+		// 数据源不可读或无法求值的规则记为未评估，不能当作检查通过
+		if err != nil {
+			result.Skipped = append(result.Skipped, rc.Skip(r.ID, r.path(), err))
+			continue
+		}
+		value, ok, err := r.evaluate(data)
+		if err != nil {
+			rc.Logger.Warn("rule evaluation failed", "rule", r.ID, "file", r.File, "error", err)
+			result.Skipped = append(result.Skipped, rc.Skip(r.ID, r.path(), err))
+			continue
+		}
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			result.Metrics = append(result.Metrics, models.Metric{
+				Name:   "rules_value",
+				Help:   "规则数据源的当前取值（仅数值）",
+				Labels: map[string]string{"rule": r.ID},
+				Value:  v,
+			})
+		}
+		if ok {
+			continue
+		}
+		f, s := r.finding(value)
+		result.Findings = append(result.Findings, f)
+		if s.Title != "" || s.Details != "" {
+			result.Suggestions = append(result.Suggestions, s)
+		}
+	}
+	return result, nil
+}
+
+// finding 根据规则与实际取值生成发现与建议，未配置描述时使用默认描述。
+func (r *Rule) finding(value string) (models.Finding, models.Suggestion) {
+	desc := r.Description
+	if desc == "" {
+		desc = "{{source}} 当前值为 {{value}}，期望 {{op}} {{expected}}。"
+	}
+	f := models.Finding{
+		ID:          r.ID,
+		Title:       r.expand(r.Title, value),
+		Description: r.expand(desc, value),
+		Severity:    r.Severity,
+		Impact:      r.expand(r.Impact, value),
+		ConfigFile:  r.ConfigFile,
+	}
+	s := models.Suggestion{
+		FindingID: r.ID,
+		Title:     r.expand(r.SuggestionTitle, value),
+		Details:   r.expand(r.SuggestionDetails, value),
+	}
+	return f, s
+}
@@ -0,0 +1,54 @@
+package scan
+
+import (
+	"context"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// PluginName 是全主机扫描插件的名称常量。
+const PluginName = "scan"
+
+// Plugin 实现了 core.Plugin 接口，用于逐进程评估线程、文件描述符与内存余量并排序。
+type Plugin struct{}
+
+// New 创建一个新的全主机扫描插件实例。
+func New() core.Plugin {
+	return &Plugin{}
+}
+
+func init() {
+	core.Register(New, core.Metadata{
+		Version:    "1.0.0",
+		Tags:       []string{"capacity", "process"},
+		OS:         []string{"linux"},
+		MinKernel:  "2.6.24",
+		Privileges: []string{"CAP_SYS_PTRACE"},
+		Scenarios:  []string{"scan.host.ranking"},
+		CaseIDs:    []string{"scan.host.ranking"},
+	})
+}
+
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+func (p *Plugin) Description() string {
+	return "全主机进程扫描，按线程/文件描述符/内存余量排序找出最接近耗尽的进程（Linux 专属）"
+}
+
+// Run 调用平台相关的场景实现，返回诊断结果。
+func (p *Plugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	findings, suggestions, skipped, err := runHostScanScenario(ctx, rc)
+	if err != nil {
+		return models.Result{}, err
+	}
+
+	return models.Result{
+		Plugin:      PluginName,
+		Findings:    findings,
+		Suggestions: suggestions,
+		Skipped:     skipped,
+	}, nil
+}
@@ -0,0 +1,387 @@
+//go:build linux
+// +build linux
+
+package scan
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/plugins/maxfd"
+	"github.com/supperghost/ossre/internal/plugins/maxproc"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+const (
+	rankingFindingID = "scan.host.ranking"
+	defaultTop       = 10
+
+	// 用量达到上限的该比例时视为“接近耗尽”
+	pressureWarnRatio = 0.9
+)
+
+// hostWideReasons 中的阻断因素对所有进程相同，不逐进程上报，避免重复告警。
+var hostWideReasons = map[string]bool{
+	"kernel threads-max": true,
+	"fs.file-max":        true,
+}
+
+// processPressure 保存单个进程在三个维度上的余量评估结果。
+type processPressure struct {
+	PID     int
+	Comm    string
+	UID     int
+	Threads maxproc.Headroom
+	Fds     maxfd.Headroom
+	Memory  memoryHeadroom
+	// threadsOK/fdsOK 表示对应维度是否评估成功（fd 目录可能无权读取）。
+	threadsOK, fdsOK bool
+	// fdsErr 为 fd 维度评估失败的原因。
+	fdsErr error
+
+	Worst    float64
+	WorstDim string
+}
+
+// memoryHeadroom 表示进程内存维度的首个阻断因素。
+type memoryHeadroom struct {
+	Reason string
+	Used   int64
+	Limit  int64
+}
+
+// runHostScanScenario 实现“全主机进程余量扫描”场景。
+// 通过有界 worker pool 并发评估所有匹配的进程，按最接近耗尽的维度排序输出；
+// 因权限不足无法评估 fd 维度的进程汇总为一条未评估记录。
+func runHostScanScenario(ctx context.Context, rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.SkippedCheck, error) {
+	expr := rc.Target.PIDSelector
+	c := rc.Collector
+	sel, err := ParseSelector(c, expr)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	workers := rc.Config.Scan.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	top := rc.Config.Scan.Top
+	if top <= 0 {
+		top = defaultTop
+	}
+	rc.Logger.Debug("host scan started", "selector", expr, "workers", workers)
+
+	pids, err := c.PIDs()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("list /proc: %w", err)
+	}
+
+	results := scanProcesses(ctx, c, pids, sel, workers)
+	if err := ctx.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Worst != results[j].Worst {
+			return results[i].Worst > results[j].Worst
+		}
+		return results[i].PID < results[j].PID
+	})
+
+	findings, suggestions := buildScanFindings(results, top, expr)
+	return findings, suggestions, deniedFdSkips(rc, results), nil
+}
+
+// deniedFdSkips 将 fd 目录因权限不足不可读的进程汇总为一条未评估记录，路径为按 PID 排序后的首个进程。
+func deniedFdSkips(rc *core.RunContext, results []processPressure) []models.SkippedCheck {
+	var denied []processPressure
+	for _, p := range results {
+		if collectors.IsPermission(p.fdsErr) {
+			denied = append(denied, p)
+		}
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+	sort.Slice(denied, func(i, j int) bool { return denied[i].PID < denied[j].PID })
+	first := denied[0]
+	s := rc.Skip(rankingFindingID+".fds", path.Join(collectors.ProcDir(first.PID), "fd"), first.fdsErr, "CAP_SYS_PTRACE")
+	s.Reason = fmt.Sprintf("%d 个进程的 fd 维度未评估，排名中显示为 -；首个进程 PID=%d: %s", len(denied), first.PID, s.Reason)
+	return []models.SkippedCheck{s}
+}
+
+// scanProcesses 以 workers 个并发评估所有匹配筛选条件的进程。
+func scanProcesses(ctx context.Context, c *collectors.Collector, pids []int, sel Selector, workers int) []processPressure {
+	jobs := make(chan int)
+	var (
+		mu      sync.Mutex
+		results []processPressure
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pid := range jobs {
+				p, ok := evaluateProcess(ctx, c, pid, sel)
+				if !ok {
+					continue
+				}
+				mu.Lock()
+				results = append(results, p)
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for _, pid := range pids {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- pid:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// evaluateProcess 对单个进程进行筛选与三维度余量评估；进程不匹配或已退出时返回 false。
+func evaluateProcess(ctx context.Context, c *collectors.Collector, pid int, sel Selector) (processPressure, bool) {
+	status, err := c.Status(pid)
+	if err != nil || status.UID < 0 {
+		return processPressure{}, false
+	}
+	comm, uid := status.Name, status.UID
+	cgroups, _ := c.Cgroups(pid)
+	if !sel.Match(comm, uid, cgroups.Paths()) {
+		return processPressure{}, false
+	}
+	// 内核线程没有用户态地址空间，不参与排名
+	if status.VmSize <= 0 {
+		return processPressure{}, false
+	}
+
+	p := processPressure{PID: pid, Comm: comm, UID: uid}
+	if h, err := maxproc.MeasureHeadroom(ctx, c, pid); err == nil && h.Threads > 0 {
+		p.Threads, p.threadsOK = h, true
+	}
+	if h, err := maxfd.MeasureHeadroom(c, pid); err == nil {
+		p.Fds, p.fdsOK = h, true
+	} else {
+		p.fdsErr = err
+	}
+	p.Memory = measureMemoryHeadroom(c, pid, status)
+
+	consider := func(dim string, used, limit int64) {
+		if r := ratio(used, limit); r > p.Worst || p.WorstDim == "" {
+			p.Worst, p.WorstDim = r, dim
+		}
+	}
+	if p.threadsOK {
+		consider("threads", p.Threads.Used, p.Threads.Limit)
+	}
+	if p.fdsOK {
+		consider("fds", p.Fds.Used, p.Fds.Limit)
+	}
+	consider("memory", p.Memory.Used, p.Memory.Limit)
+
+	return p, true
+}
+
+// measureMemoryHeadroom 比较 cgroup 内存上限与 Max address space，取用量占比更高者。
+func measureMemoryHeadroom(c *collectors.Collector, pid int, status collectors.ProcStatus) memoryHeadroom {
+	var best memoryHeadroom
+
+	consider := func(h memoryHeadroom) {
+		if h.Limit > 0 && (best.Limit <= 0 || ratio(h.Used, h.Limit) > ratio(best.Used, best.Limit)) {
+			best = h
+		}
+	}
+
+	// cgroup：v2 为 memory.max，v1 为 memory.limit_in_bytes
+	if m := c.CgroupMemory(pid); m.Limit != collectors.Unlimited {
+		reason := "cgroup memory.max"
+		if m.Version == "v1" {
+			reason = "cgroup memory.limit_in_bytes"
+		}
+		consider(memoryHeadroom{Reason: reason, Used: m.Usage, Limit: m.Limit})
+	}
+	// 进程级：Max address space 与 VmSize
+	if limits, err := c.Limits(pid); err == nil {
+		if limit := limits.Soft(collectors.LimitAddressSpace); limit != collectors.Unlimited {
+			consider(memoryHeadroom{
+				Reason: "address space",
+				Used:   status.VmSize * 1024,
+				Limit:  limit,
+			})
+		}
+	}
+
+	return best
+}
+
+// buildScanFindings 生成排名表 Finding 以及接近耗尽进程的逐项 Finding。
+func buildScanFindings(results []processPressure, top int, expr string) ([]models.Finding, []models.Suggestion) {
+	var (
+		findings    []models.Finding
+		suggestions []models.Suggestion
+	)
+
+	scope := "全部进程"
+	if expr != "" {
+		scope = fmt.Sprintf("匹配 %q 的进程", expr)
+	}
+	if len(results) == 0 {
+		findings = append(findings, models.Finding{
+			ID:          rankingFindingID,
+			Title:       "全主机进程余量扫描：没有匹配的进程",
+			Description: fmt.Sprintf("扫描范围为%s，未找到可评估的用户态进程。", scope),
+			Severity:    models.SeverityInfo,
+		})
+		return findings, suggestions
+	}
+
+	shown := results
+	if len(shown) > top {
+		shown = shown[:top]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "扫描范围为%s，共评估 %d 个进程，按最接近耗尽的维度排序，前 %d 名如下：\n", scope, len(results), len(shown))
+	fmt.Fprintf(&b, "%-4s %-8s %-16s %-6s %-26s %-26s %-30s %s\n", "#", "PID", "COMM", "UID", "THREADS(used/limit)", "FDS(used/limit)", "MEMORY(used/limit)", "WORST")
+	for i, p := range shown {
+		threads, fds, memory := "-", "-", "unlimited"
+		if p.threadsOK {
+			threads = formatUsage(p.Threads.Used, p.Threads.Limit)
+		}
+		if p.fdsOK {
+			fds = formatUsage(p.Fds.Used, p.Fds.Limit)
+		}
+		if p.Memory.Limit > 0 {
+			memory = formatUsage(p.Memory.Used, p.Memory.Limit)
+		}
+		fmt.Fprintf(&b, "%-4d %-8d %-16s %-6d %-26s %-26s %-30s %s %.1f%%\n",
+			i+1, p.PID, p.Comm, p.UID, threads, fds, memory, p.WorstDim, p.Worst*100)
+	}
+
+	severity := models.SeverityInfo
+	hostWide := map[string]hostWideUsage{}
+
+	for _, p := range results {
+		type dimension struct {
+			name, reason string
+			used, limit  int64
+			left         int64
+			ok           bool
+		}
+		dims := []dimension{
+			{"threads", p.Threads.Reason, p.Threads.Used, p.Threads.Limit, p.Threads.Left, p.threadsOK},
+			{"fds", p.Fds.Reason, p.Fds.Used, p.Fds.Limit, p.Fds.Left, p.fdsOK},
+			{"memory", p.Memory.Reason, p.Memory.Used, p.Memory.Limit, p.Memory.Limit - p.Memory.Used, p.Memory.Limit > 0},
+		}
+		for _, d := range dims {
+			if !d.ok {
+				continue
+			}
+			r := ratio(d.used, d.limit)
+			exhausted := d.left <= 0
+			if r < pressureWarnRatio && !exhausted {
+				continue
+			}
+			sev := models.SeverityWarning
+			if exhausted {
+				sev = models.SeverityError
+			}
+			if sev.Rank() > severity.Rank() {
+				severity = sev
+			}
+			if hostWideReasons[d.reason] {
+				hostWide[d.reason] = hostWideUsage{used: d.used, limit: d.limit, severity: sev}
+				continue
+			}
+
+			id := fmt.Sprintf("scan.host.%s.pid_%d", d.name, p.PID)
+			findings = append(findings, models.Finding{
+				ID:    id,
+				Title: fmt.Sprintf("进程 %s (PID=%d) 的 %s 余量接近耗尽", p.Comm, p.PID, d.name),
+				Description: fmt.Sprintf("首个阻断因素为 %s，用量 %d / 上限 %d（%.1f%%），剩余 %d。",
+					d.reason, d.used, d.limit, r*100, d.left),
+				Severity: sev,
+				Impact:   "该进程继续增长时将很快因资源耗尽而无法创建线程、打开文件或分配内存。",
+			})
+			suggestions = append(suggestions, models.Suggestion{
+				FindingID: id,
+				Title:     fmt.Sprintf("对 PID=%d 运行单进程诊断获取详细建议", p.PID),
+				Details:   scanFollowUp(d.name, p.PID),
+			})
+		}
+	}
+
+	reasons := make([]string, 0, len(hostWide))
+	for reason := range hostWide {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		h := hostWide[reason]
+		id := "scan.host.system." + strings.NewReplacer(" ", "_", ".", "_", "-", "_").Replace(reason)
+		findings = append(findings, models.Finding{
+			ID:          id,
+			Title:       fmt.Sprintf("系统级限制 %s 接近耗尽", reason),
+			Description: fmt.Sprintf("系统级限制 %s 当前用量 %d / 上限 %d（%.1f%%），影响主机上所有进程。", reason, h.used, h.limit, ratio(h.used, h.limit)*100),
+			Severity:    h.severity,
+			Impact:      "系统级限制耗尽时，主机上任意进程都可能无法创建线程或打开文件。",
+		})
+	}
+
+	ranking := models.Finding{
+		ID:          rankingFindingID,
+		Title:       "全主机进程余量扫描排名",
+		Description: strings.TrimRight(b.String(), "\n"),
+		Severity:    severity,
+		Impact:      "排名靠前的进程最可能率先因线程、文件描述符或内存限制而失败。",
+	}
+	return append([]models.Finding{ranking}, findings...), suggestions
+}
+
+// hostWideUsage 记录系统级限制的用量，用于合并为单条 Finding。
+type hostWideUsage struct {
+	used, limit int64
+	severity    models.Severity
+}
+
+// scanFollowUp 返回针对某个维度的单进程诊断命令。
+func scanFollowUp(dim string, pid int) string {
+	switch dim {
+	case "threads":
+		return fmt.Sprintf("执行以下命令查看线程余量各维度明细与调整建议：\n  ossre run --module=maxproc --pid=%d --format=plain\n如需确认是否持续增长，可追加 --sample-window=2m。", pid)
+	case "fds":
+		return fmt.Sprintf("执行以下命令查看文件描述符余量、fd 类型分布与调整建议：\n  ossre run --module=maxfd --pid=%d --format=plain\n如需确认是否持续增长，可追加 --sample-window=1m。", pid)
+	default:
+		return fmt.Sprintf("检查进程内存占用与所在 cgroup 的内存上限：\n  grep -E 'VmSize|VmRSS' /proc/%d/status\n  cat /proc/%d/cgroup\n必要时提升 cgroup memory.max（或容器内存 limit），或排查应用内存泄漏。", pid, pid)
+	}
+}
+
+// ratio 返回 used/limit，limit 无效时返回 0。
+func ratio(used, limit int64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return float64(used) / float64(limit)
+}
+
+func formatUsage(used, limit int64) string {
+	if limit <= 0 {
+		return fmt.Sprintf("%d/unlimited", used)
+	}
+	return fmt.Sprintf("%d/%d(%.0f%%)", used, limit, ratio(used, limit)*100)
+}
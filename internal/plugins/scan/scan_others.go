@@ -0,0 +1,26 @@
+//go:build !linux
+// +build !linux
+
+package scan
+
+import (
+	"context"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// runHostScanScenario 在非 Linux 平台上提供降级实现。
+func runHostScanScenario(ctx context.Context, rc *core.RunContext) ([]models.Finding, []models.Suggestion, []models.SkippedCheck, error) {
+	_, _ = ctx, rc
+
+	finding := models.Finding{
+		ID:          "scan.host.ranking",
+		Title:       "全主机进程扫描场景当前操作系统不支持",
+		Description: "scan 模块依赖 Linux 的 /proc 与 cgroup 接口，仅在 Linux 上可用；当前操作系统不支持逐进程余量评估。",
+		Severity:    models.SeverityInfo,
+		Impact:      "仅影响 scan 模块，其他插件与场景不受影响。",
+	}
+
+	return []models.Finding{finding}, nil, nil, nil
+}
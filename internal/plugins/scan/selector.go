@@ -0,0 +1,113 @@
+package scan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/supperghost/ossre/internal/collectors"
+)
+
+// Selector 描述全主机扫描的进程筛选条件。
+// 同一维度的多个取值之间为“或”关系，不同维度之间为“与”关系；全部为空时匹配所有进程。
+type Selector struct {
+	Comms   []string
+	UIDs    []int
+	Cgroups []string
+}
+
+// ParseSelector 解析形如 "comm:java,user:app,cgroup:/system.slice/x" 的筛选表达式。
+// user 既可以是用户名也可以是数字 UID，用户名从 c 视角下的 /etc/passwd 解析；cgroup 匹配该路径及其子路径。
+func ParseSelector(c *collectors.Collector, expr string) (Selector, error) {
+	var sel Selector
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return sel, nil
+	}
+
+	for _, item := range strings.Split(expr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, ":")
+		if !ok || value == "" {
+			return Selector{}, fmt.Errorf("invalid pid selector %q: expected key:value", item)
+		}
+		switch key {
+		case "comm":
+			sel.Comms = append(sel.Comms, value)
+		case "user":
+			uid, err := lookupUID(c, value)
+			if err != nil {
+				return Selector{}, fmt.Errorf("invalid pid selector %q: %w", item, err)
+			}
+			sel.UIDs = append(sel.UIDs, uid)
+		case "cgroup":
+			sel.Cgroups = append(sel.Cgroups, value)
+		default:
+			return Selector{}, fmt.Errorf("invalid pid selector %q: unknown key %q (supported: comm, user, cgroup)", item, key)
+		}
+	}
+	return sel, nil
+}
+
+// Match 判断给定进程属性是否满足筛选条件。
+func (s Selector) Match(comm string, uid int, cgroups []string) bool {
+	if len(s.Comms) > 0 && !containsString(s.Comms, comm) {
+		return false
+	}
+	if len(s.UIDs) > 0 {
+		found := false
+		for _, u := range s.UIDs {
+			if u == uid {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(s.Cgroups) > 0 {
+		found := false
+	cgroupLoop:
+		for _, prefix := range s.Cgroups {
+			for _, cg := range cgroups {
+				if cgroupUnder(cg, prefix) {
+					found = true
+					break cgroupLoop
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// cgroupUnder 判断 cgroup 路径 cg 是否为 prefix 本身或其子路径，按路径分隔符边界比较，
+// 避免 /system.slice/app 误匹配 /system.slice/app2。
+func cgroupUnder(cg, prefix string) bool {
+	dir := strings.TrimSuffix(prefix, "/")
+	return cg == prefix || cg == dir || strings.HasPrefix(cg, dir+"/")
+}
+
+// lookupUID 将用户名或数字字符串解析为 UID。
+// 用户名读取采集视角下的 /etc/passwd，以便在 sidecar 中按宿主机的用户数据库解析。
+func lookupUID(c *collectors.Collector, name string) (int, error) {
+	if uid, err := strconv.Atoi(name); err == nil {
+		return uid, nil
+	}
+	return c.LookupUser(name)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
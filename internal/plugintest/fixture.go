@@ -0,0 +1,206 @@
+package plugintest
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing/fstest"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/config"
+)
+
+const (
+	fixtureFile = "fixture.yaml"
+	rootfsDir   = "rootfs"
+	goldenDir   = "golden"
+)
+
+// Fixture 是一个测试场景：伪造的文件树、诊断目标以及需要比较结果的插件。
+//
+// 夹具目录布局：
+//
+//	fixture.yaml         场景描述、目标与插件列表
+//	rootfs/<path>        宿主视角的文件，如 rootfs/proc/42/limits
+//	golden/<plugin>.json 各插件的期望结果
+//
+// fixture.yaml 示例：
+//
+//	description: cgroup v2 下 pids.max 为首个阻断因素
+//	target:
+//	  pids: [42]
+//	  all_processes: false
+//	  pid_selector: comm:java
+//	  container: 3f4e1a2b9c0d
+//	plugins: [maxproc, maxfd]
+//	links:
+//	  /proc/self: "42"
+//	  /proc/42/fd/0: /dev/null
+//	denied: [/proc/42/fd]
+//
+// 符号链接在 fixture.yaml 中声明而不是放入 rootfs，以免依赖检出环境对符号链接的支持。
+// denied 中的路径及其下的路径读取时返回权限错误（Stat 除外），用于模拟以非特权用户运行。
+type Fixture struct {
+	Name        string
+	Dir         string
+	Description string
+	Target      core.Target
+	Plugins     []string
+	// FS 为由 rootfs 与 links 构成的内存文件树。
+	FS collectors.FS
+}
+
+// GoldenPath 返回插件 plugin 的 golden 文件路径。
+func (f *Fixture) GoldenPath(plugin string) string {
+	return filepath.Join(f.Dir, goldenDir, plugin+".json")
+}
+
+// LoadFixtures 加载 dir 下的所有夹具目录，按名称排序。
+func LoadFixtures(dir string) ([]*Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []*Fixture
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		f, err := LoadFixture(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
+
+// LoadFixture 加载单个夹具目录。rootfs 中的文件会整体读入内存，插件运行期间不再访问磁盘。
+func LoadFixture(dir string) (*Fixture, error) {
+	data, err := os.ReadFile(filepath.Join(dir, fixtureFile))
+	if err != nil {
+		return nil, err
+	}
+	tree, err := config.ParseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fixtureFile, err)
+	}
+	root, ok := tree.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: top level must be a mapping", fixtureFile)
+	}
+
+	f := &Fixture{Name: filepath.Base(dir), Dir: dir}
+	f.Description, _ = root["description"].(string)
+	f.Plugins = stringList(root["plugins"])
+	if target, ok := root["target"].(map[string]any); ok {
+		for _, s := range stringList(target["pids"]) {
+			pid, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s: target.pids: %w", fixtureFile, err)
+			}
+			f.Target.PIDs = append(f.Target.PIDs, pid)
+		}
+		f.Target.AllProcesses = target["all_processes"] == "true"
+		f.Target.PIDSelector, _ = target["pid_selector"].(string)
+		f.Target.CgroupPath, _ = target["cgroup"].(string)
+		f.Target.ContainerID, _ = target["container"].(string)
+	}
+
+	mfs := fstest.MapFS{}
+	rootfs := filepath.Join(dir, rootfsDir)
+	err = filepath.WalkDir(rootfs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(rootfs, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if d.IsDir() {
+			mfs[name] = &fstest.MapFile{Mode: fs.ModeDir | 0o555}
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		mfs[name] = &fstest.MapFile{Data: content, Mode: 0o444}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if links, ok := root["links"].(map[string]any); ok {
+		for name, target := range links {
+			s, ok := target.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: links.%s must be a string", fixtureFile, name)
+			}
+			mfs[strings.TrimPrefix(name, "/")] = &fstest.MapFile{Data: []byte(s), Mode: fs.ModeSymlink | 0o777}
+		}
+	}
+	f.FS = FromFS(mfs)
+	if denied := stringList(root["denied"]); len(denied) > 0 {
+		f.FS = deniedFS{FS: f.FS, denied: denied}
+	}
+	return f, nil
+}
+
+// deniedFS 对 denied 中的路径及其下的路径返回权限错误，与非特权用户读取其他用户进程的 fd 目录时一致，Stat 不受影响。
+type deniedFS struct {
+	collectors.FS
+	denied []string
+}
+
+func (d deniedFS) check(op, name string) error {
+	for _, p := range d.denied {
+		if name == p || strings.HasPrefix(name, p+"/") {
+			return &fs.PathError{Op: op, Path: name, Err: fs.ErrPermission}
+		}
+	}
+	return nil
+}
+
+func (d deniedFS) ReadFile(name string) ([]byte, error) {
+	if err := d.check("open", name); err != nil {
+		return nil, err
+	}
+	return d.FS.ReadFile(name)
+}
+
+func (d deniedFS) ReadDirNames(name string) ([]string, error) {
+	if err := d.check("open", name); err != nil {
+		return nil, err
+	}
+	return d.FS.ReadDirNames(name)
+}
+
+func (d deniedFS) Readlink(name string) (string, error) {
+	if err := d.check("readlink", name); err != nil {
+		return "", err
+	}
+	return d.FS.Readlink(name)
+}
+
+// stringList 将 YAML 序列或单个标量转换为字符串列表。
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
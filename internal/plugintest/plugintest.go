@@ -0,0 +1,72 @@
+// Package plugintest 提供插件测试工具：基于伪造的 proc/sys/etc 文件树运行任意 core.Plugin，
+// 并将诊断结果与 golden JSON 文件比较。
+//
+// 典型用法是在 testdata 下为每个场景准备一个夹具目录（见 LoadFixture），
+// 对夹具中列出的每个插件调用 Run，再以 Golden 与 golden/<plugin>.json 比较；
+// 以 go test -update 运行时改为重写 golden 文件。
+package plugintest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+var update = flag.Bool("update", false, "用当前结果重写 golden 文件")
+
+// FromFS 将 io/fs.FS（如 testing/fstest.MapFS）适配为 collectors.FS，使插件可以读取内存中的伪造文件树，
+// 路径映射规则见 collectors.FromIOFS。
+func FromFS(fsys fs.FS) collectors.FS {
+	return collectors.FromIOFS(fsys)
+}
+
+// Run 以 fsys 作为唯一数据来源运行插件 p。cfg 为 nil 时使用默认配置，并始终关闭趋势采样，
+// 以保证结果只取决于文件树内容。
+func Run(ctx context.Context, p core.Plugin, fsys collectors.FS, target core.Target, cfg *config.Config) (models.Result, error) {
+	if cfg == nil {
+		cfg = config.NewDefault()
+	}
+	noSampling := *cfg
+	noSampling.Sampling.Window = 0
+	r := core.NewRunner([]core.Plugin{p}, core.WithConfig(&noSampling), core.WithFS(fsys))
+	return r.Run(ctx, p.Name(), target)
+}
+
+// Golden 将 got 序列化为带缩进的 JSON 并与 path 处的 golden 文件比较，不一致时报告测试失败。
+// 指定 -update 时改为写入 golden 文件。
+func Golden(t testing.TB, path string, got any) {
+	t.Helper()
+	data, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal %s: %v", path, err)
+	}
+	data = append(data, '\n')
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(want, data) {
+		t.Errorf("result differs from %s (run with -update to accept):\n--- want\n%s\n--- got\n%s", path, want, data)
+	}
+}
@@ -0,0 +1,172 @@
+package remedy
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// AnsibleTasksFile 为 Ansible 任务文件的相对路径，不属于主机上的文件。
+const AnsibleTasksFile = "ansible/ossre-tasks.yml"
+
+// Artifact 为 export 生成的一个配置文件。主机上的配置文件 Path 为部署位置的绝对路径，
+// Ansible 任务文件等辅助文件为相对路径。
+type Artifact struct {
+	Path    string
+	Content string
+}
+
+// ExportOptions 控制 Export 生成的配置文件。
+type ExportOptions struct {
+	// Unit 为 systemd drop-in 所属的服务单元，如 nginx.service；为空时不生成 systemd drop-in。
+	Unit string
+	// Ansible 为 true 时额外生成部署上述配置文件的 Ansible 任务文件。
+	Ansible bool
+}
+
+// keyValue 为按首次出现顺序保存的配置项，同一配置项以最后一次的值为准。
+type keyValue struct {
+	keys   []string
+	values map[string]string
+}
+
+func (kv *keyValue) set(k, v string) {
+	if kv.values == nil {
+		kv.values = make(map[string]string)
+	}
+	if _, ok := kv.values[k]; !ok {
+		kv.keys = append(kv.keys, k)
+	}
+	kv.values[k] = v
+}
+
+// Export 将诊断结果中的修复动作转换为可纳入配置管理的持久化配置文件：
+// sysctl.d 与 limits.d 的 drop-in、指定服务单元时带 LimitNOFILE/LimitNPROC/TasksMax 的 systemd drop-in，
+// 以及可选的 Ansible 任务文件。
+// 立即生效类的动作（sysctl、pids.max）同样转换为对应的持久化配置。
+// warnings 说明需要人工处理的情况，如参数已在加载顺序更靠后的文件中配置。
+func Export(results []models.Result, opts ExportOptions) (artifacts []Artifact, warnings []string) {
+	var sysctls, limits keyValue
+	var tasksMax string
+	for _, result := range results {
+		for _, s := range result.Suggestions {
+			for _, a := range s.Actions {
+				switch a.Type {
+				case models.ActionSysctl:
+					sysctls.set(a.Key, a.Value)
+				case models.ActionSysctlDropIn:
+					sysctls.set(a.Key, a.Value)
+					if loadsAfterDropIn(a.Path) {
+						warnings = append(warnings, fmt.Sprintf(
+							"%s 已在 %s 中配置，该文件在 %s 之后加载，需同时删除其中的配置行", a.Key, a.Path, models.SysctlDropInFile))
+					}
+				case models.ActionLimitsDropIn:
+					limits.set(a.Key, a.Value)
+				case models.ActionCgroupPidsMax:
+					tasksMax = a.Value
+				}
+			}
+		}
+	}
+
+	if len(sysctls.keys) > 0 {
+		var b strings.Builder
+		b.WriteString("# 由 ossre export 生成。执行 sysctl --system 或重启后生效。\n")
+		for _, k := range sysctls.keys {
+			fmt.Fprintf(&b, "%s = %s\n", k, sysctls.values[k])
+		}
+		artifacts = append(artifacts, Artifact{Path: models.SysctlDropInFile, Content: b.String()})
+	}
+
+	if len(limits.keys) > 0 {
+		var b strings.Builder
+		b.WriteString("# 由 ossre export 生成。pam_limits 只作用于登录会话，systemd 服务请使用对应的 drop-in。\n")
+		for _, item := range limits.keys {
+			for _, domain := range []string{"*", "root"} {
+				for _, typ := range []string{"soft", "hard"} {
+					fmt.Fprintf(&b, "%-4s %s %s %s\n", domain, typ, item, limits.values[item])
+				}
+			}
+		}
+		artifacts = append(artifacts, Artifact{Path: models.LimitsDropInFile, Content: b.String()})
+	}
+
+	// systemd 服务不经过 pam_limits，需要在单元中单独设置资源限制
+	var service []string
+	for item, directive := range map[string]string{"nofile": "LimitNOFILE", "nproc": "LimitNPROC"} {
+		if v, ok := limits.values[item]; ok {
+			service = append(service, directive+"="+v)
+		}
+	}
+	if tasksMax != "" {
+		service = append(service, "TasksMax="+tasksMax)
+	}
+	// map 遍历顺序不固定，按指令名排序以保证输出稳定
+	sort.Strings(service)
+	switch {
+	case len(service) == 0:
+	case opts.Unit == "":
+		// TasksMax 取自目标进程所在 cgroup 的 pids.max，写入对所有服务生效的 service.d 会以无关的数值
+		// 覆盖各服务自身的设置（如 docker.service 的 TasksMax=infinity），Limit* 同理，因此只写入指定的服务单元
+		warnings = append(warnings, fmt.Sprintf(
+			"未指定服务单元（--unit），未生成 systemd drop-in：%s 需写入目标服务的 drop-in，写入对所有服务生效的 service.d 会覆盖各服务自身的设置",
+			strings.Join(service, "、")))
+	default:
+		dir := path.Join("/etc/systemd/system", opts.Unit+".d")
+		var b strings.Builder
+		b.WriteString("# 由 ossre export 生成。执行 systemctl daemon-reload 并重启服务后生效。\n[Service]\n")
+		for _, line := range service {
+			b.WriteString(line + "\n")
+		}
+		artifacts = append(artifacts, Artifact{Path: path.Join(dir, "99-ossre.conf"), Content: b.String()})
+	}
+
+	if opts.Ansible && len(artifacts) > 0 {
+		artifacts = append(artifacts, Artifact{Path: AnsibleTasksFile, Content: ansibleTasks(artifacts)})
+	}
+	return artifacts, warnings
+}
+
+// loadsAfterDropIn 判断 sysctl 配置文件是否在 ossre 的 drop-in 之后加载，其中的配置会覆盖 drop-in。
+// sysctl --system 按文件名顺序加载 /etc/sysctl.d/*.conf，最后加载 /etc/sysctl.conf。
+func loadsAfterDropIn(name string) bool {
+	if name == "/etc/sysctl.conf" {
+		return true
+	}
+	return path.Dir(name) == path.Dir(models.SysctlDropInFile) && path.Base(name) > path.Base(models.SysctlDropInFile)
+}
+
+// ansibleTasks 生成部署 artifacts 的 Ansible 任务文件，配置文件变化时重新加载 sysctl 或 systemd。
+func ansibleTasks(artifacts []Artifact) string {
+	var b strings.Builder
+	b.WriteString("# 由 ossre export 生成，可通过 include_tasks / import_tasks 引入。\n")
+	for i, a := range artifacts {
+		register := fmt.Sprintf("ossre_file_%d", i+1)
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "- name: ossre | 部署 %s\n", a.Path)
+		b.WriteString("  ansible.builtin.copy:\n")
+		fmt.Fprintf(&b, "    dest: %s\n", a.Path)
+		b.WriteString("    owner: root\n    group: root\n    mode: \"0644\"\n    content: |\n")
+		for _, line := range strings.Split(strings.TrimSuffix(a.Content, "\n"), "\n") {
+			b.WriteString("      " + line + "\n")
+		}
+		fmt.Fprintf(&b, "  register: %s\n", register)
+
+		switch {
+		case a.Path == models.SysctlDropInFile:
+			fmt.Fprintf(&b, "\n- name: ossre | 加载 %s\n", a.Path)
+			fmt.Fprintf(&b, "  ansible.builtin.command: sysctl -p %s\n", a.Path)
+			fmt.Fprintf(&b, "  when: %s.changed\n", register)
+		case strings.HasPrefix(a.Path, "/etc/systemd/"):
+			b.WriteString("\n- name: ossre | systemctl daemon-reload\n")
+			b.WriteString("  ansible.builtin.systemd:\n    daemon_reload: true\n")
+			fmt.Fprintf(&b, "  when: %s.changed\n", register)
+		}
+	}
+	return b.String()
+}
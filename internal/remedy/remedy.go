@@ -0,0 +1,381 @@
+// Package remedy 实现诊断建议中修复动作的计划、执行与回滚。
+//
+// 执行前会将所有待修改文件的原始内容（或"文件不存在"）写入备份记录，再依次执行动作；
+// 回滚时按记录逆序恢复这些文件。所有路径均为宿主视角，按 --root 等配置映射到实际位置。
+package remedy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// DefaultStateDir 为备份记录的默认保存目录。
+const DefaultStateDir = "/var/lib/ossre/fix"
+
+// Step 为修复计划中的一个动作及其来源。
+type Step struct {
+	// Index 为动作在计划中的序号，从 1 开始，用于选择部分动作执行。
+	Index      int
+	Plugin     string
+	FindingID  string
+	Suggestion string
+	Action     models.Action
+	// Current 为动作目标的当前值，读取失败或不适用时为空。
+	Current string
+}
+
+// FileBackup 为一个文件在修复前的状态。
+type FileBackup struct {
+	Path    string
+	Existed bool
+	// Content 为修复前的文件内容，文件不存在时为空。
+	Content string
+}
+
+// Record 为一次修复的备份记录，Files 按首次修改的顺序排列。
+type Record struct {
+	ID        string
+	CreatedAt time.Time
+	Actions   []models.Action
+	Files     []FileBackup
+	// RolledBack 为该记录被回滚的时间，未回滚时为 nil。
+	RolledBack *time.Time `json:",omitempty"`
+}
+
+// Engine 在 paths 描述的文件系统上执行修复动作，并将备份记录保存在 stateDir。
+type Engine struct {
+	fs       *collectors.HostFS
+	stateDir string
+	now      func() time.Time
+}
+
+// NewEngine 创建修复引擎，stateDir 为空时使用 DefaultStateDir。
+func NewEngine(paths config.PathsConfig, stateDir string) *Engine {
+	if stateDir == "" {
+		stateDir = DefaultStateDir
+	}
+	return &Engine{fs: collectors.NewHostFS(paths), stateDir: stateDir, now: time.Now}
+}
+
+// Plan 从诊断结果中收集修复动作并读取其当前值，完全相同的动作只保留第一次出现。
+func (e *Engine) Plan(results []models.Result) []Step {
+	var steps []Step
+	seen := make(map[models.Action]bool)
+	for _, result := range results {
+		for _, s := range result.Suggestions {
+			for _, a := range s.Actions {
+				if seen[a] {
+					continue
+				}
+				seen[a] = true
+				steps = append(steps, Step{
+					Index:      len(steps) + 1,
+					Plugin:     result.Plugin,
+					FindingID:  s.FindingID,
+					Suggestion: s.Title,
+					Action:     a,
+					Current:    e.current(a),
+				})
+			}
+		}
+	}
+	return steps
+}
+
+// current 读取动作目标的当前值：sysctl 与 pids.max 为文件内容，配置文件为其中已有的对应配置行。
+func (e *Engine) current(a models.Action) string {
+	switch a.Type {
+	case models.ActionSysctl:
+		data, _ := e.fs.ReadFile(collectors.SysctlPath(a.Key))
+		return strings.TrimSpace(string(data))
+	case models.ActionCgroupPidsMax:
+		data, _ := e.fs.ReadFile(a.Path)
+		return strings.TrimSpace(string(data))
+	case models.ActionSysctlDropIn:
+		data, _ := e.fs.ReadFile(a.Path)
+		for _, line := range strings.Split(string(data), "\n") {
+			if k, v, ok := sysctlLine(line); ok && k == a.Key {
+				return v
+			}
+		}
+	case models.ActionLimitsDropIn:
+		data, _ := e.fs.ReadFile(a.Path)
+		var found []string
+		for _, line := range strings.Split(string(data), "\n") {
+			if f := strings.Fields(line); len(f) == 4 && f[0][0] != '#' && f[2] == a.Key {
+				found = append(found, strings.Join(f, " "))
+			}
+		}
+		return strings.Join(found, "; ")
+	}
+	return ""
+}
+
+// Describe 返回动作的可读描述。
+func Describe(a models.Action) string {
+	switch a.Type {
+	case models.ActionSysctl:
+		return fmt.Sprintf("sysctl -w %s=%s", a.Key, a.Value)
+	case models.ActionSysctlDropIn:
+		return fmt.Sprintf("在 %s 中设置 %s = %s", a.Path, a.Key, a.Value)
+	case models.ActionLimitsDropIn:
+		return fmt.Sprintf("在 %s 中将 * 与 root 的 %s 软硬限制设置为 %s", a.Path, a.Key, a.Value)
+	case models.ActionCgroupPidsMax:
+		return fmt.Sprintf("echo %s > %s", a.Value, a.Path)
+	default:
+		return fmt.Sprintf("未知动作 %s", a.Type)
+	}
+}
+
+// change 为一个动作对文件的修改。
+type change struct {
+	path    string
+	content string
+	// dropIn 表示目标为配置文件，写入前需要确保父目录存在。
+	dropIn bool
+}
+
+// Apply 执行 steps 中的动作。先计算全部修改并保存备份记录，再依次写入；
+// 写入失败时立即停止，已保存的记录可用于回滚已完成的修改。
+func (e *Engine) Apply(steps []Step) (*Record, error) {
+	rec := &Record{ID: e.newID(), CreatedAt: e.now().UTC()}
+	// 同一文件可能被多个动作修改（如多个参数写入同一 drop-in），后续动作在前一次修改的基础上进行
+	pending := make(map[string]string)
+	var changes []change
+	for _, s := range steps {
+		a := s.Action
+		target := a.Path
+		if a.Type == models.ActionSysctl {
+			target = collectors.SysctlPath(a.Key)
+		}
+		if target == "" || !path.IsAbs(target) {
+			return nil, fmt.Errorf("action %s: invalid path %q", a.Type, target)
+		}
+		prev, ok := pending[target]
+		if !ok {
+			data, err := e.fs.ReadFile(target)
+			switch {
+			case err == nil:
+				rec.Files = append(rec.Files, FileBackup{Path: target, Existed: true, Content: string(data)})
+			case errors.Is(err, fs.ErrNotExist) && a.Type != models.ActionSysctl && a.Type != models.ActionCgroupPidsMax:
+				rec.Files = append(rec.Files, FileBackup{Path: target})
+			default:
+				return nil, fmt.Errorf("read %s: %w", target, err)
+			}
+			prev = string(data)
+		}
+
+		var next string
+		switch a.Type {
+		case models.ActionSysctl, models.ActionCgroupPidsMax:
+			next = a.Value + "\n"
+		case models.ActionSysctlDropIn:
+			next = setSysctl(prev, a.Key, a.Value)
+		case models.ActionLimitsDropIn:
+			next = setLimits(prev, a.Key, a.Value)
+		default:
+			return nil, fmt.Errorf("unsupported action %q", a.Type)
+		}
+		pending[target] = next
+		rec.Actions = append(rec.Actions, a)
+		changes = append(changes, change{
+			path:    target,
+			content: next,
+			dropIn:  a.Type == models.ActionSysctlDropIn || a.Type == models.ActionLimitsDropIn,
+		})
+	}
+	if len(changes) == 0 {
+		return nil, errors.New("no actions to apply")
+	}
+
+	if err := e.save(rec); err != nil {
+		return nil, fmt.Errorf("save backup record: %w", err)
+	}
+	for _, c := range changes {
+		if err := e.write(c.path, c.content, c.dropIn); err != nil {
+			return rec, fmt.Errorf("write %s: %w", c.path, err)
+		}
+	}
+	return rec, nil
+}
+
+// Rollback 按备份记录逆序恢复文件：修复前不存在的文件被删除，其余文件恢复原内容。
+// 已回滚过的记录返回错误，避免以过时的备份覆盖之后的修复或手工修改。
+func (e *Engine) Rollback(id string) (*Record, error) {
+	rec, err := e.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.RolledBack != nil {
+		return rec, fmt.Errorf("record %s was already rolled back at %s", id, rec.RolledBack.Format(time.RFC3339))
+	}
+	var errs []error
+	for i := len(rec.Files) - 1; i >= 0; i-- {
+		f := rec.Files[i]
+		if !f.Existed {
+			if err := os.Remove(e.fs.Resolve(f.Path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, fmt.Errorf("remove %s: %w", f.Path, err))
+			}
+			continue
+		}
+		if err := e.write(f.Path, f.Content, false); err != nil {
+			errs = append(errs, fmt.Errorf("restore %s: %w", f.Path, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return rec, err
+	}
+	now := e.now().UTC()
+	rec.RolledBack = &now
+	if err := e.save(rec); err != nil {
+		return rec, fmt.Errorf("save backup record: %w", err)
+	}
+	return rec, nil
+}
+
+// Load 读取指定 ID 的备份记录。
+func (e *Engine) Load(id string) (*Record, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, fmt.Errorf("invalid record id %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(e.stateDir, id+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("load record %s: %w", id, err)
+	}
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("parse record %s: %w", id, err)
+	}
+	return &rec, nil
+}
+
+// History 返回全部备份记录，按创建时间从新到旧排列；目录不存在时返回空列表。
+func (e *Engine) History() ([]Record, error) {
+	entries, err := os.ReadDir(e.stateDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var records []Record
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		rec, err := e.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].CreatedAt.After(records[j].CreatedAt) })
+	return records, nil
+}
+
+// save 将备份记录写入 stateDir，先写临时文件再重命名，避免留下不完整的记录。
+func (e *Engine) save(rec *Record) error {
+	if err := os.MkdirAll(e.stateDir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(e.stateDir, rec.ID+".json")
+	if err := os.WriteFile(name+".tmp", append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(name+".tmp", name)
+}
+
+// write 写入宿主视角路径对应的实际文件。/proc/sys 与 cgroup 文件不支持创建与重命名，直接截断写入。
+func (e *Engine) write(name, content string, mkdir bool) error {
+	real := e.fs.Resolve(name)
+	if mkdir {
+		if err := os.MkdirAll(filepath.Dir(real), 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(real, []byte(content), 0o644)
+}
+
+// newID 生成备份记录 ID，格式为 时间戳-随机后缀，按字典序即按时间排序。
+func (e *Engine) newID() string {
+	var b [3]byte
+	_, _ = rand.Read(b[:])
+	return e.now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b[:])
+}
+
+// sysctlLine 解析 sysctl 配置行，返回规范化后的参数名与值。
+func sysctlLine(line string) (key, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || line[0] == '#' || line[0] == ';' {
+		return "", "", false
+	}
+	k, v, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", "", false
+	}
+	k = strings.ReplaceAll(strings.TrimPrefix(strings.TrimSpace(k), "-"), "/", ".")
+	return k, strings.TrimSpace(v), true
+}
+
+// fileHeader 为 ossre 新建配置文件的首行注释。
+const fileHeader = "# 由 ossre fix 写入，可通过 ossre fix --rollback 恢复\n"
+
+// setSysctl 将 content 中参数 key 的配置行全部替换为 "key = value"，不存在时在末尾追加。
+func setSysctl(content, key, value string) string {
+	return setLine(content, key+" = "+value, func(line string) bool {
+		k, _, ok := sysctlLine(line)
+		return ok && k == key
+	})
+}
+
+// setLimits 将 content 中 * 与 root 的 item 软硬限制设置为 value，已有的配置行原地替换，其余在末尾追加。
+func setLimits(content, item, value string) string {
+	for _, domain := range []string{"*", "root"} {
+		for _, typ := range []string{"soft", "hard"} {
+			want := fmt.Sprintf("%-4s %s %s %s", domain, typ, item, value)
+			content = setLine(content, want, func(line string) bool {
+				f := strings.Fields(line)
+				return len(f) == 4 && f[0] == domain && f[1] == typ && f[2] == item
+			})
+		}
+	}
+	return content
+}
+
+// setLine 将 content 中满足 match 的行替换为 want，没有匹配行时在末尾追加；content 为空时先写入文件头。
+func setLine(content, want string, match func(string) bool) string {
+	if content == "" {
+		content = fileHeader
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	replaced := false
+	for i, line := range lines {
+		if match(line) {
+			lines[i] = want
+			replaced = true
+		}
+	}
+	if !replaced {
+		lines = append(lines, want)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,162 @@
+package report
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// htmlPlugin 为 HTML 模板中单个插件的渲染数据。
+type htmlPlugin struct {
+	Summary    summary
+	Findings   []htmlFinding
+	Extra      []models.Suggestion
+	Suppressed []models.SuppressedFinding
+	Skipped    []models.SkippedCheck
+	Clean      string
+}
+
+type htmlFinding struct {
+	models.Finding
+	Suggestions []models.Suggestion
+}
+
+// writeHTML 输出单文件 HTML 报告，样式内联、不引用任何外部资源，可直接作为附件上传。
+func writeHTML(w io.Writer, meta Meta, results []models.Result) error {
+	plugins := make([]htmlPlugin, 0, len(results))
+	for _, result := range results {
+		p := htmlPlugin{Summary: summarize(result), Extra: unlinkedSuggestions(result), Suppressed: result.Suppressed, Skipped: result.Skipped, Clean: cleanNote(result)}
+		for _, f := range result.Findings {
+			p.Findings = append(p.Findings, htmlFinding{Finding: f, Suggestions: suggestionsFor(result, f.ID)})
+		}
+		plugins = append(plugins, p)
+	}
+	return htmlTemplate.Execute(w, map[string]any{
+		"Meta":       metaLines(meta),
+		"Plugins":    plugins,
+		"Severities": severities,
+	})
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"count": func(s summary, sev models.Severity) int { return s.Counts[sev] },
+	"sevClass": func(s models.Severity) string {
+		switch s {
+		case models.SeverityCritical, models.SeverityError, models.SeverityWarning:
+			return string(s)
+		default:
+			return "info"
+		}
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>ossre 诊断报告</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; margin: 2em auto; max-width: 1100px; padding: 0 1em; color: #1f2328; line-height: 1.5; }
+h1 { border-bottom: 1px solid #d0d7de; padding-bottom: .3em; }
+h2 { margin-top: 2em; border-bottom: 1px solid #d0d7de; padding-bottom: .2em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #d0d7de; padding: 4px 12px; text-align: left; }
+td.num { text-align: right; }
+.meta { color: #59636e; list-style: none; padding: 0; }
+.badge { display: inline-block; border-radius: 4px; padding: 0 8px; font-size: .85em; font-weight: 600; color: #fff; }
+.critical { background: #8b0000; }
+.error { background: #cf222e; }
+.warning { background: #bf8700; }
+.info { background: #0969da; }
+.ok { background: #1a7f37; }
+.finding { border: 1px solid #d0d7de; border-left-width: 6px; border-radius: 6px; padding: .5em 1em; margin: 1em 0; }
+.finding.sev-critical { border-left-color: #8b0000; }
+.finding.sev-error { border-left-color: #cf222e; }
+.finding.sev-warning { border-left-color: #bf8700; }
+.finding.sev-info { border-left-color: #0969da; }
+.finding h3 { margin: .3em 0; font-size: 1.05em; }
+code { background: #f6f8fa; padding: 0 4px; border-radius: 4px; }
+pre { background: #f6f8fa; padding: .8em; border-radius: 6px; overflow-x: auto; white-space: pre-wrap; }
+pre code { padding: 0; }
+summary { cursor: pointer; color: #0969da; }
+</style>
+</head>
+<body>
+<h1>ossre 诊断报告</h1>
+{{- with .Meta}}
+<ul class="meta">
+{{- range .}}
+<li>{{index . 0}}: {{index . 1}}</li>
+{{- end}}
+</ul>
+{{- end}}
+
+<h2>概览</h2>
+<table>
+<tr><th>模块</th><th>最高级别</th>{{range $.Severities}}<th>{{.}}</th>{{end}}</tr>
+{{- range .Plugins}}
+<tr><td><a href="#plugin-{{.Summary.Plugin}}">{{.Summary.Plugin}}</a></td><td>{{if .Summary.Total}}<span class="badge {{sevClass .Summary.Highest}}">{{.Summary.Highest}}</span>{{else if .Summary.Skipped}}<span class="badge warning">{{.Summary.Skipped}} 项未评估</span>{{else}}<span class="badge ok">未发现问题</span>{{end}}</td>{{$s := .Summary}}{{range $.Severities}}<td class="num">{{count $s .}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{range .Plugins}}
+<h2 id="plugin-{{.Summary.Plugin}}">{{.Summary.Plugin}}</h2>
+{{- if and (not .Findings) (not .Extra)}}
+<p>{{.Clean}}</p>
+{{- end}}
+{{- range $i, $f := .Findings}}
+<div class="finding sev-{{sevClass $f.Severity}}">
+<h3><span class="badge {{sevClass $f.Severity}}">{{$f.Severity}}</span> {{$f.Title}}</h3>
+{{- if $f.ID}}
+<div>ID: <code>{{$f.ID}}</code></div>
+{{- end}}
+{{- if $f.Impact}}
+<p>影响: {{$f.Impact}}</p>
+{{- end}}
+{{- if $f.ConfigFile}}
+<div>配置文件: <code>{{$f.ConfigFile}}</code></div>
+{{- end}}
+{{- if $f.Description}}
+<details>
+<summary>详细信息</summary>
+<pre><code>{{$f.Description}}</code></pre>
+</details>
+{{- end}}
+{{- range $f.Suggestions}}
+<p><strong>建议：{{.Title}}</strong></p>
+{{- if .Details}}
+<pre><code>{{.Details}}</code></pre>
+{{- end}}
+{{- end}}
+</div>
+{{- end}}
+{{- with .Extra}}
+<h3>其他建议</h3>
+{{- range .}}
+<p><strong>建议：{{.Title}}</strong></p>
+{{- if .Details}}
+<pre><code>{{.Details}}</code></pre>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- with .Skipped}}
+<h3>未评估</h3>
+<table>
+<tr><th>案例</th><th>原因</th></tr>
+{{- range .}}
+<tr><td><code>{{.Case}}</code></td><td>{{.Reason}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Suppressed}}
+<h3>已豁免</h3>
+<table>
+<tr><th>发现</th><th>级别</th><th>豁免</th><th>负责人</th><th>原因</th><th>到期</th></tr>
+{{- range .}}
+<tr><td>{{.Finding.Title}}<br><code>{{.Finding.ID}}</code></td><td><span class="badge {{sevClass .Finding.Severity}}">{{.Finding.Severity}}</span></td><td><code>{{.Case}}</code></td><td>{{.Owner}}</td><td>{{.Reason}}</td><td>{{if .Expires}}{{.Expires}}{{else}}长期{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,155 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// JUnit XML 没有正式规范，以下结构与 Jenkins、GitLab、GitHub Actions 等常见 CI 的解析器兼容。
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr,omitempty"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Hostname  string          `xml:"hostname,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// junitSkipped 标记被豁免或未评估的案例。
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitOutput 以 CDATA 输出多行文本，避免换行被转义为字符引用，便于在 CI 界面中阅读。
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+// writeJUnit 输出 JUnit XML：每个插件为一个 testsuite，每个案例（Finding.ID）为一个 testcase。
+// warning 及以上级别的发现记为失败；info 级别的发现是评估结论而非问题，记为通过并将内容写入 system-out；
+// 被豁免的发现与未评估的检查记为跳过。
+// 插件元数据中声明、但本次既无发现也未被豁免或跳过的案例 ID 记为通过，使通过的检查同样计入用例数；
+// 以 * 结尾的前缀无法对应具体案例，不单独输出。插件仍没有任何 testcase 时输出一个以插件命名的通过用例。
+func writeJUnit(w io.Writer, meta Meta, results []models.Result) error {
+	suites := junitTestSuites{Name: "ossre"}
+	for _, result := range results {
+		suite := junitTestSuite{Name: result.Plugin, Hostname: meta.Hostname}
+		if !meta.GeneratedAt.IsZero() {
+			suite.Timestamp = meta.GeneratedAt.UTC().Format("2006-01-02T15:04:05")
+		}
+		for _, f := range result.Findings {
+			name := f.ID
+			if name == "" {
+				name = f.Title
+			}
+			tc := junitTestCase{ClassName: result.Plugin, Name: name}
+			body := junitBody(result, f)
+			if f.Severity.Rank() >= models.SeverityWarning.Rank() {
+				tc.Failure = &junitFailure{Message: f.Title, Type: string(f.Severity), Text: body}
+				suite.Failures++
+			} else {
+				tc.SystemOut = &junitOutput{Text: body}
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		for _, s := range result.Suppressed {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				ClassName: result.Plugin,
+				Name:      s.Finding.ID,
+				Skipped:   &junitSkipped{Message: suppressionNote(s)},
+			})
+			suite.Skipped++
+		}
+		for _, s := range result.Skipped {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				ClassName: result.Plugin,
+				Name:      s.Case,
+				Skipped:   &junitSkipped{Message: "未评估: " + s.Reason},
+			})
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, passedCases(result.Plugin, suite.Cases)...)
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{ClassName: result.Plugin, Name: result.Plugin})
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// passedCases 为插件声明的案例 ID 中未出现在 reported 里的具体案例生成通过的 testcase。
+func passedCases(plugin string, reported []junitTestCase) []junitTestCase {
+	meta, ok := core.LookupMetadata(plugin)
+	if !ok {
+		return nil
+	}
+	seen := make(map[string]bool, len(reported))
+	for _, tc := range reported {
+		seen[tc.Name] = true
+	}
+	var cases []junitTestCase
+	for _, id := range meta.CaseIDs {
+		if strings.HasSuffix(id, "*") || seen[id] {
+			continue
+		}
+		seen[id] = true
+		cases = append(cases, junitTestCase{ClassName: plugin, Name: id})
+	}
+	return cases
+}
+
+// junitBody 拼接发现的描述、影响与关联建议，作为失败详情或标准输出。
+func junitBody(result models.Result, f models.Finding) string {
+	parts := []string{f.Description}
+	if f.Impact != "" {
+		parts = append(parts, "影响: "+f.Impact)
+	}
+	if f.ConfigFile != "" {
+		parts = append(parts, "配置文件: "+f.ConfigFile)
+	}
+	for _, s := range suggestionsFor(result, f.ID) {
+		parts = append(parts, "建议: "+s.Title+"\n"+s.Details)
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,205 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// severityBadges 为 Markdown 中表示严重级别的标记，Markdown 本身不支持颜色，以彩色圆点区分。
+var severityBadges = map[models.Severity]string{
+	models.SeverityCritical: "🔴 critical",
+	models.SeverityError:    "🟠 error",
+	models.SeverityWarning:  "🟡 warning",
+	models.SeverityInfo:     "🔵 info",
+}
+
+func badge(s models.Severity) string {
+	if b, ok := severityBadges[s]; ok {
+		return b
+	}
+	return string(s)
+}
+
+// writeMarkdown 输出适合粘贴到工单与复盘文档的 Markdown 报告。
+// 详细描述放在 <details> 中默认折叠（GitHub、GitLab 等均支持），建议以代码块呈现以便直接复制执行。
+func writeMarkdown(w io.Writer, meta Meta, results []models.Result) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "# ossre 诊断报告")
+	fmt.Fprintln(bw)
+	for _, kv := range metaLines(meta) {
+		fmt.Fprintf(bw, "- %s: %s\n", kv[0], kv[1])
+	}
+	if len(metaLines(meta)) > 0 {
+		fmt.Fprintln(bw)
+	}
+
+	fmt.Fprintln(bw, "## 概览")
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "| 模块 | 最高级别 | critical | error | warning | info |")
+	fmt.Fprintln(bw, "| --- | --- | ---: | ---: | ---: | ---: |")
+	for _, result := range results {
+		s := summarize(result)
+		highest := "✅ 未发现问题"
+		switch {
+		case s.Total > 0:
+			highest = badge(s.Highest)
+		case s.Skipped > 0:
+			highest = fmt.Sprintf("⚠️ %d 项未评估", s.Skipped)
+		}
+		fmt.Fprintf(bw, "| [%s](#%s) | %s |", mdCell(s.Plugin), mdAnchor(s.Plugin), highest)
+		for _, sev := range severities {
+			fmt.Fprintf(bw, " %d |", s.Counts[sev])
+		}
+		fmt.Fprintln(bw)
+	}
+
+	for _, result := range results {
+		fmt.Fprintln(bw)
+		fmt.Fprintf(bw, "## %s\n", result.Plugin)
+		fmt.Fprintln(bw)
+		writeMarkdownSkipped(bw, result)
+		writeMarkdownSuppressed(bw, result)
+		if len(result.Findings) == 0 && len(result.Suggestions) == 0 {
+			fmt.Fprintln(bw, cleanNote(result))
+			continue
+		}
+		for i, f := range result.Findings {
+			fmt.Fprintf(bw, "### %d. %s\n\n", i+1, mdInline(f.Title))
+			fmt.Fprintf(bw, "- 级别: %s\n", badge(f.Severity))
+			if f.ID != "" {
+				fmt.Fprintf(bw, "- ID: `%s`\n", f.ID)
+			}
+			if f.Impact != "" {
+				fmt.Fprintf(bw, "- 影响: %s\n", mdInline(f.Impact))
+			}
+			if f.ConfigFile != "" {
+				fmt.Fprintf(bw, "- 配置文件: `%s`\n", f.ConfigFile)
+			}
+			fmt.Fprintln(bw)
+			if f.Description != "" {
+				fmt.Fprintln(bw, "<details>")
+				fmt.Fprintln(bw, "<summary>详细信息</summary>")
+				fmt.Fprintln(bw)
+				writeFence(bw, "text", f.Description)
+				fmt.Fprintln(bw)
+				fmt.Fprintln(bw, "</details>")
+				fmt.Fprintln(bw)
+			}
+			for _, s := range suggestionsFor(result, f.ID) {
+				writeMarkdownSuggestion(bw, s)
+			}
+		}
+		if extra := unlinkedSuggestions(result); len(extra) > 0 {
+			fmt.Fprintln(bw, "### 其他建议")
+			fmt.Fprintln(bw)
+			for _, s := range extra {
+				writeMarkdownSuggestion(bw, s)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// writeMarkdownSkipped 在插件小节开头以表格列出未评估的检查。
+func writeMarkdownSkipped(w io.Writer, result models.Result) {
+	if len(result.Skipped) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%d 项检查未评估：\n\n", len(result.Skipped))
+	fmt.Fprintln(w, "| 案例 | 原因 |")
+	fmt.Fprintln(w, "| --- | --- |")
+	for _, s := range result.Skipped {
+		fmt.Fprintf(w, "| `%s` | %s |\n", s.Case, mdCell(s.Reason))
+	}
+	fmt.Fprintln(w)
+}
+
+// writeMarkdownSuppressed 在插件小节开头以表格列出被豁免的发现。
+func writeMarkdownSuppressed(w io.Writer, result models.Result) {
+	if len(result.Suppressed) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "已豁免 %d 条发现：\n\n", len(result.Suppressed))
+	fmt.Fprintln(w, "| 发现 | 级别 | 豁免 | 负责人 | 原因 | 到期 |")
+	fmt.Fprintln(w, "| --- | --- | --- | --- | --- | --- |")
+	for _, s := range result.Suppressed {
+		expires := s.Expires
+		if expires == "" {
+			expires = "长期"
+		}
+		fmt.Fprintf(w, "| %s | %s | `%s` | %s | %s | %s |\n",
+			mdCell(s.Finding.Title), s.Finding.Severity, s.Case, mdCell(s.Owner), mdCell(s.Reason), expires)
+	}
+	fmt.Fprintln(w)
+}
+
+func writeMarkdownSuggestion(w io.Writer, s models.Suggestion) {
+	fmt.Fprintf(w, "**建议：%s**\n\n", mdInline(s.Title))
+	if s.Details != "" {
+		writeFence(w, "", s.Details)
+		fmt.Fprintln(w)
+	}
+}
+
+// writeFence 输出围栏代码块，围栏长度大于内容中最长的连续反引号，避免内容提前闭合代码块。
+func writeFence(w io.Writer, lang, content string) {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	fmt.Fprintf(w, "%s%s\n%s\n%s\n", fence, lang, strings.TrimRight(content, "\n"), fence)
+}
+
+// metaLines 返回报告头部需要展示的运行信息。
+func metaLines(meta Meta) [][2]string {
+	var lines [][2]string
+	if !meta.GeneratedAt.IsZero() {
+		lines = append(lines, [2]string{"生成时间", meta.GeneratedAt.Format(time.RFC3339)})
+	}
+	if meta.Hostname != "" {
+		lines = append(lines, [2]string{"主机", meta.Hostname})
+	}
+	if meta.Source != "" {
+		lines = append(lines, [2]string{"数据来源", meta.Source})
+	}
+	if meta.Version != "" {
+		lines = append(lines, [2]string{"ossre 版本", meta.Version})
+	}
+	return lines
+}
+
+// mdInline 将多行文本压缩为一行，用于标题与列表项。
+func mdInline(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// mdCell 转义表格单元格中的竖线。
+func mdCell(s string) string {
+	return strings.ReplaceAll(mdInline(s), "|", `\|`)
+}
+
+// mdAnchor 返回标题对应的锚点，规则与 GitHub 一致：小写、去掉标点、空格替换为连字符。
+func mdAnchor(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 0x7f:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
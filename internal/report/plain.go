@@ -0,0 +1,80 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// writePlain 以面向终端的格式化文本输出诊断结果，多个插件之间以空行分隔。
+func writePlain(w io.Writer, results []models.Result) error {
+	bw := bufio.NewWriter(w)
+	for i, result := range results {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		writePlainResult(bw, result)
+	}
+	return bw.Flush()
+}
+
+func writePlainResult(w io.Writer, result models.Result) {
+	fmt.Fprintf(w, "=== %s 诊断结果 ===\n\n", result.Plugin)
+
+	if len(result.Findings) > 0 {
+		fmt.Fprintln(w, "发现问题:")
+		for i, finding := range result.Findings {
+			fmt.Fprintf(w, "\n%d. %s\n", i+1, finding.Title)
+			fmt.Fprintf(w, "   严重程度: %s\n", finding.Severity)
+			fmt.Fprintf(w, "   描述: %s\n", finding.Description)
+			if finding.Impact != "" {
+				fmt.Fprintf(w, "   影响: %s\n", finding.Impact)
+			}
+			if finding.ConfigFile != "" {
+				fmt.Fprintf(w, "   配置文件: %s\n", finding.ConfigFile)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(result.Suggestions) > 0 {
+		fmt.Fprintln(w, "建议:")
+		for i, suggestion := range result.Suggestions {
+			fmt.Fprintf(w, "\n%d. %s\n", i+1, suggestion.Title)
+			fmt.Fprintf(w, "   %s\n", suggestion.Details)
+		}
+		fmt.Fprintln(w)
+	}
+
+	clean := len(result.Findings) == 0 && len(result.Suggestions) == 0
+	if clean {
+		fmt.Fprintln(w, cleanNote(result))
+	}
+
+	if len(result.Skipped) > 0 {
+		if clean {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "未评估:")
+		for i, s := range result.Skipped {
+			fmt.Fprintf(w, "\n%d. %s\n", i+1, s.Case)
+			fmt.Fprintf(w, "   %s\n", s.Reason)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(result.Suppressed) > 0 {
+		if clean && len(result.Skipped) == 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "已豁免:")
+		for i, s := range result.Suppressed {
+			fmt.Fprintf(w, "\n%d. %s\n", i+1, s.Finding.Title)
+			fmt.Fprintf(w, "   案例: %s（%s）\n", s.Finding.ID, s.Finding.Severity)
+			fmt.Fprintf(w, "   %s\n", suppressionNote(s))
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,164 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// metricPrefix 为所有输出指标的名称前缀。
+const metricPrefix = "ossre_"
+
+// promFamily 为同名指标的一组样本，Prometheus 文本格式要求同名样本连续输出且只有一组 HELP/TYPE。
+type promFamily struct {
+	name    string
+	help    string
+	samples []promSample
+}
+
+type promSample struct {
+	labels [][2]string
+	value  float64
+}
+
+// writePrometheus 以 Prometheus 文本格式（0.0.4）输出诊断结果，可直接写入 node_exporter 的 textfile 目录。
+// 除各插件的数值证据（models.Metric）外，还输出按严重级别统计的发现数、被豁免的发现数、未评估的检查数、
+// 每条发现的存在标记与运行时间戳。
+// 所有指标均为 gauge。
+func writePrometheus(w io.Writer, meta Meta, results []models.Result) error {
+	var families []*promFamily
+	index := make(map[string]*promFamily)
+	add := func(name, help string, labels [][2]string, value float64) {
+		name = metricPrefix + sanitizeMetricName(name)
+		f, ok := index[name]
+		if !ok {
+			f = &promFamily{name: name, help: help}
+			index[name] = f
+			families = append(families, f)
+		}
+		f.samples = append(f.samples, promSample{labels: labels, value: value})
+	}
+
+	// 每个插件的所有严重级别都输出，计数为 0 时同样输出，便于告警规则区分“无问题”与“未运行”
+	for _, result := range results {
+		s := summarize(result)
+		for _, sev := range severities {
+			add("findings", "按插件与严重级别统计的发现数",
+				[][2]string{{"plugin", result.Plugin}, {"severity", string(sev)}}, float64(s.Counts[sev]))
+		}
+	}
+	for _, result := range results {
+		add("findings_suppressed", "按插件统计的被豁免发现数",
+			[][2]string{{"plugin", result.Plugin}}, float64(len(result.Suppressed)))
+	}
+	for _, result := range results {
+		add("checks_skipped", "按插件统计的未评估检查数（权限不足或数据不可读）",
+			[][2]string{{"plugin", result.Plugin}}, float64(len(result.Skipped)))
+	}
+	for _, result := range results {
+		for _, f := range result.Findings {
+			add("finding", "本次运行产出的发现，值恒为 1",
+				[][2]string{{"plugin", result.Plugin}, {"id", f.ID}, {"severity", string(f.Severity)}}, 1)
+		}
+	}
+	for _, result := range results {
+		for _, m := range result.Metrics {
+			labels := [][2]string{{"plugin", result.Plugin}}
+			keys := make([]string, 0, len(m.Labels))
+			for k := range m.Labels {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				labels = append(labels, [2]string{sanitizeMetricName(k), m.Labels[k]})
+			}
+			add(m.Name, m.Help, labels, m.Value)
+		}
+	}
+	if !meta.GeneratedAt.IsZero() {
+		add("last_run_timestamp_seconds", "生成本次诊断结果的 Unix 时间戳", nil,
+			float64(meta.GeneratedAt.UnixNano())/1e9)
+	}
+	if meta.Version != "" {
+		add("build_info", "ossre 版本信息，值恒为 1", [][2]string{{"version", meta.Version}}, 1)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		if f.help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", f.name)
+		for _, s := range f.samples {
+			bw.WriteString(f.name)
+			if len(s.labels) > 0 {
+				bw.WriteByte('{')
+				for i, l := range s.labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					fmt.Fprintf(bw, `%s="%s"`, l[0], escapeLabelValue(l[1]))
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteByte(' ')
+			bw.WriteString(formatMetricValue(s.value))
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// sanitizeMetricName 将名称中不符合 [a-zA-Z0-9_] 的字符替换为下划线，首字符为数字时加下划线前缀。
+func sanitizeMetricName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case v == math.Trunc(v) && math.Abs(v) < 1e15:
+		// 整数值按整数输出，避免 1.04857e+06 这样不便阅读的科学计数法；小数同样不使用科学计数法
+		return strconv.FormatInt(int64(v), 10)
+	default:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+}
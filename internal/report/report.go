@@ -0,0 +1,197 @@
+// Package report 将一次运行中各插件的诊断结果渲染为不同的输出格式，
+// 供终端阅读（plain）、程序处理（json）、附到工单与复盘文档中（markdown、html）、接入 CI（sarif、junit）以及接入监控（prometheus）。
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// 支持的输出格式。
+const (
+	FormatJSON       = "json"
+	FormatPlain      = "plain"
+	FormatMarkdown   = "markdown"
+	FormatHTML       = "html"
+	FormatSARIF      = "sarif"
+	FormatJUnit      = "junit"
+	FormatPrometheus = "prometheus"
+)
+
+// Formats 返回所有支持的输出格式名称。
+func Formats() []string {
+	return []string{FormatJSON, FormatPlain, FormatMarkdown, FormatHTML, FormatSARIF, FormatJUnit, FormatPrometheus}
+}
+
+// Meta 为报告头部展示的运行信息，字段为空时不展示。
+type Meta struct {
+	Version     string
+	Hostname    string
+	GeneratedAt time.Time
+	// Source 为数据来源说明，如离线分析时的快照包路径。
+	Source string
+}
+
+// Write 以 format 格式将 results 写入 w。
+// json 格式在只有一个插件时输出对象、多个插件时输出数组，与历史行为保持一致。
+func Write(w io.Writer, format string, meta Meta, results []models.Result) error {
+	results = normalize(results)
+	switch format {
+	case FormatJSON:
+		var v any = results
+		if len(results) == 1 {
+			v = results[0]
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case FormatPlain:
+		return writePlain(w, results)
+	case FormatMarkdown:
+		return writeMarkdown(w, meta, results)
+	case FormatHTML:
+		return writeHTML(w, meta, results)
+	case FormatSARIF:
+		return writeSARIF(w, meta, results)
+	case FormatJUnit:
+		return writeJUnit(w, meta, results)
+	case FormatPrometheus:
+		return writePrometheus(w, meta, results)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// ReadJSON 读取 json 格式的报告，兼容单个插件的对象与多个插件的数组两种形式。
+func ReadJSON(r io.Reader) ([]models.Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("empty report")
+	}
+	if trimmed[0] == '[' {
+		var results []models.Result
+		if err := json.Unmarshal(trimmed, &results); err != nil {
+			return nil, fmt.Errorf("parse report: %w", err)
+		}
+		return results, nil
+	}
+	var result models.Result
+	if err := json.Unmarshal(trimmed, &result); err != nil {
+		return nil, fmt.Errorf("parse report: %w", err)
+	}
+	return []models.Result{result}, nil
+}
+
+// Supported 判断 format 是否为支持的输出格式。
+func Supported(format string) bool {
+	for _, f := range Formats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize 确保空结果也序列化为 [] 而不是 null。
+func normalize(results []models.Result) []models.Result {
+	out := make([]models.Result, 0, len(results))
+	for _, result := range results {
+		if result.Findings == nil {
+			result.Findings = []models.Finding{}
+		}
+		if result.Suggestions == nil {
+			result.Suggestions = []models.Suggestion{}
+		}
+		out = append(out, result)
+	}
+	return out
+}
+
+// suppressionNote 返回豁免依据的单行说明。
+func suppressionNote(s models.SuppressedFinding) string {
+	note := fmt.Sprintf("豁免: %s，负责人 %s，原因: %s", s.Case, s.Owner, s.Reason)
+	if s.Expires != "" {
+		note += "，" + s.Expires + " 到期"
+	}
+	return note
+}
+
+// cleanNote 返回插件没有发现时的说明；存在未评估的检查时注明，以免被误读为全部通过。
+func cleanNote(result models.Result) string {
+	if n := len(result.Skipped); n > 0 {
+		return fmt.Sprintf("未发现问题，但有 %d 项检查未评估。", n)
+	}
+	return "未发现问题。"
+}
+
+// severities 为报告中按从高到低展示的严重级别。
+var severities = []models.Severity{
+	models.SeverityCritical,
+	models.SeverityError,
+	models.SeverityWarning,
+	models.SeverityInfo,
+}
+
+// summary 为单个插件的发现数量统计。
+type summary struct {
+	Plugin  string
+	Highest models.Severity
+	Counts  map[models.Severity]int
+	Total   int
+	// Skipped 为未评估的检查数。
+	Skipped int
+}
+
+func summarize(result models.Result) summary {
+	s := summary{Plugin: result.Plugin, Counts: make(map[models.Severity]int), Skipped: len(result.Skipped)}
+	for _, f := range result.Findings {
+		sev := f.Severity
+		if sev.Rank() == 0 {
+			sev = models.SeverityInfo
+		}
+		s.Counts[sev]++
+		s.Total++
+		if s.Highest == "" || sev.Rank() > s.Highest.Rank() {
+			s.Highest = sev
+		}
+	}
+	return s
+}
+
+// suggestionsFor 返回关联到 findingID 的建议。
+func suggestionsFor(result models.Result, findingID string) []models.Suggestion {
+	var out []models.Suggestion
+	for _, s := range result.Suggestions {
+		if s.FindingID == findingID {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// unlinkedSuggestions 返回未关联到任何发现的建议。
+func unlinkedSuggestions(result models.Result) []models.Suggestion {
+	ids := make(map[string]bool, len(result.Findings))
+	for _, f := range result.Findings {
+		ids[f.ID] = true
+	}
+	var out []models.Suggestion
+	for _, s := range result.Suggestions {
+		if !ids[s.FindingID] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,208 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// SARIF 2.1.0 的最小子集，字段命名遵循规范，仅包含 CI 平台展示所需的部分。
+// 参见 https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html。
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+	// Invocations 仅在存在未评估的检查时输出，以工具执行通知列出这些检查。
+	Invocations []sarifInvocation `json:"invocations,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool                `json:"executionSuccessful"`
+	Notifications       []sarifNotification `json:"toolExecutionNotifications"`
+}
+
+type sarifNotification struct {
+	Descriptor sarifDescriptorRef `json:"descriptor"`
+	Level      string             `json:"level"`
+	Message    sarifMessage       `json:"message"`
+}
+
+type sarifDescriptorRef struct {
+	ID string `json:"id"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	ShortDescription     sarifMessage      `json:"shortDescription"`
+	FullDescription      *sarifMessage     `json:"fullDescription,omitempty"`
+	Help                 *sarifMessage     `json:"help,omitempty"`
+	DefaultConfiguration sarifRuleConfig   `json:"defaultConfiguration"`
+	Properties           map[string]string `json:"properties,omitempty"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string            `json:"ruleId"`
+	RuleIndex int               `json:"ruleIndex"`
+	Level     string            `json:"level"`
+	Message   sarifMessage      `json:"message"`
+	Locations []sarifLocation   `json:"locations,omitempty"`
+	Props     map[string]string `json:"properties,omitempty"`
+	// Suppressions 非空表示结果已被豁免，CI 平台据此将其显示为已接受而非待处理。
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifLevel 将严重级别映射为 SARIF 的 level：critical/error 为 error，warning 为 warning，其余为 note。
+func sarifLevel(s models.Severity) string {
+	switch s {
+	case models.SeverityCritical, models.SeverityError:
+		return "error"
+	case models.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// writeSARIF 输出 SARIF 2.1.0 日志：每个案例 ID（Finding.ID）对应一条规则，每条发现对应一个结果，
+// Finding.ConfigFile 非空时作为结果的位置；被豁免的发现同样输出，并以 suppressions 标注豁免依据；
+// 未评估的检查不是结果，以 warning 级别的工具执行通知输出。
+func writeSARIF(w io.Writer, meta Meta, results []models.Result) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "ossre", Version: meta.Version, Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	ruleIndex := make(map[string]int)
+	add := func(result models.Result, f models.Finding, suppressions []sarifSuppression) {
+		id := f.ID
+		if id == "" {
+			id = result.Plugin
+		}
+		idx, ok := ruleIndex[id]
+		if !ok {
+			idx = len(run.Tool.Driver.Rules)
+			ruleIndex[id] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRuleFor(id, result, f))
+		}
+
+		text := f.Title
+		if f.Description != "" {
+			text += "\n\n" + f.Description
+		}
+		r := sarifResult{
+			RuleID:    id,
+			RuleIndex: idx,
+			Level:     sarifLevel(f.Severity),
+			Message:   sarifMessage{Text: text},
+			Props:     map[string]string{"plugin": result.Plugin, "severity": string(f.Severity)},
+		}
+		if f.ConfigFile != "" {
+			r.Locations = []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: fileURI(f.ConfigFile)}},
+			}}
+		}
+		r.Suppressions = suppressions
+		run.Results = append(run.Results, r)
+	}
+	for _, result := range results {
+		for _, f := range result.Findings {
+			add(result, f, nil)
+		}
+		for _, s := range result.Suppressed {
+			add(result, s.Finding, []sarifSuppression{{Kind: "external", Status: "accepted", Justification: suppressionNote(s)}})
+		}
+	}
+	var notifications []sarifNotification
+	for _, result := range results {
+		for _, s := range result.Skipped {
+			notifications = append(notifications, sarifNotification{
+				Descriptor: sarifDescriptorRef{ID: s.Case},
+				Level:      "warning",
+				Message:    sarifMessage{Text: "未评估: " + s.Reason},
+			})
+		}
+	}
+	if len(notifications) > 0 {
+		run.Invocations = []sarifInvocation{{ExecutionSuccessful: true, Notifications: notifications}}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// sarifRuleFor 以首次出现的发现描述规则，关联的建议作为规则的帮助文本。
+func sarifRuleFor(id string, result models.Result, f models.Finding) sarifRule {
+	rule := sarifRule{
+		ID:                   id,
+		ShortDescription:     sarifMessage{Text: f.Title},
+		DefaultConfiguration: sarifRuleConfig{Level: sarifLevel(f.Severity)},
+		Properties:           map[string]string{"plugin": result.Plugin},
+	}
+	if f.Impact != "" {
+		rule.FullDescription = &sarifMessage{Text: f.Impact}
+	}
+	var help []string
+	for _, s := range suggestionsFor(result, f.ID) {
+		help = append(help, s.Title+"\n"+s.Details)
+	}
+	if len(help) > 0 {
+		rule.Help = &sarifMessage{Text: strings.Join(help, "\n\n")}
+	}
+	return rule
+}
+
+// fileURI 将宿主机上的绝对路径转换为 file URI。
+func fileURI(p string) string {
+	if strings.HasPrefix(p, "/") {
+		return "file://" + p
+	}
+	return p
+}
@@ -0,0 +1,409 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/suppress"
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+const (
+	defaultMaxConcurrent = 2
+	defaultRunTimeout    = 5 * time.Minute
+	// maxRetainedRuns 为保留的已结束运行数，超出后淘汰最早的运行。
+	maxRetainedRuns = 100
+	maxRequestBody  = 1 << 20
+)
+
+// RunnerFactory 按给定选项创建 Runner。API 为每次运行单独创建 Runner，以便使用请求中的配置与进度回调。
+type RunnerFactory func(opts ...core.Option) *core.Runner
+
+// RunRequest 为 POST /api/v1/runs 的请求体，时长字段使用 Go duration 格式，如 "60s"、"5m"。
+type RunRequest struct {
+	Modules           []string `json:"modules"`
+	PID               int      `json:"pid,omitempty"`
+	ContainerID       string   `json:"container_id,omitempty"`
+	AllProcesses      bool     `json:"all_processes,omitempty"`
+	PIDSelector       string   `json:"pid_selector,omitempty"`
+	SampleWindow      string   `json:"sample_window,omitempty"`
+	SampleInterval    string   `json:"sample_interval,omitempty"`
+	ForecastThreshold string   `json:"forecast_threshold,omitempty"`
+	Top               int      `json:"top,omitempty"`
+	// Timeout 为本次运行的超时时间，不能超过服务端的上限，未指定时使用该上限。
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// PluginInfo 为 GET /api/v1/plugins 返回的插件信息。
+type PluginInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Version     string   `json:"version,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// API 以 HTTP/JSON 接口包装 core.Runner，支持远程列出插件、触发运行、查询结果与订阅进度。
+//
+//	GET  /api/v1/plugins            列出插件
+//	POST /api/v1/runs               触发运行，返回 202 与运行 ID
+//	GET  /api/v1/runs               列出保留的运行（不含结果）
+//	GET  /api/v1/runs/{id}          查询运行状态与结果
+//	GET  /api/v1/runs/{id}/events   以 Server-Sent Events 推送进度，运行结束后关闭
+type API struct {
+	newRunner     RunnerFactory
+	config        *config.Config
+	token         string
+	maxConcurrent int
+	runTimeout    time.Duration
+	logger        *slog.Logger
+	waivers       *waivers
+
+	// ctx 在 Close 时取消，用于中止所有进行中的运行。
+	ctx    context.Context
+	cancel context.CancelFunc
+	sem    chan struct{}
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	runs  map[string]*apiRun
+	order []string
+}
+
+// APIOption 用于定制 API。
+type APIOption func(*API)
+
+// WithToken 要求所有请求携带 "Authorization: Bearer <token>"，token 为空时不校验。
+func WithToken(token string) APIOption {
+	return func(a *API) {
+		a.token = token
+	}
+}
+
+// WithMaxConcurrent 指定同时进行的运行数上限，超出时新请求返回 429。
+func WithMaxConcurrent(n int) APIOption {
+	return func(a *API) {
+		if n > 0 {
+			a.maxConcurrent = n
+		}
+	}
+}
+
+// WithRunTimeout 指定单次运行的超时时间上限。
+func WithRunTimeout(d time.Duration) APIOption {
+	return func(a *API) {
+		if d > 0 {
+			a.runTimeout = d
+		}
+	}
+}
+
+// WithAPILogger 指定运行失败等事件的日志接口。
+func WithAPILogger(logger *slog.Logger) APIOption {
+	return func(a *API) {
+		if logger != nil {
+			a.logger = logger
+		}
+	}
+}
+
+// WithAPISuppressions 指定每次运行后应用的豁免列表，host、role 为匹配豁免时使用的主机名与角色。
+func WithAPISuppressions(list *suppress.List, host, role string) APIOption {
+	return func(a *API) {
+		a.waivers = newWaivers(list, host, role)
+	}
+}
+
+// NewAPI 创建 API，cfg 为每次运行的基础配置，请求中的采样等参数在其副本上覆盖。
+func NewAPI(newRunner RunnerFactory, cfg *config.Config, opts ...APIOption) *API {
+	if cfg == nil {
+		cfg = config.NewDefault()
+	}
+	a := &API{
+		newRunner:     newRunner,
+		config:        cfg,
+		maxConcurrent: defaultMaxConcurrent,
+		runTimeout:    defaultRunTimeout,
+		logger:        slog.New(slog.DiscardHandler),
+		runs:          make(map[string]*apiRun),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+	a.sem = make(chan struct{}, a.maxConcurrent)
+	return a
+}
+
+// Close 取消所有进行中的运行并等待其结束。
+func (a *API) Close() {
+	a.cancel()
+	a.wg.Wait()
+}
+
+// Handler 返回 API 的 HTTP 处理器，路由均位于 /api/v1/ 下。
+func (a *API) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/plugins", a.handlePlugins)
+	mux.HandleFunc("POST /api/v1/runs", a.handleCreateRun)
+	mux.HandleFunc("GET /api/v1/runs", a.handleListRuns)
+	mux.HandleFunc("GET /api/v1/runs/{id}", a.handleGetRun)
+	mux.HandleFunc("GET /api/v1/runs/{id}/events", a.handleEvents)
+	return a.authenticate(mux)
+}
+
+func (a *API) authenticate(next http.Handler) http.Handler {
+	if a.token == "" {
+		return next
+	}
+	want := []byte("Bearer " + a.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ossre"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *API) handlePlugins(w http.ResponseWriter, r *http.Request) {
+	runner := a.newRunner()
+	plugins := runner.ListPlugins()
+	out := make([]PluginInfo, 0, len(plugins))
+	for _, p := range plugins {
+		meta := runner.Metadata(p.Name())
+		out = append(out, PluginInfo{Name: p.Name(), Description: p.Description(), Version: meta.Version, Tags: meta.Tags})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (a *API) handleCreateRun(w http.ResponseWriter, r *http.Request) {
+	var req RunRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	cfg, target, timeout, err := a.prepare(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	select {
+	case a.sem <- struct{}{}:
+	default:
+		writeError(w, http.StatusTooManyRequests, fmt.Sprintf("too many concurrent runs (limit %d)", a.maxConcurrent))
+		return
+	}
+
+	run := newAPIRun(newRunID(), req)
+	a.store(run)
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		defer func() { <-a.sem }()
+		a.execute(run, req.Modules, cfg, target, timeout)
+	}()
+
+	w.Header().Set("Location", "/api/v1/runs/"+run.info.ID)
+	writeJSON(w, http.StatusAccepted, run.snapshot(false))
+}
+
+// prepare 校验请求并在基础配置的副本上应用请求参数。
+func (a *API) prepare(req RunRequest) (*config.Config, core.Target, time.Duration, error) {
+	if len(req.Modules) == 0 {
+		return nil, core.Target{}, 0, errors.New("modules is required")
+	}
+	known := make(map[string]bool)
+	for _, p := range a.newRunner().ListPlugins() {
+		known[p.Name()] = true
+	}
+	for _, name := range req.Modules {
+		if !known[name] {
+			return nil, core.Target{}, 0, fmt.Errorf("unknown module: %s", name)
+		}
+	}
+
+	cfg := *a.config
+	timeout := a.runTimeout
+	for _, d := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"sample_window", req.SampleWindow, &cfg.Sampling.Window},
+		{"sample_interval", req.SampleInterval, &cfg.Sampling.Interval},
+		{"forecast_threshold", req.ForecastThreshold, &cfg.Sampling.ForecastThreshold},
+		{"timeout", req.Timeout, &timeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v < 0 {
+			return nil, core.Target{}, 0, fmt.Errorf("invalid %s: %q", d.name, d.value)
+		}
+		*d.dst = v
+	}
+	if timeout <= 0 || timeout > a.runTimeout {
+		return nil, core.Target{}, 0, fmt.Errorf("timeout must be between 0 and %s", a.runTimeout)
+	}
+	if cfg.Sampling.Window >= timeout {
+		return nil, core.Target{}, 0, fmt.Errorf("sample_window %s must be shorter than timeout %s", cfg.Sampling.Window, timeout)
+	}
+	if req.Top > 0 {
+		cfg.Scan.Top = req.Top
+	}
+
+	target := core.Target{AllProcesses: req.AllProcesses, PIDSelector: req.PIDSelector, ContainerID: req.ContainerID}
+	if req.PID > 0 {
+		target.PIDs = []int{req.PID}
+	}
+	return &cfg, target, timeout, nil
+}
+
+func (a *API) execute(run *apiRun, modules []string, cfg *config.Config, target core.Target, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(a.ctx, timeout)
+	defer cancel()
+
+	r := a.newRunner(core.WithConfig(cfg), core.WithObserver(run.observe))
+	runResults, err := r.RunAll(ctx, modules, target)
+	// 插件在超时后可能提前返回部分结果而不报错，此时同样视为失败
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		a.logger.Warn("api run failed", "id", run.info.ID, "modules", modules, "error", err)
+	}
+	results := make([]models.Result, 0, len(runResults))
+	for _, rr := range runResults {
+		results = append(results, rr.Result)
+	}
+	run.finish(a.waivers.apply(results), err)
+}
+
+// store 保存新运行，并在超出保留数量时淘汰最早的已结束运行。
+func (a *API) store(run *apiRun) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.runs[run.info.ID] = run
+	a.order = append(a.order, run.info.ID)
+	for i := 0; len(a.order) > maxRetainedRuns && i < len(a.order); {
+		id := a.order[i]
+		if a.runs[id].done() {
+			delete(a.runs, id)
+			a.order = append(a.order[:i], a.order[i+1:]...)
+			continue
+		}
+		i++
+	}
+}
+
+func (a *API) lookup(id string) *apiRun {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.runs[id]
+}
+
+func (a *API) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	runs := make([]*apiRun, 0, len(a.order))
+	for i := len(a.order) - 1; i >= 0; i-- {
+		runs = append(runs, a.runs[a.order[i]])
+	}
+	a.mu.Unlock()
+
+	out := make([]RunInfo, 0, len(runs))
+	for _, run := range runs {
+		out = append(out, run.snapshot(false))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (a *API) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	run := a.lookup(r.PathValue("id"))
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, run.snapshot(true))
+}
+
+// handleEvents 以 Server-Sent Events 推送进度：先补发已有事件，再推送新事件，直到运行结束。
+// 断线重连时客户端携带的 Last-Event-ID 用于跳过已收到的事件。
+func (a *API) handleEvents(w http.ResponseWriter, r *http.Request) {
+	run := a.lookup(r.PathValue("id"))
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	next := 0
+	if last, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil {
+		next = last + 1
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		events, changed := run.eventsSince(next)
+		for _, e := range events {
+			data, _ := json.Marshal(e)
+			event := "progress"
+			if e.Phase == phaseDone {
+				event = phaseDone
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, event, data)
+			next = e.Seq + 1
+			if e.Phase == phaseDone {
+				flusher.Flush()
+				return
+			}
+		}
+		flusher.Flush()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		}
+	}
+}
+
+func newRunID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": strings.TrimSpace(msg)})
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// RunState 为一次 API 触发的诊断运行所处的状态。
+type RunState string
+
+const (
+	RunRunning   RunState = "running"
+	RunSucceeded RunState = "succeeded"
+	RunFailed    RunState = "failed"
+)
+
+// RunInfo 为 GET /api/v1/runs/{id} 返回的运行状态，Results 仅在运行结束后出现。
+// 部分模块失败时 Status 为 failed，已完成模块的结果仍在 Results 中。
+type RunInfo struct {
+	ID         string          `json:"id"`
+	Status     RunState        `json:"status"`
+	Request    RunRequest      `json:"request"`
+	CreatedAt  time.Time       `json:"created_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Results    []models.Result `json:"results,omitempty"`
+}
+
+// ProgressEvent 为 GET /api/v1/runs/{id}/events 推送的进度事件。
+// Phase 为 started、finished、failed 时对应单个插件，为 done 时表示整个运行结束，Status 为最终状态。
+type ProgressEvent struct {
+	Seq      int       `json:"seq"`
+	Time     time.Time `json:"time"`
+	Phase    string    `json:"phase"`
+	Plugin   string    `json:"plugin,omitempty"`
+	Elapsed  string    `json:"elapsed,omitempty"`
+	Findings int       `json:"findings,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Status   RunState  `json:"status,omitempty"`
+}
+
+// phaseDone 为运行结束事件的阶段名称。
+const phaseDone = "done"
+
+// apiRun 保存一次运行的状态与事件记录，事件追加后关闭 changed 通知所有等待中的订阅者。
+type apiRun struct {
+	mu      sync.Mutex
+	info    RunInfo
+	events  []ProgressEvent
+	changed chan struct{}
+}
+
+func newAPIRun(id string, req RunRequest) *apiRun {
+	return &apiRun{
+		info: RunInfo{
+			ID:        id,
+			Status:    RunRunning,
+			Request:   req,
+			CreatedAt: time.Now(),
+		},
+		changed: make(chan struct{}),
+	}
+}
+
+// observe 将 Runner 的插件事件转换为进度事件，作为 core.WithObserver 的回调。
+func (r *apiRun) observe(e core.Event) {
+	pe := ProgressEvent{Phase: string(e.Phase), Plugin: e.Plugin, Findings: e.Findings}
+	if e.Phase != core.EventStarted {
+		pe.Elapsed = e.Elapsed.String()
+	}
+	if e.Err != nil {
+		pe.Error = e.Err.Error()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.publishLocked(pe)
+}
+
+// finish 记录运行结果并推送结束事件。
+func (r *apiRun) finish(results []models.Result, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.info.FinishedAt = &now
+	r.info.Results = results
+	r.info.Status = RunSucceeded
+	if err != nil {
+		r.info.Status = RunFailed
+		r.info.Error = err.Error()
+	}
+	r.publishLocked(ProgressEvent{Phase: phaseDone, Status: r.info.Status, Error: r.info.Error})
+}
+
+func (r *apiRun) publishLocked(e ProgressEvent) {
+	e.Seq = len(r.events)
+	e.Time = time.Now()
+	r.events = append(r.events, e)
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// snapshot 返回当前状态的副本，withResults 为 false 时不包含结果，用于列表展示。
+func (r *apiRun) snapshot(withResults bool) RunInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info := r.info
+	if !withResults {
+		info.Results = nil
+	}
+	return info
+}
+
+func (r *apiRun) done() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.info.Status != RunRunning
+}
+
+// eventsSince 返回序号不小于 next 的事件；没有新事件时可等待返回的 changed 通道。
+func (r *apiRun) eventsSince(next int) ([]ProgressEvent, <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if next < 0 {
+		next = 0
+	}
+	var events []ProgressEvent
+	if next < len(r.events) {
+		events = append(events, r.events[next:]...)
+	}
+	return events, r.changed
+}
@@ -0,0 +1,173 @@
+// Package server 实现 ossre serve 常驻模式：Scheduler 按固定周期运行诊断插件并暴露 Prometheus 指标，
+// API 以 HTTP/JSON 接口供远程触发诊断、查询结果与订阅进度。
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/report"
+	"github.com/supperghost/ossre/internal/suppress"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// Scheduler 按固定周期运行一组插件，缓存最近一次渲染好的 Prometheus 指标。
+// 抓取 /metrics 时直接返回缓存，不触发诊断，因此抓取频率不会放大对主机的读取压力。
+type Scheduler struct {
+	runner   *core.Runner
+	modules  []string
+	interval time.Duration
+	target   core.Target
+	meta     report.Meta
+	logger   *slog.Logger
+	waivers  *waivers
+
+	mu      sync.RWMutex
+	metrics []byte
+}
+
+// SchedulerOption 用于定制 Scheduler。
+type SchedulerOption func(*Scheduler)
+
+// WithTarget 指定每次运行的诊断目标，未指定时由各插件回退为 ossre 自身。
+func WithTarget(target core.Target) SchedulerOption {
+	return func(s *Scheduler) {
+		s.target = target
+	}
+}
+
+// WithMeta 指定指标中输出的版本、主机名等运行信息，GeneratedAt 由每次运行的开始时间覆盖。
+func WithMeta(meta report.Meta) SchedulerOption {
+	return func(s *Scheduler) {
+		s.meta = meta
+	}
+}
+
+// WithLogger 指定运行失败等事件的日志接口。
+func WithLogger(logger *slog.Logger) SchedulerOption {
+	return func(s *Scheduler) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// WithSuppressions 指定每次运行后应用的豁免列表，host、role 为匹配豁免时使用的主机名与角色。
+func WithSuppressions(list *suppress.List, host, role string) SchedulerOption {
+	return func(s *Scheduler) {
+		s.waivers = newWaivers(list, host, role)
+	}
+}
+
+// waivers 保存 serve 加载的豁免列表及匹配时使用的主机名与角色，定时运行与 API 运行共用同一份。
+type waivers struct {
+	list       *suppress.List
+	host, role string
+}
+
+func newWaivers(list *suppress.List, host, role string) *waivers {
+	if list == nil {
+		return nil
+	}
+	return &waivers{list: list, host: host, role: role}
+}
+
+// apply 将被有效豁免的发现移到 Suppressed；未配置豁免时原样返回。
+func (w *waivers) apply(results []models.Result) []models.Result {
+	if w == nil {
+		return results
+	}
+	return w.list.Apply(results, w.host, w.role, time.Now())
+}
+
+// NewScheduler 创建以 interval 为周期运行 modules 的 Scheduler，需调用 Run 启动。
+func NewScheduler(runner *core.Runner, modules []string, interval time.Duration, opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		runner:   runner,
+		modules:  modules,
+		interval: interval,
+		logger:   slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run 立即运行一次，随后每隔 interval 运行一次，直到 ctx 取消。
+// 单次运行失败只记录日志，不中断调度。
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.RunOnce(ctx); err != nil {
+			s.logger.Warn("scheduled run failed", "modules", s.modules, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 运行一次所有插件并更新缓存的指标，单次运行的超时时间为一个周期。
+// 部分插件失败时，已完成插件的结果仍会更新，ossre_run_success 为 0。
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.interval)
+	defer cancel()
+
+	start := time.Now()
+	runResults, runErr := s.runner.RunAll(ctx, s.modules, s.target)
+	elapsed := time.Since(start)
+
+	results := make([]models.Result, 0, len(runResults))
+	for _, rr := range runResults {
+		results = append(results, rr.Result)
+	}
+	results = s.waivers.apply(results)
+	meta := s.meta
+	meta.GeneratedAt = start
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf, report.FormatPrometheus, meta, results); err != nil {
+		return fmt.Errorf("render metrics: %w", err)
+	}
+	success := 1
+	if runErr != nil {
+		success = 0
+	}
+	fmt.Fprintf(&buf, "# HELP ossre_run_duration_seconds 最近一次定时诊断的耗时\n# TYPE ossre_run_duration_seconds gauge\nossre_run_duration_seconds %s\n",
+		strconv.FormatFloat(elapsed.Seconds(), 'f', -1, 64))
+	fmt.Fprintf(&buf, "# HELP ossre_run_success 最近一次定时诊断是否全部成功（1 为成功）\n# TYPE ossre_run_success gauge\nossre_run_success %d\n", success)
+
+	s.mu.Lock()
+	s.metrics = buf.Bytes()
+	s.mu.Unlock()
+	return runErr
+}
+
+// ServeHTTP 返回最近一次运行的指标，首次运行完成前返回 503。
+func (s *Scheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.RLock()
+	metrics := s.metrics
+	s.mu.RUnlock()
+	if metrics == nil {
+		http.Error(w, "first run has not completed yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(metrics)
+}
@@ -0,0 +1,267 @@
+// Package suppress 实现发现豁免：豁免文件按案例 ID 模式（可限定主机名与角色）列出已知并接受的发现，
+// 匹配的发现从 Findings 移到 Suppressed 单独展示而不是被丢弃；到期的豁免不再生效，并产生一条提示发现。
+package suppress
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// ExpiredFindingID 为豁免过期提示发现的案例 ID。
+const ExpiredFindingID = "suppress.waiver.expired"
+
+// dateLayout 为到期日的格式。
+const dateLayout = "2006-01-02"
+
+// Entry 为一条豁免。
+type Entry struct {
+	// Case 为案例 ID 模式，支持 path.Match 的通配符，如 kernel.net.baseline.sysctl.*。
+	Case string
+	// Hosts 与 Roles 为可选的主机名模式与角色，均为空时对所有主机生效，非空时需分别匹配其一。
+	Hosts []string
+	Roles []string
+	// Reason 与 Owner 为豁免原因与负责人，均为必填。
+	Reason string
+	Owner  string
+	// Expires 为到期日，当天仍有效；为零值时长期有效。
+	Expires time.Time
+}
+
+// expired 表示豁免在 now 时是否已过期。
+func (e *Entry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires.AddDate(0, 0, 1))
+}
+
+func (e *Entry) expiresString() string {
+	if e.Expires.IsZero() {
+		return ""
+	}
+	return e.Expires.Format(dateLayout)
+}
+
+// matches 表示豁免是否适用于主机 host、角色 role 上的案例 caseID，不考虑是否过期。
+func (e *Entry) matches(caseID, host, role string) bool {
+	if ok, _ := path.Match(e.Case, caseID); !ok {
+		return false
+	}
+	if len(e.Hosts) > 0 {
+		ok := false
+		for _, h := range e.Hosts {
+			m, _ := path.Match(h, host)
+			ok = ok || m
+		}
+		if !ok {
+			return false
+		}
+	}
+	if len(e.Roles) > 0 {
+		ok := false
+		for _, r := range e.Roles {
+			ok = ok || r == role
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// List 为从豁免文件加载的全部豁免，按文件中的顺序匹配。
+type List struct {
+	File    string
+	Entries []*Entry
+}
+
+// Load 加载豁免文件，文件不存在时返回空列表。文件的顶层为 suppressions 序列。
+func Load(file string) (*List, error) {
+	l := &List{File: file}
+	if file == "" {
+		return l, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return l, nil
+		}
+		return nil, err
+	}
+	tree, err := config.ParseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	root, ok := tree.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: top level must be a mapping with a suppressions list", file)
+	}
+	list, ok := root["suppressions"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: suppressions must be a list", file)
+	}
+	for i, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: suppressions[%d] must be a mapping", file, i)
+		}
+		e, err := parseEntry(m)
+		if err != nil {
+			return nil, fmt.Errorf("%s: suppressions[%d]: %w", file, i, err)
+		}
+		l.Entries = append(l.Entries, e)
+	}
+	return l, nil
+}
+
+func parseEntry(m map[string]any) (*Entry, error) {
+	str := func(key string) string {
+		s, _ := m[key].(string)
+		return s
+	}
+	e := &Entry{Case: str("case"), Reason: str("reason"), Owner: str("owner")}
+	if e.Case == "" {
+		return nil, fmt.Errorf("case is required")
+	}
+	if _, err := path.Match(e.Case, ""); err != nil {
+		return nil, fmt.Errorf("%s: invalid case pattern", e.Case)
+	}
+	if e.Reason == "" || e.Owner == "" {
+		return nil, fmt.Errorf("%s: reason and owner are required", e.Case)
+	}
+	var err error
+	if e.Hosts, err = stringList(m, "hosts"); err != nil {
+		return nil, fmt.Errorf("%s: %w", e.Case, err)
+	}
+	for _, h := range e.Hosts {
+		if _, err := path.Match(h, ""); err != nil {
+			return nil, fmt.Errorf("%s: invalid host pattern %q", e.Case, h)
+		}
+	}
+	if e.Roles, err = stringList(m, "roles"); err != nil {
+		return nil, fmt.Errorf("%s: %w", e.Case, err)
+	}
+	if v := str("expires"); v != "" {
+		t, err := time.ParseInLocation(dateLayout, v, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expires must be a date such as 2026-12-31", e.Case)
+		}
+		e.Expires = t
+	}
+	return e, nil
+}
+
+// stringList 读取字符串或字符串列表形式的键。
+func stringList(m map[string]any, key string) ([]string, error) {
+	switch v := m[key].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		var out []string
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", key)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%s must be a list of strings", key)
+	}
+}
+
+// Apply 将主机 host、角色 role 上被有效豁免的发现及其建议移到 Suppressed，每条发现使用第一条有效的豁免。
+// 只有已过期的豁免匹配的发现仍计入 Findings，并在该插件的结果中追加一条 ExpiredFindingID 提示发现。
+func (l *List) Apply(results []models.Result, host, role string, now time.Time) []models.Result {
+	if len(l.Entries) == 0 {
+		return results
+	}
+	out := make([]models.Result, 0, len(results))
+	for _, result := range results {
+		var (
+			findings []models.Finding
+			moved    = make(map[string]int)
+			expired  []*Entry
+			matched  = make(map[*Entry][]string)
+		)
+		for _, f := range result.Findings {
+			var valid *Entry
+			var stale []*Entry
+			for _, e := range l.Entries {
+				if !e.matches(f.ID, host, role) {
+					continue
+				}
+				if !e.expired(now) {
+					valid = e
+					break
+				}
+				stale = append(stale, e)
+			}
+			if valid == nil {
+				findings = append(findings, f)
+				for _, e := range stale {
+					if matched[e] == nil {
+						expired = append(expired, e)
+					}
+					matched[e] = append(matched[e], f.ID)
+				}
+				continue
+			}
+			moved[f.ID] = len(result.Suppressed)
+			result.Suppressed = append(result.Suppressed, models.SuppressedFinding{
+				Finding: f,
+				Case:    valid.Case,
+				Reason:  valid.Reason,
+				Owner:   valid.Owner,
+				Expires: valid.expiresString(),
+			})
+		}
+
+		var suggestions []models.Suggestion
+		for _, s := range result.Suggestions {
+			if i, ok := moved[s.FindingID]; ok {
+				result.Suppressed[i].Suggestions = append(result.Suppressed[i].Suggestions, s)
+				continue
+			}
+			suggestions = append(suggestions, s)
+		}
+		if len(expired) > 0 {
+			f, s := l.expiredFinding(expired, matched)
+			findings = append(findings, f)
+			suggestions = append(suggestions, s)
+		}
+		result.Findings, result.Suggestions = findings, suggestions
+		out = append(out, result)
+	}
+	return out
+}
+
+// expiredFinding 汇总一个插件结果中匹配到发现的过期豁免。
+func (l *List) expiredFinding(expired []*Entry, matched map[*Entry][]string) (models.Finding, models.Suggestion) {
+	var lines []string
+	for _, e := range expired {
+		lines = append(lines, fmt.Sprintf("%s（负责人 %s，%s 到期，原因: %s）匹配 %s",
+			e.Case, e.Owner, e.expiresString(), e.Reason, strings.Join(matched[e], ", ")))
+	}
+	f := models.Finding{
+		ID:          ExpiredFindingID,
+		Title:       fmt.Sprintf("%d 条豁免已过期，对应发现重新计入结果", len(expired)),
+		Description: strings.Join(lines, "\n"),
+		Severity:    models.SeverityWarning,
+		Impact:      "豁免到期后未复核，被接受的风险可能已经变化。",
+		ConfigFile:  l.File,
+	}
+	s := models.Suggestion{
+		FindingID: ExpiredFindingID,
+		Title:     "复核过期的豁免",
+		Details:   fmt.Sprintf("确认风险仍可接受后在 %s 中延长 expires，或修复问题后删除对应条目。", l.File),
+	}
+	return f, s
+}
@@ -4,19 +4,89 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config 表示框架的运行时配置。
-// TODO: 根据实际需求将字段拆分为更细的结构体，并补充 YAML/TOML 标签。
 type Config struct {
 	// 原始文件路径，仅用于调试。
 	Source string
 	// 原始配置内容的占位字段，后续可替换为结构化字段。
 	Raw []byte
+
+	// Paths 为 proc/sys/etc 的实际挂载位置。
+	Paths PathsConfig
+	// Sampling 为趋势采样相关配置。
+	Sampling SamplingConfig
+	// Scan 为全主机进程扫描相关配置。
+	Scan ScanConfig
+	// Rules 为声明式检查规则相关配置。
+	Rules RulesConfig
+	// Plugins 为外部插件相关配置。
+	Plugins PluginsConfig
+	// Suppress 为发现豁免相关配置。
+	Suppress SuppressConfig
+}
+
+// PathsConfig 描述诊断时读取的 /proc、/sys、/etc 在当前文件系统中的实际位置。
+// 在特权 sidecar 容器中诊断宿主机时，可将其指向宿主机的挂载点（如 /host/proc）。
+type PathsConfig struct {
+	ProcRoot string
+	SysRoot  string
+	EtcRoot  string
+}
+
+// SetRoot 将 proc/sys/etc 统一设置为 root 下的同名目录，root 为空或 "/" 时恢复默认值。
+func (p *PathsConfig) SetRoot(root string) {
+	root = strings.TrimRight(root, "/")
+	p.ProcRoot = root + "/proc"
+	p.SysRoot = root + "/sys"
+	p.EtcRoot = root + "/etc"
+}
+
+// SamplingConfig 控制 maxproc/maxfd 等插件的趋势采样行为。
+type SamplingConfig struct {
+	// Window 为采样窗口，为 0 时仅做单次快照评估。
+	Window time.Duration
+	// Interval 为采样间隔。
+	Interval time.Duration
+	// ForecastThreshold 为预计耗尽时间的告警阈值，低于该值时提升严重级别。
+	ForecastThreshold time.Duration
 }
 
-// LoadFromFile 从给定路径加载配置文件。
-// 当前实现仅读取原始字节，不解析 YAML/TOML，后续可在不引入第三方依赖的前提下补充解析逻辑。
+// ScanConfig 控制全主机进程扫描的并发与输出规模。
+type ScanConfig struct {
+	// Workers 为并发评估进程的 worker 数，为 0 时使用 CPU 核数。
+	Workers int
+	// Top 为排名表展示的进程数。
+	Top int
+}
+
+// DefaultRulesDir 为声明式检查规则的默认目录。
+const DefaultRulesDir = "/etc/ossre/rules.d"
+
+// RulesConfig 控制 rules 插件加载的规则文件。
+type RulesConfig struct {
+	// Dirs 为规则目录，按顺序加载其中的 *.yaml 与 *.yml 文件。
+	Dirs []string
+}
+
+// DefaultPluginsDir 为外部插件的默认目录。
+const DefaultPluginsDir = "/usr/local/libexec/ossre/plugins"
+
+// PluginsConfig 控制外部（exec）插件的发现与运行。
+type PluginsConfig struct {
+	// Dir 为外部插件目录，其中的每个可执行文件为一个插件；为空时不加载外部插件。
+	Dir string
+	// Timeout 为单次运行外部插件的超时时间。
+	Timeout time.Duration
+	// User 为运行外部插件的用户名，为空时与 ossre 相同。
+	User string
+}
+
+// LoadFromFile 从给定路径加载 YAML 配置文件，未出现的键保留默认值，未知的键被忽略。
 func LoadFromFile(path string) (*Config, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -29,14 +99,194 @@ func LoadFromFile(path string) (*Config, error) {
 		return nil, fmt.Errorf("read config file: %w", err)
 	}
 
-	return &Config{
-		Source: path,
-		Raw:    data,
-	}, nil
+	cfg := NewDefault()
+	cfg.Source = path
+	cfg.Raw = data
+
+	tree, err := ParseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	if err := cfg.apply(tree); err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return cfg, nil
 }
 
-// NewDefault 返回一个占位的默认配置实例。
-// TODO: 根据项目需求填充合理的默认值。
+// NewDefault 返回带有默认值的配置实例。
 func NewDefault() *Config {
-	return &Config{}
+	cfg := &Config{
+		Sampling: SamplingConfig{
+			Interval:          5 * time.Second,
+			ForecastThreshold: time.Hour,
+		},
+		Scan: ScanConfig{
+			Top: 10,
+		},
+		Rules: RulesConfig{
+			Dirs: []string{DefaultRulesDir},
+		},
+		Plugins: PluginsConfig{
+			Dir:     DefaultPluginsDir,
+			Timeout: 30 * time.Second,
+		},
+		Suppress: SuppressConfig{
+			File: DefaultSuppressFile,
+		},
+	}
+	cfg.Paths.SetRoot("")
+	return cfg
+}
+
+// DefaultSuppressFile 为豁免文件的默认路径。
+const DefaultSuppressFile = "/etc/ossre/suppressions.yaml"
+
+// SuppressConfig 控制按案例 ID 豁免已知并接受的发现。
+type SuppressConfig struct {
+	// File 为豁免文件路径，文件不存在时不豁免任何发现。
+	File string
+	// Role 为本机的角色（如 frontend、db），与豁免条目中的 roles 匹配。
+	Role string
+}
+
+// apply 将解析后的 YAML 树写入配置字段。
+func (c *Config) apply(tree any) error {
+	if tree == nil {
+		return nil
+	}
+	root, ok := tree.(map[string]any)
+	if !ok {
+		return fmt.Errorf("top level must be a mapping")
+	}
+
+	if paths, err := section(root, "paths"); err != nil {
+		return err
+	} else if paths != nil {
+		if v, ok := paths["root"].(string); ok && v != "" {
+			c.Paths.SetRoot(v)
+		}
+		for key, dst := range map[string]*string{
+			"proc_root": &c.Paths.ProcRoot,
+			"sys_root":  &c.Paths.SysRoot,
+			"etc_root":  &c.Paths.EtcRoot,
+		} {
+			if v, ok := paths[key].(string); ok && v != "" {
+				*dst = v
+			}
+		}
+	}
+
+	if sampling, err := section(root, "sampling"); err != nil {
+		return err
+	} else if sampling != nil {
+		for key, dst := range map[string]*time.Duration{
+			"window":             &c.Sampling.Window,
+			"interval":           &c.Sampling.Interval,
+			"forecast_threshold": &c.Sampling.ForecastThreshold,
+		} {
+			if err := setDuration(sampling, key, dst); err != nil {
+				return fmt.Errorf("sampling.%w", err)
+			}
+		}
+	}
+
+	if scan, err := section(root, "scan"); err != nil {
+		return err
+	} else if scan != nil {
+		for key, dst := range map[string]*int{
+			"workers": &c.Scan.Workers,
+			"top":     &c.Scan.Top,
+		} {
+			if err := setInt(scan, key, dst); err != nil {
+				return fmt.Errorf("scan.%w", err)
+			}
+		}
+	}
+
+	if rules, err := section(root, "rules"); err != nil {
+		return err
+	} else if rules != nil {
+		switch v := rules["dirs"].(type) {
+		case nil:
+		case string:
+			c.Rules.Dirs = []string{v}
+		case []any:
+			c.Rules.Dirs = nil
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("rules.dirs must be a list of paths")
+				}
+				c.Rules.Dirs = append(c.Rules.Dirs, s)
+			}
+		default:
+			return fmt.Errorf("rules.dirs must be a list of paths")
+		}
+	}
+
+	if plugins, err := section(root, "plugins"); err != nil {
+		return err
+	} else if plugins != nil {
+		if v, ok := plugins["dir"].(string); ok {
+			c.Plugins.Dir = v
+		}
+		if v, ok := plugins["user"].(string); ok {
+			c.Plugins.User = v
+		}
+		if err := setDuration(plugins, "timeout", &c.Plugins.Timeout); err != nil {
+			return fmt.Errorf("plugins.%w", err)
+		}
+	}
+
+	if suppress, err := section(root, "suppress"); err != nil {
+		return err
+	} else if suppress != nil {
+		if v, ok := suppress["file"].(string); ok {
+			c.Suppress.File = v
+		}
+		if v, ok := suppress["role"].(string); ok {
+			c.Suppress.Role = v
+		}
+	}
+
+	return nil
+}
+
+// section 返回顶层映射中名为 name 的子映射，不存在时返回 nil。
+func section(root map[string]any, name string) (map[string]any, error) {
+	v, ok := root[name]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a mapping", name)
+	}
+	return m, nil
+}
+
+func setDuration(m map[string]any, key string, dst *time.Duration) error {
+	s, ok := m[key].(string)
+	if !ok || s == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = d
+	return nil
+}
+
+func setInt(m map[string]any, key string, dst *int) error {
+	s, ok := m[key].(string)
+	if !ok || s == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = n
+	return nil
 }
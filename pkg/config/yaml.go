@@ -0,0 +1,350 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseYAML 解析 YAML 的一个常用子集，不引入第三方依赖。
+// 支持：嵌套映射、序列（含“- key: value”形式的映射元素）、单/双引号字符串、
+// 行内序列 [a, b]、块字面量（| 与 |-）以及 # 注释；不支持锚点、多文档与行内映射。
+// 返回值由 map[string]any、[]any、string 与 nil 组成，标量一律保留为字符串，由调用方按需转换。
+func ParseYAML(data []byte) (any, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		p.lines = append(p.lines, yamlLine{num: i + 1, raw: raw})
+	}
+	for i := range p.lines {
+		l := &p.lines[i]
+		if lead := l.raw[:len(l.raw)-len(strings.TrimLeft(l.raw, " \t"))]; strings.Contains(lead, "\t") {
+			return nil, fmt.Errorf("yaml line %d: tabs are not allowed for indentation", l.num)
+		}
+		text := stripYAMLComment(l.raw)
+		trimmed := strings.TrimLeft(text, " ")
+		l.indent = len(text) - len(trimmed)
+		l.text = strings.TrimRight(trimmed, " ")
+	}
+
+	p.skipBlank()
+	if p.eof() {
+		return nil, nil
+	}
+	node, err := p.parseNode(p.cur().indent)
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if !p.eof() {
+		return nil, fmt.Errorf("yaml line %d: unexpected indentation", p.cur().num)
+	}
+	return node, nil
+}
+
+type yamlLine struct {
+	num    int
+	raw    string
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) eof() bool      { return p.pos >= len(p.lines) }
+func (p *yamlParser) cur() *yamlLine { return &p.lines[p.pos] }
+func (p *yamlParser) skipBlank() {
+	for !p.eof() && (p.cur().text == "" || p.cur().text == "---") {
+		p.pos++
+	}
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) parseNode(indent int) (any, error) {
+	if isSeqItem(p.cur().text) {
+		return p.parseSeq(indent)
+	}
+	return p.parseMap(indent)
+}
+
+func (p *yamlParser) parseMap(indent int) (map[string]any, error) {
+	m := make(map[string]any)
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return m, nil
+		}
+		l := p.cur()
+		if l.indent < indent {
+			return m, nil
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("yaml line %d: unexpected indentation", l.num)
+		}
+		if isSeqItem(l.text) {
+			return nil, fmt.Errorf("yaml line %d: unexpected sequence item in mapping", l.num)
+		}
+
+		key, rest, err := splitYAMLKey(l.text)
+		if err != nil {
+			return nil, fmt.Errorf("yaml line %d: %w", l.num, err)
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("yaml line %d: duplicate key %q", l.num, key)
+		}
+		p.pos++
+
+		switch {
+		case rest == "|" || rest == "|-":
+			m[key] = p.parseBlockLiteral(indent, rest == "|-")
+		case rest != "":
+			v, err := parseYAMLScalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("yaml line %d: %w", l.num, err)
+			}
+			m[key] = v
+		default:
+			p.skipBlank()
+			switch {
+			case p.eof():
+				m[key] = nil
+			case p.cur().indent > indent:
+				v, err := p.parseNode(p.cur().indent)
+				if err != nil {
+					return nil, err
+				}
+				m[key] = v
+			case p.cur().indent == indent && isSeqItem(p.cur().text):
+				// 允许序列与父键同缩进，如 "key:\n- a"
+				v, err := p.parseSeq(indent)
+				if err != nil {
+					return nil, err
+				}
+				m[key] = v
+			default:
+				m[key] = nil
+			}
+		}
+	}
+}
+
+func (p *yamlParser) parseSeq(indent int) ([]any, error) {
+	var seq []any
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return seq, nil
+		}
+		l := p.cur()
+		if l.indent < indent || !isSeqItem(l.text) {
+			if l.indent > indent {
+				return nil, fmt.Errorf("yaml line %d: unexpected indentation", l.num)
+			}
+			return seq, nil
+		}
+		if l.indent > indent {
+			return nil, fmt.Errorf("yaml line %d: unexpected indentation", l.num)
+		}
+
+		item := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+		if item == "" {
+			p.pos++
+			p.skipBlank()
+			if p.eof() || p.cur().indent <= indent {
+				seq = append(seq, nil)
+				continue
+			}
+			v, err := p.parseNode(p.cur().indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			continue
+		}
+
+		if _, _, err := splitYAMLKey(item); err == nil && !strings.HasPrefix(item, "[") && !isQuoted(item) {
+			// "- key: value"：将当前行改写为位于键所在列的映射行，再按映射解析
+			offset := len(l.text) - len(item)
+			l.indent += offset
+			l.text = item
+			v, err := p.parseMap(l.indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			continue
+		}
+
+		v, err := parseYAMLScalar(item)
+		if err != nil {
+			return nil, fmt.Errorf("yaml line %d: %w", l.num, err)
+		}
+		seq = append(seq, v)
+		p.pos++
+	}
+}
+
+// parseBlockLiteral 读取缩进大于 parentIndent 的原始行作为块字面量。
+func (p *yamlParser) parseBlockLiteral(parentIndent int, strip bool) string {
+	var (
+		lines       []string
+		blockIndent = -1
+	)
+	for !p.eof() {
+		raw := p.cur().raw
+		trimmed := strings.TrimLeft(raw, " ")
+		if trimmed == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		ind := len(raw) - len(trimmed)
+		if ind <= parentIndent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = ind
+		}
+		if ind < blockIndent {
+			break
+		}
+		lines = append(lines, raw[blockIndent:])
+		p.pos++
+	}
+	// 结尾的空行不属于块内容
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	s := strings.Join(lines, "\n")
+	if !strip && s != "" {
+		s += "\n"
+	}
+	return s
+}
+
+// splitYAMLKey 将 "key: value" 拆分为键与值文本。
+func splitYAMLKey(text string) (string, string, error) {
+	var key, rest string
+	if isQuoted(text) {
+		q := text[0]
+		end := strings.IndexByte(text[1:], q)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		key = text[1 : end+1]
+		after := text[end+2:]
+		if !strings.HasPrefix(after, ":") {
+			return "", "", fmt.Errorf("expected ':' after key")
+		}
+		rest = after[1:]
+	} else {
+		idx := strings.Index(text, ": ")
+		if idx < 0 {
+			if !strings.HasSuffix(text, ":") {
+				return "", "", fmt.Errorf("expected 'key: value'")
+			}
+			idx = len(text) - 1
+		}
+		key = strings.TrimSpace(text[:idx])
+		rest = text[idx+1:]
+	}
+	if key == "" {
+		return "", "", fmt.Errorf("empty key")
+	}
+	return key, strings.TrimSpace(rest), nil
+}
+
+func isQuoted(s string) bool {
+	return strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'")
+}
+
+// parseYAMLScalar 解析标量或行内序列。
+func parseYAMLScalar(s string) (any, error) {
+	switch {
+	case s == "~" || s == "null":
+		return nil, nil
+	case strings.HasPrefix(s, `"`):
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid double-quoted string %s", s)
+		}
+		return v, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, fmt.Errorf("invalid single-quoted string %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("unterminated flow sequence %s", s)
+		}
+		inner := strings.TrimSpace(s[1 : len(s)-1])
+		seq := []any{}
+		if inner == "" {
+			return seq, nil
+		}
+		for _, item := range splitFlowItems(inner) {
+			v, err := parseYAMLScalar(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+		}
+		return seq, nil
+	default:
+		return s, nil
+	}
+}
+
+// splitFlowItems 按逗号拆分行内序列，忽略引号内的逗号。
+func splitFlowItems(s string) []string {
+	var (
+		items []string
+		quote byte
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// stripYAMLComment 去掉引号之外、位于行首或空白之后的 # 注释。
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			// 仅当引号位于值开头时才视为字符串定界符
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '[' || line[i-1] == ',' {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || line[i-1] == ' ' {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
@@ -10,6 +10,20 @@ const (
 	SeverityCritical Severity = "critical"
 )
 
+// Rank 返回严重级别的排序权重，级别越高值越大；未知级别与 info 相同。
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityCritical:
+		return 3
+	case SeverityError:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
 // Finding 表示一次诊断中的单条发现。
 type Finding struct {
 	// 插件内部的发现 ID，便于排错与归档。
@@ -22,6 +36,8 @@ type Finding struct {
 	Severity Severity
 	// 可选：影响范围或影响描述。
 	Impact string
+	// 可选：修复时需要修改的配置文件，如 /etc/sysctl.conf，供 SARIF 等输出定位。
+	ConfigFile string `json:",omitempty"`
 }
 
 // Suggestion 表示针对某个 Finding 给出的修复建议。
@@ -32,6 +48,36 @@ type Suggestion struct {
 	Title string
 	// 具体操作建议或说明。
 	Details string
+	// 可选：可由 ossre fix 自动执行的修复动作，按顺序执行。
+	Actions []Action `json:",omitempty"`
+}
+
+// ActionType 为修复动作的类型。
+type ActionType string
+
+const (
+	// ActionSysctl 立即将内核参数 Key 设置为 Value（写入 /proc/sys），重启后失效。
+	ActionSysctl ActionType = "sysctl"
+	// ActionSysctlDropIn 在 sysctl 配置文件 Path 中设置 "Key = Value"，已有该参数时原地替换，否则追加。
+	ActionSysctlDropIn ActionType = "sysctl_dropin"
+	// ActionLimitsDropIn 在 pam_limits 配置文件 Path 中为 * 与 root 设置资源 Key（如 nofile）的软硬限制为 Value。
+	ActionLimitsDropIn ActionType = "limits_dropin"
+	// ActionCgroupPidsMax 将 cgroup 的 pids.max 文件 Path 设置为 Value。
+	ActionCgroupPidsMax ActionType = "cgroup_pids_max"
+)
+
+// ossre fix 默认写入的配置文件，文件名以 99- 开头，在同目录的其他文件之后加载。
+const (
+	SysctlDropInFile = "/etc/sysctl.d/99-ossre.conf"
+	LimitsDropInFile = "/etc/security/limits.d/99-ossre.conf"
+)
+
+// Action 表示一个机器可执行的修复动作，路径均为宿主视角的绝对路径。
+type Action struct {
+	Type  ActionType
+	Key   string `json:",omitempty"`
+	Value string
+	Path  string `json:",omitempty"`
 }
 
 // Result 表示某个插件一次执行的整体结果。
@@ -42,4 +88,47 @@ type Result struct {
 	Findings []Finding
 	// 建议列表。
 	Suggestions []Suggestion
+	// 可选：诊断过程中得到的数值证据，供 Prometheus 等监控输出使用。
+	Metrics []Metric `json:",omitempty"`
+	// 可选：被豁免文件豁免的发现，不计入 Findings，单独展示以便复核。
+	Suppressed []SuppressedFinding `json:",omitempty"`
+	// 可选：因权限不足或数据不可读而未评估的检查，存在时结果中没有发现并不代表该检查通过。
+	Skipped []SkippedCheck `json:",omitempty"`
+}
+
+// SkippedCheck 表示一个未评估的检查（案例或案例中的一个维度）。
+type SkippedCheck struct {
+	// 未评估的案例 ID，如 maxfd.fd.headroom 或 maxproc.thread.headroom.nproc。
+	Case string
+	// 未评估的原因，如“权限不足，无法读取 /proc/42/fd（需要 root 或 CAP_SYS_PTRACE）”。
+	Reason string
+	// 可选：无法读取的路径。
+	Path string `json:",omitempty"`
+	// Permission 表示原因是权限不足（EACCES/EPERM），而不是文件不存在等环境问题。
+	Permission bool `json:",omitempty"`
+}
+
+// SuppressedFinding 表示一条被豁免的发现及豁免依据。
+type SuppressedFinding struct {
+	Finding Finding
+	// 发现对应的建议，随发现一同移出 Suggestions。
+	Suggestions []Suggestion `json:",omitempty"`
+	// 匹配的豁免条目：案例 ID 模式、原因、负责人与到期日（YYYY-MM-DD，为空表示长期有效）。
+	Case    string
+	Reason  string
+	Owner   string
+	Expires string `json:",omitempty"`
+}
+
+// Metric 表示一个数值证据，如某个维度的线程创建余量、某个内核参数是否符合基线（0/1）。
+// 同名指标的 Labels 键应保持一致，输出时以 "ossre_" 为前缀并追加 plugin 标签。
+type Metric struct {
+	// 指标名称，如 maxproc_thread_headroom，只能包含字母、数字与下划线。
+	Name string
+	// 指标说明。
+	Help string
+	// 可选：区分同名指标的标签，如 {"dimension": "nproc"}。
+	Labels map[string]string `json:",omitempty"`
+	// 指标值。
+	Value float64
 }
@@ -0,0 +1,120 @@
+// Package ossre 是 ossre 的公开 Go API，供其他 Go 程序内嵌诊断（如服务启动时做一次健康检查）
+// 以及编写自定义插件。
+//
+// 本包只暴露稳定的类型与构造方式，内部的调度、采集缓存与各插件实现均不对外；
+// 其兼容性由 APIVersion 单独标识，与 ossre 命令行工具的版本无关。
+//
+//	r, err := ossre.New(ossre.WithBuiltins("kernel", "maxfd"), ossre.WithPlugins(myPlugin{}))
+//	if err != nil { ... }
+//	rep, err := r.Run(ctx, ossre.Target{PIDs: []int{os.Getpid()}})
+//	if err != nil { ... }
+//	for _, f := range rep.Findings(models.SeverityError) { ... }
+package ossre
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// APIVersion 为本包的 API 版本，本包出现不兼容的变更时递增。
+const APIVersion = 1
+
+// Plugin 为自定义诊断插件需要实现的接口。
+type Plugin interface {
+	// Name 返回插件的唯一名称，不能与内置插件或其他插件重复。
+	Name() string
+	// Description 返回插件的简要说明。
+	Description() string
+	// Run 执行一次诊断，env 保证非 nil。
+	Run(ctx context.Context, env *Env) (models.Result, error)
+}
+
+// Env 为插件一次运行的环境。
+type Env struct {
+	// Target 为本次诊断的目标对象。
+	Target Target
+	// Config 为运行时配置，保证非 nil，插件不应修改。
+	Config *config.Config
+	// Logger 已附带 plugin 属性，保证非 nil。
+	Logger *slog.Logger
+	// FS 为读取 /proc、/sys、/etc 等数据的入口，保证非 nil。插件应始终使用宿主视角的绝对路径，
+	// 由 FS 映射到备用根目录等实际位置；同一次 Run 内的读取结果会被缓存并在插件之间共享。
+	FS FS
+}
+
+// FS 为插件读取文件所用的只读文件系统。
+type FS interface {
+	ReadFile(name string) ([]byte, error)
+	// ReadDirNames 返回目录下的条目名称，不保证顺序。
+	ReadDirNames(name string) ([]string, error)
+	Readlink(name string) (string, error)
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// FromFS 将 io/fs.FS（如 testing/fstest.MapFS）包装为 FS，配合 WithFS 用伪造的文件树测试自定义插件。
+// 宿主视角的绝对路径去掉开头的 "/" 后在 fsys 中查找，模式带 fs.ModeSymlink 的文件视为符号链接。
+func FromFS(fsys fs.FS) FS {
+	return collectors.FromIOFS(fsys)
+}
+
+// Target 描述一次诊断所针对的对象，各字段均为可选，插件按需读取自己关心的部分。
+type Target struct {
+	// PIDs 为目标进程列表，单进程插件只使用第一个。
+	PIDs []int
+	// AllProcesses 表示对主机上所有进程进行扫描。
+	AllProcesses bool
+	// PIDSelector 为全主机扫描的进程筛选表达式，如 "comm:java,user:app"。
+	PIDSelector string
+	// CgroupPath 为目标 cgroup 路径（相对于 cgroup 挂载点）。
+	CgroupPath string
+	// ContainerID 为目标容器 ID（可以是短 ID），未指定 PIDs 时运行前定位容器的 init 进程与 cgroup。
+	ContainerID string
+	// NetNS 为目标网络 namespace，可以是名称或 /proc/<pid>/ns/net 形式的路径。
+	NetNS string
+	// MountRoot 为目标挂载 namespace 的根目录。
+	MountRoot string
+}
+
+func (t Target) core() core.Target {
+	return core.Target{
+		PIDs:         t.PIDs,
+		AllProcesses: t.AllProcesses,
+		PIDSelector:  t.PIDSelector,
+		CgroupPath:   t.CgroupPath,
+		ContainerID:  t.ContainerID,
+		NetNS:        t.NetNS,
+		MountRoot:    t.MountRoot,
+	}
+}
+
+func targetFromCore(t core.Target) Target {
+	return Target{
+		PIDs:         t.PIDs,
+		AllProcesses: t.AllProcesses,
+		PIDSelector:  t.PIDSelector,
+		CgroupPath:   t.CgroupPath,
+		ContainerID:  t.ContainerID,
+		NetNS:        t.NetNS,
+		MountRoot:    t.MountRoot,
+	}
+}
+
+// adapter 将公开的 Plugin 适配为内部的 core.Plugin。
+type adapter struct {
+	Plugin
+}
+
+func (a adapter) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	return a.Plugin.Run(ctx, &Env{
+		Target: targetFromCore(rc.Target),
+		Config: rc.Config,
+		Logger: rc.Logger,
+		FS:     rc.Collector,
+	})
+}
@@ -0,0 +1,271 @@
+package ossre
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/plugins/builtin"
+	"github.com/supperghost/ossre/internal/report"
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// PluginInfo 描述一个可运行的插件。
+type PluginInfo struct {
+	Name        string
+	Description string
+	// Builtin 表示插件随 ossre 发布；通过 WithPlugins 注册的插件与外部插件为 false。
+	Builtin bool
+	// Version 与 Tags 为插件的版本与分类标签（如 network、capacity），可能为空。
+	Version string
+	Tags    []string
+}
+
+// Builtins 返回全部内置插件，按名称排序。
+func Builtins() []PluginInfo {
+	var infos []PluginInfo
+	for _, p := range builtin.Plugins() {
+		meta, _ := core.LookupMetadata(p.Name())
+		infos = append(infos, PluginInfo{Name: p.Name(), Description: p.Description(), Builtin: true, Version: meta.Version, Tags: meta.Tags})
+	}
+	return infos
+}
+
+// Formats 返回 Report.Write 支持的输出格式名称。
+func Formats() []string {
+	return report.Formats()
+}
+
+// Option 用于定制 New 创建的 Runner。
+type Option func(*options)
+
+type options struct {
+	cfg        *config.Config
+	cfgFile    string
+	root       *string
+	logger     *slog.Logger
+	fs         FS
+	builtins   []string
+	noBuiltins bool
+	plugins    []Plugin
+	external   *string
+}
+
+// WithConfig 指定运行时配置，未指定时使用 config.NewDefault()。
+func WithConfig(cfg *config.Config) Option {
+	return func(o *options) { o.cfg = cfg }
+}
+
+// WithConfigFile 从 YAML 配置文件加载运行时配置，优先于 WithConfig。
+func WithConfigFile(path string) Option {
+	return func(o *options) { o.cfgFile = path }
+}
+
+// WithRoot 将 /proc、/sys、/etc 重定向到 root 下，用于诊断挂载进来的宿主机根目录。
+func WithRoot(root string) Option {
+	return func(o *options) { o.root = &root }
+}
+
+// WithLogger 指定 Runner 与插件使用的日志接口，未指定时丢弃所有日志。
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// WithFS 指定插件读取文件所用的文件系统，常用于以伪造的文件树测试自定义插件。
+// 指定后 WithRoot 不再生效，也不会加载外部插件。
+func WithFS(fsys FS) Option {
+	return func(o *options) { o.fs = fsys }
+}
+
+// WithBuiltins 只注册给定名称的内置插件，不传名称时不注册任何内置插件；未使用本选项时注册全部内置插件。
+func WithBuiltins(names ...string) Option {
+	return func(o *options) {
+		o.builtins = names
+		o.noBuiltins = len(names) == 0
+	}
+}
+
+// WithPlugins 注册自定义插件，可多次使用。
+func WithPlugins(plugins ...Plugin) Option {
+	return func(o *options) { o.plugins = append(o.plugins, plugins...) }
+}
+
+// WithExternalPlugins 从 dir 加载外部可执行插件，dir 为空时使用配置中的 plugins.dir。
+// 协议见 docs/CASE_AND_SCENARIO.md 的“外部插件”一节。
+func WithExternalPlugins(dir string) Option {
+	return func(o *options) { o.external = &dir }
+}
+
+// Runner 按名称运行已注册的插件。
+type Runner struct {
+	runner *core.Runner
+	fs     collectors.FS
+	custom map[string]bool
+}
+
+// New 按选项创建 Runner。配置文件无法加载、内置插件名称未知或插件名称重复时返回错误。
+func New(opts ...Option) (*Runner, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	cfg := config.NewDefault()
+	if o.cfg != nil {
+		c := *o.cfg
+		cfg = &c
+	}
+	if o.cfgFile != "" {
+		c, err := config.LoadFromFile(o.cfgFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg = c
+	}
+	if o.root != nil {
+		cfg.Paths.SetRoot(*o.root)
+	}
+	if o.external != nil && *o.external != "" {
+		cfg.Plugins.Dir = *o.external
+	}
+
+	plugins, err := o.selectBuiltins()
+	if err != nil {
+		return nil, err
+	}
+	// 自定义插件不能与任何内置插件同名，即使该内置插件未被选中，以免与内置插件的元数据混淆
+	seen := make(map[string]bool)
+	for _, p := range builtin.Plugins() {
+		seen[p.Name()] = true
+	}
+	custom := make(map[string]bool)
+	for _, p := range o.plugins {
+		if p == nil || p.Name() == "" {
+			return nil, fmt.Errorf("plugin has no name")
+		}
+		if seen[p.Name()] {
+			return nil, fmt.Errorf("duplicate plugin name %q", p.Name())
+		}
+		seen[p.Name()] = true
+		custom[p.Name()] = true
+		plugins = append(plugins, adapter{p})
+	}
+
+	var fsys collectors.FS = collectors.NewHostFS(cfg.Paths)
+	coreOpts := []core.Option{core.WithConfig(cfg), core.WithLogger(o.logger)}
+	if o.fs != nil {
+		fsys = o.fs
+		coreOpts = append(coreOpts, core.WithFS(fsys))
+	}
+	if o.external != nil {
+		coreOpts = append(coreOpts, core.WithExecPlugins())
+	}
+	return &Runner{
+		runner: core.NewRunner(plugins, coreOpts...),
+		fs:     fsys,
+		custom: custom,
+	}, nil
+}
+
+func (o *options) selectBuiltins() ([]core.Plugin, error) {
+	all := builtin.Plugins()
+	if o.builtins == nil && !o.noBuiltins {
+		return all, nil
+	}
+	byName := make(map[string]core.Plugin, len(all))
+	for _, p := range all {
+		byName[p.Name()] = p
+	}
+	var selected []core.Plugin
+	for _, name := range o.builtins {
+		p, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown builtin plugin: %s", name)
+		}
+		selected = append(selected, p)
+	}
+	return selected, nil
+}
+
+// Plugins 返回已注册的插件，按名称排序。
+func (r *Runner) Plugins() []PluginInfo {
+	var infos []PluginInfo
+	for _, p := range r.runner.ListPlugins() {
+		_, external := p.(*core.ExecPlugin)
+		meta := r.runner.Metadata(p.Name())
+		infos = append(infos, PluginInfo{
+			Name:        p.Name(),
+			Description: p.Description(),
+			Builtin:     !external && !r.custom[p.Name()],
+			Version:     meta.Version,
+			Tags:        meta.Tags,
+		})
+	}
+	return infos
+}
+
+// PluginErrors 返回加载外部插件时遇到的错误，未使用 WithExternalPlugins 时为空。
+func (r *Runner) PluginErrors() []error {
+	return r.runner.PluginErrors()
+}
+
+// Run 针对 target 按顺序运行给定名称的插件，未给出名称时按名称顺序运行全部已注册插件。
+// 插件名称未知时返回错误；单个插件运行失败时返回已完成插件的报告与该错误。
+func (r *Runner) Run(ctx context.Context, target Target, names ...string) (*Report, error) {
+	if len(names) == 0 {
+		for _, p := range r.Plugins() {
+			names = append(names, p.Name)
+		}
+	}
+	rep := &Report{GeneratedAt: time.Now()}
+	if h, err := collectors.NewCollector(r.fs).Sysctl("kernel.hostname"); err == nil {
+		rep.Hostname = h
+	}
+	runResults, err := r.runner.RunAll(ctx, names, target.core())
+	for _, rr := range runResults {
+		rep.Results = append(rep.Results, rr.Result)
+	}
+	if err != nil && runResults == nil {
+		return nil, err
+	}
+	return rep, err
+}
+
+// Report 为一次 Run 的结果。
+type Report struct {
+	Results     []models.Result
+	Hostname    string
+	GeneratedAt time.Time
+}
+
+// Findings 返回严重级别不低于 min 的全部发现，按插件顺序排列。
+func (rep *Report) Findings(min models.Severity) []models.Finding {
+	var findings []models.Finding
+	for _, res := range rep.Results {
+		for _, f := range res.Findings {
+			if f.Severity.Rank() >= min.Rank() {
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+// Skipped 返回因权限不足或数据不可读而未评估的检查，非空时没有发现并不代表这些检查通过。
+func (rep *Report) Skipped() []models.SkippedCheck {
+	var skipped []models.SkippedCheck
+	for _, res := range rep.Results {
+		skipped = append(skipped, res.Skipped...)
+	}
+	return skipped
+}
+
+// Write 以 format 格式输出报告，格式与命令行的 --format 相同，见 Formats。
+func (rep *Report) Write(w io.Writer, format string) error {
+	return report.Write(w, format, report.Meta{Hostname: rep.Hostname, GeneratedAt: rep.GeneratedAt}, rep.Results)
+}
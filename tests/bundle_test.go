@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"testing"
+
+	"github.com/supperghost/ossre/internal/bundle"
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/plugins/maxproc"
+)
+
+func TestBundleReplaysFindings(t *testing.T) {
+	rec := bundle.NewRecorder(writeTree(t, map[string]string{
+		"proc/42/limits":                   "Max processes 100 200 processes\nMax stack size 8388608 unlimited bytes\n",
+		"proc/42/status":                   "Name:\tjava\nThreads:\t90\nVmSize:\t1048576 kB\n",
+		"proc/42/cgroup":                   "0::/app.slice\n",
+		"sys/fs/cgroup/app.slice/pids.max": "max\n",
+		"proc/sys/kernel/threads-max":      "1000\n",
+		"proc/sys/kernel/pid_max":          "32768\n",
+		"proc/loadavg":                     "0.00 0.00 0.00 1/120 42\n",
+	}))
+	target := core.Target{PIDs: []int{42}}
+
+	online, err := core.NewRunner([]core.Plugin{maxproc.New()}, core.WithFS(rec)).Run(context.Background(), maxproc.PluginName, target)
+	if err != nil {
+		t.Fatalf("online run: %v", err)
+	}
+	if len(online.Findings) == 0 {
+		t.Fatal("online run produced no findings")
+	}
+
+	_, missingErr := rec.ReadFile("/proc/42/environ")
+	if missingErr == nil {
+		t.Fatal("expected error reading missing file")
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.Write(&buf, rec, bundle.Meta{Target: target, Plugins: []string{maxproc.PluginName}}, nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	b, err := bundle.Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got := b.Meta(); got.FormatVersion != bundle.FormatVersion || !reflect.DeepEqual(got.Target, target) {
+		t.Errorf("meta = %+v", got)
+	}
+
+	offline, err := core.NewRunner([]core.Plugin{maxproc.New()}, core.WithFS(b)).Run(context.Background(), maxproc.PluginName, target)
+	if err != nil {
+		t.Fatalf("offline run: %v", err)
+	}
+	if !reflect.DeepEqual(online, offline) {
+		t.Errorf("offline result differs from online:\nonline:  %+v\noffline: %+v", online, offline)
+	}
+
+	// 采集时失败的读取在离线时保留错误类型，未记录的路径一律视为不存在
+	if _, err := b.ReadFile("/proc/42/environ"); !errors.Is(err, fs.ErrNotExist) || err.Error() != missingErr.Error() {
+		t.Errorf("recorded err = %v, want %v", err, missingErr)
+	}
+	if _, err := b.ReadFile("/proc/42/fd/0"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("unrecorded path err = %v, want ErrNotExist", err)
+	}
+}
+
+func TestBundleRedactsEnviron(t *testing.T) {
+	rec := bundle.NewRecorder(writeTree(t, map[string]string{
+		"proc/42/environ": "PATH=/usr/bin\x00DB_PASSWORD=hunter2\x00GOMAXPROCS=8\x00" +
+			"JAVA_TOOL_OPTIONS=-XX:ActiveProcessorCount=4\x00AWS_SECRET_ACCESS_KEY=abc\x00",
+		"proc/42/task/43/environ": "API_TOKEN=xyz\x00_JAVA_OPTIONS=-Xmx1g\x00",
+	}))
+	c := collectors.NewCollector(rec)
+	env, err := c.Environ(42)
+	if err != nil {
+		t.Fatalf("Environ: %v", err)
+	}
+	want := map[string]string{"GOMAXPROCS": "8", "JAVA_TOOL_OPTIONS": "-XX:ActiveProcessorCount=4"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("Environ = %v, want %v", env, want)
+	}
+	if _, err := rec.ReadFile("/proc/42/task/43/environ"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.Write(&buf, rec, bundle.Meta{}, nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	raw := buf.Bytes()
+	b, err := bundle.Read(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	for name, want := range map[string]string{
+		"/proc/42/environ":         "GOMAXPROCS=8\x00JAVA_TOOL_OPTIONS=-XX:ActiveProcessorCount=4\x00",
+		"/proc/42/task/43/environ": "_JAVA_OPTIONS=-Xmx1g\x00",
+	} {
+		got, err := b.ReadFile(name)
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	// 解压后的归档中不应出现其他变量
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"DB_PASSWORD", "hunter2", "AWS_SECRET_ACCESS_KEY", "API_TOKEN", "PATH=/usr/bin"} {
+		if bytes.Contains(plain, []byte(secret)) {
+			t.Errorf("bundle contains %q", secret)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/internal/plugintest"
+	"github.com/supperghost/ossre/pkg/config"
+)
+
+// countingFS 统计每个路径被底层读取的次数。
+type countingFS struct {
+	collectors.FS
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+func (f *countingFS) ReadFile(name string) ([]byte, error) {
+	f.mu.Lock()
+	f.reads[name]++
+	f.mu.Unlock()
+	return f.FS.ReadFile(name)
+}
+
+// writeTree 在临时目录下按宿主视角路径写入文件，并返回指向该目录的 FS。
+func writeTree(t *testing.T, files map[string]string) collectors.FS {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var paths config.PathsConfig
+	paths.SetRoot(root)
+	return collectors.NewHostFS(paths)
+}
+
+func TestCollectorCachesReads(t *testing.T) {
+	fsys := &countingFS{
+		FS: writeTree(t, map[string]string{
+			"proc/42/limits": "Max processes 100 200 processes\nMax open files unlimited unlimited files\n",
+		}),
+		reads: make(map[string]int),
+	}
+	c := collectors.NewCollector(fsys)
+
+	for i := 0; i < 3; i++ {
+		limits, err := c.Limits(42)
+		if err != nil {
+			t.Fatalf("Limits: %v", err)
+		}
+		if got := limits[collectors.LimitNproc]; got != (collectors.Limit{Soft: 100, Hard: 200}) {
+			t.Errorf("nproc = %+v", got)
+		}
+		if got := limits.Soft(collectors.LimitNofile); got != collectors.Unlimited {
+			t.Errorf("nofile soft = %d, want Unlimited", got)
+		}
+	}
+	if n := fsys.reads["/proc/42/limits"]; n != 1 {
+		t.Errorf("limits read %d times, want 1", n)
+	}
+
+	if _, err := c.Fresh().Limits(42); err != nil {
+		t.Fatalf("Fresh().Limits: %v", err)
+	}
+	if n := fsys.reads["/proc/42/limits"]; n != 2 {
+		t.Errorf("limits read %d times after Fresh, want 2", n)
+	}
+}
+
+func TestCollectorProcFiles(t *testing.T) {
+	c := collectors.NewCollector(writeTree(t, map[string]string{
+		"proc/7/stat":   "7 (my (odd) proc) S 1 7 7 0 -1 4194560 100 0 0 0 12 34 0 0 20 0 5 0 999 1000 10",
+		"proc/7/status": "Name:\tjava\nUid:\t1000\t1000\t1000\t1000\nThreads:\t5\nVmSize:\t  2048 kB\n",
+		"proc/7/cgroup": "0::/system.slice/app.service\n",
+		"sys/fs/cgroup/system.slice/app.service/pids.max":     "max\n",
+		"sys/fs/cgroup/system.slice/app.service/pids.current": "5\n",
+		"proc/loadavg": "0.10 0.20 0.30 2/345 678\n",
+	}))
+
+	st, err := c.ProcStat(7)
+	if err != nil {
+		t.Fatalf("ProcStat: %v", err)
+	}
+	if st.Comm != "my (odd) proc" || st.PPID != 1 || st.UTime != 12 || st.STime != 34 || st.NumThreads != 5 || st.StartTime != 999 {
+		t.Errorf("ProcStat = %+v", st)
+	}
+
+	status, err := c.Status(7)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Name != "java" || status.UID != 1000 || status.VmSize != 2048 {
+		t.Errorf("Status = %+v", status)
+	}
+
+	pids := c.CgroupPids(7)
+	if pids.Version != "v2" || pids.Max != collectors.Unlimited || pids.Current != 5 {
+		t.Errorf("CgroupPids = %+v", pids)
+	}
+
+	l, err := c.Loadavg()
+	if err != nil {
+		t.Fatalf("Loadavg: %v", err)
+	}
+	if l.Running != 2 || l.Total != 345 {
+		t.Errorf("Loadavg = %+v", l)
+	}
+}
+
+func TestSamplesRates(t *testing.T) {
+	var s collectors.Samples
+	t0 := time.Unix(1000, 0)
+	// eth0 为 32 位计数器并在第二个区间回绕；eth1 在第二个采样点被拔出后重新插入；sda 计数器在第三个区间被重置
+	s.Add(t0, collectors.Counters{"eth0/rx_bytes": math.MaxUint32 - 99, "eth1/rx_bytes": 10, "sda/reads": 1 << 40, "sda/read_ms": 0, "sda/in_flight": 2})
+	s.Add(t0.Add(time.Second), collectors.Counters{"eth0/rx_bytes": math.MaxUint32 - 49, "sda/reads": 1<<40 + 10, "sda/read_ms": 50, "sda/in_flight": 4})
+	s.Add(t0.Add(2*time.Second), collectors.Counters{"eth0/rx_bytes": 50, "eth1/rx_bytes": 0, "sda/reads": 1<<40 + 30, "sda/read_ms": 250, "sda/in_flight": 6})
+	s.Add(t0.Add(4*time.Second), collectors.Counters{"eth0/rx_bytes": 250, "eth1/rx_bytes": 40, "sda/reads": 5, "sda/read_ms": 260, "sda/in_flight": 8})
+
+	got := s.Rate("eth0/rx_bytes")
+	want := collectors.Stats{Count: 3, Min: 50, Max: 100, Mean: 250.0 / 3, P50: 100, P95: 100, P99: 100}
+	if got != want {
+		t.Errorf("Rate(eth0) = %+v, want %+v", got, want)
+	}
+	if d, ok := s.Delta("eth0/rx_bytes"); !ok || d != 350 {
+		t.Errorf("Delta(eth0) = %d, %v, want 350", d, ok)
+	}
+	if got := s.Rate("eth1/rx_bytes"); got.Count != 1 || got.Max != 20 {
+		t.Errorf("Rate(eth1) = %+v, want one interval at 20/s", got)
+	}
+	if got := s.Ratio([]string{"sda/read_ms"}, []string{"sda/reads"}); got.Count != 2 || got.Min != 5 || got.Max != 10 {
+		t.Errorf("Ratio(await) = %+v, want intervals 5 and 10", got)
+	}
+	if got := s.Gauge("sda/in_flight"); got.Count != 4 || got.Mean != 5 || got.P50 != 5 {
+		t.Errorf("Gauge(in_flight) = %+v", got)
+	}
+	if s.Elapsed() != 4*time.Second {
+		t.Errorf("Elapsed = %s", s.Elapsed())
+	}
+	if first, last, ok := s.Endpoints("sda/in_flight"); !ok || first != 2 || last != 8 {
+		t.Errorf("Endpoints(in_flight) = %d, %d, %v", first, last, ok)
+	}
+
+	// 瞬时值按线性拟合求变化速率，取值不变时斜率严格为 0
+	var g collectors.Samples
+	g.Add(t0, collectors.Counters{"threads": 10, "headroom": 1000})
+	g.Add(t0.Add(time.Second), collectors.Counters{"threads": 12, "headroom": 1000})
+	g.Add(t0.Add(3*time.Second), collectors.Counters{"threads": 16, "headroom": 1000})
+	if got, ok := g.Slope("threads"); !ok || got != 2 {
+		t.Errorf("Slope(threads) = %v, %v, want 2", got, ok)
+	}
+	if got, ok := g.Slope("headroom"); !ok || got != 0 {
+		t.Errorf("Slope(headroom) = %v, %v, want 0", got, ok)
+	}
+	if _, ok := g.Slope("missing"); ok {
+		t.Error("Slope(missing) should not be ok")
+	}
+
+	// 接近 32 位上限时的减小视为回绕，较小的值减小视为 64 位计数器被重置
+	for _, tc := range []struct {
+		prev, cur, delta uint64
+		ok               bool
+	}{
+		{math.MaxUint32 - 9, 5, 15, true},
+		{1000, 5, 0, false},
+		{3_000_000_000, 10, 0, false},
+		{1 << 40, 5, 0, false},
+	} {
+		if d, ok := collectors.CounterDelta(tc.prev, tc.cur); d != tc.delta || ok != tc.ok {
+			t.Errorf("CounterDelta(%d, %d) = %d, %v, want %d, %v", tc.prev, tc.cur, d, ok, tc.delta, tc.ok)
+		}
+	}
+}
+
+// sequenceFS 每次读取 name 时依次返回 contents 中的下一项，用完后重复最后一项。
+type sequenceFS struct {
+	collectors.FS
+	name     string
+	mu       sync.Mutex
+	contents []string
+}
+
+func (f *sequenceFS) ReadFile(name string) ([]byte, error) {
+	if name != f.name {
+		return f.FS.ReadFile(name)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data := f.contents[0]
+	if len(f.contents) > 1 {
+		f.contents = f.contents[1:]
+	}
+	return []byte(data), nil
+}
+
+func TestCollectorSample(t *testing.T) {
+	softnet := func(processed, dropped uint64) string {
+		return fmt.Sprintf("%08x %08x 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000\n", processed, dropped)
+	}
+	fsys := &sequenceFS{
+		FS:       writeTree(t, nil),
+		name:     "/proc/net/softnet_stat",
+		contents: []string{softnet(100, 0), softnet(300, 3)},
+	}
+	s, err := collectors.NewCollector(fsys).Sample(context.Background(), 10*time.Millisecond, 25*time.Millisecond, collectors.SoftnetCounters)
+	if err != nil {
+		t.Fatalf("Sample: %v", err)
+	}
+	// 首次采样与窗口结束时各采样一次，中间的采样次数取决于调度
+	if s.Len() < 2 {
+		t.Fatalf("Len = %d, want at least 2", s.Len())
+	}
+	if d, ok := s.Delta("cpu0/processed"); !ok || d != 200 {
+		t.Errorf("Delta(processed) = %d, %v, want 200", d, ok)
+	}
+	if d, _ := s.Delta("cpu0/dropped"); d != 3 {
+		t.Errorf("Delta(dropped) = %d, want 3", d)
+	}
+
+	if _, err := collectors.NewCollector(writeTree(t, nil)).Sample(context.Background(), time.Second, 0, collectors.DiskstatsCounters); err == nil {
+		t.Error("expected error when no source is readable")
+	}
+}
+
+func TestCollectorPreflight(t *testing.T) {
+	f, err := plugintest.LoadFixture(filepath.Join("testdata", "fixtures", "permission-denied"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf := collectors.NewCollector(f.FS).Preflight(42)
+	if pf.EUID != 1000 || pf.Root() || !pf.CapKnown || pf.HasCap("CAP_SYS_PTRACE") {
+		t.Errorf("unexpected identity: %+v", pf)
+	}
+	if got := fmt.Sprint(pf.Denied); got != "[/proc/42/fd /proc/42/limits]" {
+		t.Errorf("Denied = %s", got)
+	}
+	if pf.UserNS != collectors.NamespaceUnknown {
+		t.Errorf("UserNS = %s, want unknown without ns links", pf.UserNS)
+	}
+	if missing := pf.Missing([]string{"CAP_SYS_PTRACE", "root"}); len(missing) != 2 {
+		t.Errorf("Missing = %v", missing)
+	}
+
+	// 有效能力集中的位与内核的初始 namespace inode 编号
+	fsys := plugintest.FromFS(fstest.MapFS{
+		"proc/self":       {Data: []byte("7"), Mode: fs.ModeSymlink},
+		"proc/7/status":   {Data: []byte("Uid:\t1000\t0\t0\t0\nCapEff:\t0000000000080000\n")},
+		"proc/7/ns/user":  {Data: []byte("user:[4026531837]"), Mode: fs.ModeSymlink},
+		"proc/7/ns/pid":   {Data: []byte("pid:[4026532412]"), Mode: fs.ModeSymlink},
+		"proc/42/limits":  {Data: []byte("Limit Soft Units\n")},
+		"proc/42/fd/0":    {Data: []byte("/dev/null"), Mode: fs.ModeSymlink},
+		"proc/42/task/42": {Mode: fs.ModeDir},
+		"proc/42/status":  {Data: []byte("Uid:\t0\t0\t0\t0\n")},
+	})
+	pf = collectors.NewCollector(fsys).Preflight(42)
+	if !pf.Root() || !pf.HasCap("CAP_SYS_PTRACE") || pf.HasCap("CAP_SYS_ADMIN") || len(pf.Denied) != 0 {
+		t.Errorf("unexpected privileges: %+v", pf)
+	}
+	if pf.UserNS != collectors.NamespaceInitial || pf.PIDNS != collectors.NamespaceNested {
+		t.Errorf("namespaces = %s/%s, want initial/nested", pf.UserNS, pf.PIDNS)
+	}
+}
+
+func TestFindContainer(t *testing.T) {
+	const id = "3f4e1a2b9c0d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f"
+	for _, tc := range []struct {
+		path, runtime string
+	}{
+		{"/docker/" + id, "docker"},
+		{"/system.slice/docker-" + id + ".scope", "docker"},
+		{"/kubepods/burstable/pod5d1e2c3b-4a5f-6e7d-8c9b-0a1f2e3d4c5b/" + id, "kubernetes"},
+		{"/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + id + ".scope", "containerd"},
+		{"/kubepods.slice/kubepods-pod1.slice/crio-" + id + ".scope", "cri-o"},
+		{"/machine.slice/libpod-" + id + ".scope/container", "podman"},
+		{"/default/" + id, "containerd"},
+	} {
+		got, runtime, ok := collectors.ParseContainerCgroup(tc.path)
+		if !ok || got != id || runtime != tc.runtime {
+			t.Errorf("ParseContainerCgroup(%q) = %q, %q, %v; want runtime %q", tc.path, got, runtime, ok, tc.runtime)
+		}
+	}
+	for _, p := range []string{"/system.slice/app.service", "/kubepods.slice/crio-conmon-" + id + ".scope", "/"} {
+		if _, _, ok := collectors.ParseContainerCgroup(p); ok {
+			t.Errorf("ParseContainerCgroup(%q) matched", p)
+		}
+	}
+
+	f, err := plugintest.LoadFixture(filepath.Join("testdata", "fixtures", "container-k8s"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := collectors.NewCollector(f.FS)
+	// exec 进入容器的 PID 250 启动较晚，init 进程为 PID 200
+	ct, err := c.FindContainer("containerd://3F4E1A2B9C0D")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct.ID != id || ct.PID != 200 || fmt.Sprint(ct.PIDs) != "[200 250]" || ct.Runtime != "containerd" {
+		t.Errorf("FindContainer = %+v", ct)
+	}
+	// 没有匹配的容器与非十六进制的 ID 返回错误
+	if _, err := c.FindContainer("ab"); err == nil {
+		t.Error("FindContainer(ab) succeeded, want not found")
+	}
+	if _, err := c.FindContainer("not-hex"); err == nil {
+		t.Error("FindContainer(not-hex) succeeded, want invalid id")
+	}
+}
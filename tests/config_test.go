@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/supperghost/ossre/internal/collectors"
+	"github.com/supperghost/ossre/pkg/config"
+)
+
+func TestParseYAML(t *testing.T) {
+	data := []byte(`# comment
+paths:
+  root: /host   # trailing comment
+sampling:
+  window: "60s"
+rules:
+  - id: demo
+    tags: [a, 'b c']
+  - plain
+script: |
+  line1
+  line2
+`)
+	got, err := config.ParseYAML(data)
+	if err != nil {
+		t.Fatalf("ParseYAML: %v", err)
+	}
+	want := map[string]any{
+		"paths":    map[string]any{"root": "/host"},
+		"sampling": map[string]any{"window": "60s"},
+		"rules": []any{
+			map[string]any{"id": "demo", "tags": []any{"a", "b c"}},
+			"plain",
+		},
+		"script": "line1\nline2\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseYAML = %#v, want %#v", got, want)
+	}
+
+	if _, err := config.ParseYAML([]byte("a:\n\tb: 1\n")); err == nil {
+		t.Fatal("expected error for tab indentation")
+	}
+}
+
+func TestLoadFromFilePaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ossre.yaml")
+	content := "paths:\n  root: /host/\n  etc_root: /snap/etc\nsampling:\n  interval: 10s\nscan:\n  top: 3\nrules:\n  dirs: [/etc/ossre/rules.d, /opt/site/rules]\nsuppress:\n  role: frontend\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile: %v", err)
+	}
+	want := config.PathsConfig{ProcRoot: "/host/proc", SysRoot: "/host/sys", EtcRoot: "/snap/etc"}
+	if cfg.Paths != want {
+		t.Errorf("Paths = %+v, want %+v", cfg.Paths, want)
+	}
+	if cfg.Sampling.Interval != 10*time.Second || cfg.Sampling.ForecastThreshold != time.Hour {
+		t.Errorf("Sampling = %+v", cfg.Sampling)
+	}
+	if cfg.Scan.Top != 3 {
+		t.Errorf("Scan.Top = %d, want 3", cfg.Scan.Top)
+	}
+	if want := []string{"/etc/ossre/rules.d", "/opt/site/rules"}; !reflect.DeepEqual(cfg.Rules.Dirs, want) {
+		t.Errorf("Rules.Dirs = %q, want %q", cfg.Rules.Dirs, want)
+	}
+	if want := (config.SuppressConfig{File: config.DefaultSuppressFile, Role: "frontend"}); cfg.Suppress != want {
+		t.Errorf("Suppress = %+v, want %+v", cfg.Suppress, want)
+	}
+}
+
+func TestHostFSResolve(t *testing.T) {
+	var paths config.PathsConfig
+	paths.SetRoot("/host")
+	fsys := collectors.NewHostFS(paths)
+
+	cases := map[string]string{
+		"/proc/1/limits":          "/host/proc/1/limits",
+		"/proc":                   "/host/proc",
+		"/sys/fs/cgroup/pids.max": "/host/sys/fs/cgroup/pids.max",
+		"/etc/passwd":             "/host/etc/passwd",
+		"/processes":              "/processes",
+		"/var/run/docker.sock":    "/var/run/docker.sock",
+	}
+	for in, want := range cases {
+		if got := fsys.Resolve(in); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// recordingPlugin 记录 Runner 传入的运行上下文，便于断言。
+type recordingPlugin struct {
+	rc *core.RunContext
+}
+
+func (p *recordingPlugin) Name() string        { return "recording" }
+func (p *recordingPlugin) Description() string { return "records run context" }
+
+func (p *recordingPlugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	p.rc = rc
+	return models.Result{Plugin: p.Name()}, nil
+}
+
+func TestRunnerPassesRunContext(t *testing.T) {
+	p := &recordingPlugin{}
+	cfg := config.NewDefault()
+	cfg.Sampling.Window = time.Minute
+
+	r := core.NewRunner([]core.Plugin{p}, core.WithConfig(cfg))
+	target := core.Target{PIDs: []int{42}, CgroupPath: "/system.slice/app.service"}
+	if _, err := r.Run(context.Background(), "recording", target); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if p.rc == nil {
+		t.Fatal("plugin did not receive a run context")
+	}
+	if got := p.rc.Target.PrimaryPID(); got != 42 {
+		t.Errorf("PrimaryPID = %d, want 42", got)
+	}
+	if p.rc.Target.CgroupPath != target.CgroupPath {
+		t.Errorf("CgroupPath = %q, want %q", p.rc.Target.CgroupPath, target.CgroupPath)
+	}
+	if p.rc.Config.Sampling.Window != time.Minute {
+		t.Errorf("Sampling.Window = %s, want 1m", p.rc.Config.Sampling.Window)
+	}
+	if p.rc.Logger == nil {
+		t.Error("Logger is nil")
+	}
+}
+
+func TestRunnerDefaultsConfigAndLogger(t *testing.T) {
+	p := &recordingPlugin{}
+	r := core.NewRunner([]core.Plugin{p})
+	if _, err := r.Run(context.Background(), "recording", core.Target{}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if p.rc.Config == nil || p.rc.Logger == nil {
+		t.Fatal("Runner must provide non-nil Config and Logger")
+	}
+	if p.rc.Target.PrimaryPID() != 0 {
+		t.Errorf("PrimaryPID = %d, want 0 for empty target", p.rc.Target.PrimaryPID())
+	}
+}
+
+func TestRunnerUnknownPlugin(t *testing.T) {
+	r := core.NewRunner(nil)
+	if _, err := r.Run(context.Background(), "missing", core.Target{}); err == nil {
+		t.Fatal("expected error for unknown plugin")
+	}
+}
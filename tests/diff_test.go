@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/supperghost/ossre/internal/diff"
+	"github.com/supperghost/ossre/internal/report"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+func TestCompareReports(t *testing.T) {
+	somaxconn := map[string]string{"key": "net.core.somaxconn"}
+	old := []models.Result{{
+		Plugin: "kernel",
+		Findings: []models.Finding{
+			{ID: "kernel.net.baseline.sysctl.net_core_somaxconn", Severity: models.SeverityWarning},
+			{ID: "kernel.limit.baseline.ulimit.nofile", Severity: models.SeverityWarning},
+			{ID: "kernel.limit.baseline.ulimit.nproc", Severity: models.SeverityWarning},
+		},
+		Metrics: []models.Metric{{Name: "kernel_sysctl_value", Labels: somaxconn, Value: 128}},
+	}}
+	cur := []models.Result{{
+		Plugin: "kernel",
+		Findings: []models.Finding{
+			{ID: "kernel.limit.baseline.ulimit.nofile", Severity: models.SeverityWarning},
+			{ID: "kernel.limit.baseline.ulimit.nproc", Severity: models.SeverityInfo},
+			{ID: "kernel.net.baseline.sysctl.net_ipv4_tcp_syncookies", Severity: models.SeverityError},
+		},
+		Metrics: []models.Metric{{Name: "kernel_sysctl_value", Labels: somaxconn, Value: 4096}},
+	}}
+
+	d := diff.Compare(old, cur)
+	if len(d.Resolved) != 1 || d.Resolved[0].ID != "kernel.net.baseline.sysctl.net_core_somaxconn" {
+		t.Errorf("resolved = %+v", d.Resolved)
+	}
+	if len(d.New) != 1 || d.New[0].NewSeverity != models.SeverityError {
+		t.Errorf("new = %+v", d.New)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].OldSeverity != models.SeverityWarning || d.Changed[0].NewSeverity != models.SeverityInfo {
+		t.Errorf("changed = %+v", d.Changed)
+	}
+	if len(d.Unchanged) != 1 {
+		t.Errorf("unchanged = %+v", d.Unchanged)
+	}
+
+	// 级别降低不算回归，只有新增的 error 满足 --fail-on=warning
+	if got := d.Regressions(models.SeverityWarning); len(got) != 1 {
+		t.Errorf("regressions(warning) = %+v", got)
+	}
+	if got := d.Regressions(models.SeverityCritical); len(got) != 0 {
+		t.Errorf("regressions(critical) = %+v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := diff.Write(&buf, report.FormatPlain, "old.json", "new.json", d); err != nil {
+		t.Fatal(err)
+	}
+	if want := `kernel_sysctl_value{key="net.core.somaxconn"}: 128 → 4096`; !strings.Contains(buf.String(), want) {
+		t.Errorf("plain diff missing %q:\n%s", want, buf.String())
+	}
+}
+
+func TestCompareMetricsAcrossPIDs(t *testing.T) {
+	// 两次运行以 ossre 自身为目标，进程号不同；scan 涉及多个进程，pid 仍用于区分
+	old := []models.Result{
+		{Plugin: "maxproc", Metrics: []models.Metric{
+			{Name: "maxproc_threads", Labels: map[string]string{"pid": "1001"}, Value: 8},
+			{Name: "maxproc_thread_headroom", Labels: map[string]string{"pid": "1001", "dimension": "nproc"}, Value: 100},
+		}},
+		{Plugin: "scan", Metrics: []models.Metric{
+			{Name: "scan_threads", Labels: map[string]string{"pid": "1"}, Value: 1},
+			{Name: "scan_threads", Labels: map[string]string{"pid": "1001"}, Value: 8},
+		}},
+	}
+	cur := []models.Result{
+		{Plugin: "maxproc", Metrics: []models.Metric{
+			{Name: "maxproc_threads", Labels: map[string]string{"pid": "2002"}, Value: 8},
+			{Name: "maxproc_thread_headroom", Labels: map[string]string{"pid": "2002", "dimension": "nproc"}, Value: 90},
+		}},
+		{Plugin: "scan", Metrics: []models.Metric{
+			{Name: "scan_threads", Labels: map[string]string{"pid": "1"}, Value: 1},
+			{Name: "scan_threads", Labels: map[string]string{"pid": "2002"}, Value: 8},
+		}},
+	}
+
+	d := diff.Compare(old, cur)
+	var got []string
+	for _, m := range d.Metrics {
+		got = append(got, m.Plugin+"/"+m.Series())
+	}
+	want := []string{
+		`maxproc/maxproc_thread_headroom{dimension="nproc",pid="2002"}`,
+		`scan/scan_threads{pid="1001"}`,
+		`scan/scan_threads{pid="2002"}`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("metric changes = %q, want %q", got, want)
+	}
+	if m := d.Metrics[0]; m.Old == nil || m.New == nil || *m.Old != 100 || *m.New != 90 {
+		t.Errorf("headroom change = %+v", m)
+	}
+}
+
+func TestReadJSONReport(t *testing.T) {
+	for _, results := range [][]models.Result{
+		{{Plugin: "kernel"}},
+		{{Plugin: "kernel"}, {Plugin: "maxfd"}},
+	} {
+		var buf bytes.Buffer
+		if err := report.Write(&buf, report.FormatJSON, report.Meta{}, results); err != nil {
+			t.Fatal(err)
+		}
+		got, err := report.ReadJSON(&buf)
+		if err != nil {
+			t.Fatalf("ReadJSON: %v", err)
+		}
+		if len(got) != len(results) || got[0].Plugin != "kernel" {
+			t.Errorf("ReadJSON round trip = %+v", got)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// writeExecPlugin 在 dir 中写入一个 shell 外部插件，describe 时输出给定名称，run 时执行 runBody。
+func writeExecPlugin(t *testing.T, dir, file, name, runBody string, mode os.FileMode) {
+	t.Helper()
+	script := `#!/bin/sh
+case "$1" in
+describe) echo '{"protocol":1,"name":"` + name + `","description":"test"}' ;;
+run) ` + runBody + ` ;;
+esac
+`
+	path := filepath.Join(dir, file)
+	if err := os.WriteFile(path, []byte(script), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecPluginReference(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+	dir := t.TempDir()
+	build := exec.Command(goBin, "build", "-o", filepath.Join(dir, "loadavg"), "../examples/plugins/loadavg")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("build reference plugin: %v\n%s", err, out)
+	}
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "proc"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"proc/loadavg": "9.00 4.00 2.00 3/200 1234\n",
+		"proc/stat":    "cpu  1 2 3 4\ncpu0 1 1 1 1\ncpu1 1 1 1 1\nintr 0\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := config.NewDefault()
+	cfg.Paths.SetRoot(root)
+	cfg.Plugins.Dir = dir
+
+	r := core.NewRunner(nil, core.WithConfig(cfg), core.WithExecPlugins())
+	if errs := r.PluginErrors(); len(errs) > 0 {
+		t.Fatalf("PluginErrors: %v", errs)
+	}
+	if meta := r.Metadata("loadavg"); meta.Version != "1.0.0" || !meta.HasTag("cpu") || !meta.HasTag(core.ExternalTag) {
+		t.Errorf("Metadata = %+v, want version and tags from describe plus external", meta)
+	}
+	result, err := r.Run(context.Background(), "loadavg", core.Target{})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Plugin != "loadavg" || len(result.Findings) != 1 || result.Findings[0].Severity != models.SeverityWarning {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(result.Metrics) != 1 || result.Metrics[0].Value != 4.5 {
+		t.Errorf("metrics = %+v, want loadavg_per_cpu 4.5", result.Metrics)
+	}
+}
+
+func TestExecPluginValidationAndTimeout(t *testing.T) {
+	dir := t.TempDir()
+	writeExecPlugin(t, dir, "slow", "slow", "sleep 10", 0o755)
+	writeExecPlugin(t, dir, "bad", "bad", `echo '{"Findings":[{"Title":"x","Severity":"fatal"}]}'`, 0o755)
+	writeExecPlugin(t, dir, "fails", "fails", "echo boom >&2; exit 3", 0o755)
+	writeExecPlugin(t, dir, "shadow", "fake", "echo '{}'", 0o755)
+	writeExecPlugin(t, dir, "writable", "writable", "echo '{}'", 0o775)
+	writeExecPlugin(t, dir, "badname", "Bad Name", "echo '{}'", 0o755)
+	writeExecPlugin(t, dir, "notexec", "notexec", "echo '{}'", 0o644)
+
+	cfg := config.NewDefault()
+	cfg.Plugins.Dir = dir
+	cfg.Plugins.Timeout = 300 * time.Millisecond
+	r := core.NewRunner([]core.Plugin{&metricPlugin{}}, core.WithConfig(cfg), core.WithExecPlugins())
+
+	var errText []string
+	for _, err := range r.PluginErrors() {
+		errText = append(errText, err.Error())
+	}
+	joined := strings.Join(errText, "\n")
+	for _, want := range []string{"conflicts with a built-in plugin", "writable by group or others", "invalid plugin name"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("PluginErrors missing %q:\n%s", want, joined)
+		}
+	}
+	if len(errText) != 3 {
+		t.Errorf("got %d plugin errors, want 3:\n%s", len(errText), joined)
+	}
+
+	start := time.Now()
+	_, err := r.Run(context.Background(), "slow", core.Target{})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("slow plugin error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timed out plugin took %s to stop", elapsed)
+	}
+	if _, err := r.Run(context.Background(), "bad", core.Target{}); err == nil || !strings.Contains(err.Error(), "unknown severity") {
+		t.Errorf("bad plugin error = %v", err)
+	}
+	if _, err := r.Run(context.Background(), "fails", core.Target{}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("failing plugin error = %v, want stderr in message", err)
+	}
+	if _, err := r.Run(context.Background(), "notexec", core.Target{}); err == nil {
+		t.Error("non-executable file should not be registered")
+	}
+}
+
+func TestExecPluginUserDropsGroups(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("switching plugin user requires root")
+	}
+	if _, err := user.Lookup("nobody"); err != nil {
+		t.Skip("user nobody not found")
+	}
+	// ossre 自身持有 nobody 不属于的附加组 4242，插件进程不应继承
+	saved, err := syscall.Getgroups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Setgroups(append(saved, 4242)); err != nil {
+		t.Skipf("setgroups: %v", err)
+	}
+	defer func() { _ = syscall.Setgroups(saved) }()
+
+	dir, err := os.MkdirTemp("", "ossre-exec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeExecPlugin(t, dir, "groups", "groups",
+		`echo "{\"Findings\":[{\"Title\":\"$(grep '^Groups:' /proc/self/status | cut -f2 | xargs)\",\"Severity\":\"info\"}]}"`, 0o755)
+
+	cfg := config.NewDefault()
+	cfg.Plugins.Dir = dir
+	cfg.Plugins.User = "nobody"
+	r := core.NewRunner(nil, core.WithConfig(cfg), core.WithExecPlugins())
+	result, err := r.Run(context.Background(), "groups", core.Target{})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(result.Findings) != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	u, _ := user.Lookup("nobody")
+	want, _ := u.GroupIds()
+	for _, g := range strings.Fields(result.Findings[0].Title) {
+		if !slices.Contains(want, g) {
+			t.Errorf("plugin groups = %q, want a subset of %v", result.Findings[0].Title, want)
+		}
+	}
+}
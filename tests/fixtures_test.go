@@ -0,0 +1,60 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/plugins/container"
+	"github.com/supperghost/ossre/internal/plugins/cpu"
+	"github.com/supperghost/ossre/internal/plugins/kernel"
+	"github.com/supperghost/ossre/internal/plugins/maxfd"
+	"github.com/supperghost/ossre/internal/plugins/maxproc"
+	"github.com/supperghost/ossre/internal/plugins/scan"
+	"github.com/supperghost/ossre/internal/plugintest"
+)
+
+// fixturePlugins 为夹具中可引用的插件。
+var fixturePlugins = map[string]func() core.Plugin{
+	container.PluginName: container.New,
+	cpu.PluginName:       cpu.New,
+	kernel.PluginName:    kernel.New,
+	maxproc.PluginName:   maxproc.New,
+	maxfd.PluginName:     maxfd.New,
+	scan.PluginName:      scan.New,
+}
+
+// TestPluginFixtures 对 testdata/fixtures 下的每个夹具运行其列出的插件，并与 golden 结果比较。
+// 修改插件输出后以 go test ./tests -run TestPluginFixtures -update 更新 golden 文件。
+func TestPluginFixtures(t *testing.T) {
+	fixtures, err := plugintest.LoadFixtures("testdata/fixtures")
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no fixtures found")
+	}
+	for _, f := range fixtures {
+		t.Run(f.Name, func(t *testing.T) {
+			if len(f.Plugins) == 0 {
+				t.Fatalf("fixture %s lists no plugins", f.Name)
+			}
+			for _, name := range f.Plugins {
+				t.Run(name, func(t *testing.T) {
+					newPlugin, ok := fixturePlugins[name]
+					if !ok {
+						t.Fatalf("unknown plugin %q", name)
+					}
+					result, err := plugintest.Run(context.Background(), newPlugin(), f.FS, f.Target, nil)
+					if err != nil {
+						t.Fatalf("Run: %v", err)
+					}
+					plugintest.Golden(t, f.GoldenPath(name), result)
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/plugins/builtin"
+)
+
+func TestRegistryBuiltins(t *testing.T) {
+	var names []string
+	for _, p := range builtin.Plugins() {
+		names = append(names, p.Name())
+	}
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("builtin plugins not sorted: %v", names)
+	}
+	for _, want := range []string{"io", "kernel", "maxfd", "maxproc", "net", "rules", "scan", "system"} {
+		meta, ok := core.LookupMetadata(want)
+		if !ok {
+			t.Errorf("%s not registered", want)
+			continue
+		}
+		if meta.Version == "" || len(meta.Tags) == 0 {
+			t.Errorf("%s metadata incomplete: %+v", want, meta)
+		}
+	}
+	kernel, _ := core.LookupMetadata("kernel")
+	if !kernel.HasTag("storage", "network") || kernel.HasTag("storage") {
+		t.Errorf("kernel tags = %v", kernel.Tags)
+	}
+
+	r := core.NewRunner(builtin.Plugins())
+	listed := r.ListPlugins()
+	if len(listed) != len(names) || listed[0].Name() != names[0] {
+		t.Errorf("ListPlugins = %d plugins starting with %s, want sorted %v", len(listed), listed[0].Name(), names)
+	}
+}
+
+func TestMetadataSupports(t *testing.T) {
+	meta := core.Metadata{OS: []string{"linux"}, MinKernel: "4.5"}
+	tests := []struct {
+		platform core.Platform
+		want     bool
+	}{
+		{core.Platform{OS: "linux", Kernel: "5.15.0-91-generic"}, true},
+		{core.Platform{OS: "linux", Kernel: "4.5"}, true},
+		{core.Platform{OS: "linux", Kernel: "4.4.302"}, false},
+		{core.Platform{OS: "linux", Kernel: "3.10.0-1160.el7.x86_64"}, false},
+		// 内核版本未知时不做内核版本检查
+		{core.Platform{OS: "linux"}, true},
+		{core.Platform{OS: "darwin", Kernel: "23.1.0"}, false},
+	}
+	for _, tt := range tests {
+		ok, reason := meta.Supports(tt.platform)
+		if ok != tt.want {
+			t.Errorf("Supports(%+v) = %v (%s), want %v", tt.platform, ok, reason, tt.want)
+		}
+		if !ok && reason == "" {
+			t.Errorf("Supports(%+v) gave no reason", tt.platform)
+		}
+	}
+	if ok, _ := (core.Metadata{}).Supports(core.Platform{OS: "plan9"}); !ok {
+		t.Error("metadata without constraints should support every platform")
+	}
+}
+
+func TestRunnerApplicable(t *testing.T) {
+	r := core.NewRunner(builtin.Plugins())
+	names, skipped := r.Applicable(core.Platform{OS: runtime.GOOS, Kernel: "2.6.18"})
+	joined := strings.Join(names, ",")
+	for _, name := range []string{"maxfd", "maxproc", "scan"} {
+		if _, ok := skipped[name]; !ok {
+			t.Errorf("%s should be skipped on 2.6.18, applicable: %s", name, joined)
+		}
+	}
+	if !strings.Contains(joined, "kernel") {
+		t.Errorf("kernel should be applicable everywhere, got %s", joined)
+	}
+}
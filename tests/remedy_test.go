@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/supperghost/ossre/internal/remedy"
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+func TestRemedyApplyAndRollback(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"proc/sys/net/core/somaxconn": "128\n",
+		"etc/sysctl.conf":             "# local\nnet.core.somaxconn = 128\nvm.swappiness = 10\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var paths config.PathsConfig
+	paths.SetRoot(root)
+	engine := remedy.NewEngine(paths, filepath.Join(root, "state"))
+
+	results := []models.Result{{
+		Plugin: "kernel",
+		Suggestions: []models.Suggestion{
+			{FindingID: "kernel.somaxconn", Actions: []models.Action{
+				{Type: models.ActionSysctl, Key: "net.core.somaxconn", Value: "4096"},
+				{Type: models.ActionSysctlDropIn, Key: "net.core.somaxconn", Value: "4096", Path: "/etc/sysctl.conf"},
+			}},
+			{FindingID: "kernel.nofile", Actions: []models.Action{
+				{Type: models.ActionLimitsDropIn, Key: "nofile", Value: "655350", Path: models.LimitsDropInFile},
+			}},
+			// 与第一个建议重复的动作只保留一次
+			{FindingID: "kernel.dup", Actions: []models.Action{
+				{Type: models.ActionSysctl, Key: "net.core.somaxconn", Value: "4096"},
+			}},
+		},
+	}}
+
+	steps := engine.Plan(results)
+	if len(steps) != 3 {
+		t.Fatalf("plan has %d steps, want 3: %+v", len(steps), steps)
+	}
+	if steps[0].Current != "128" || steps[1].Current != "128" || steps[2].Current != "" {
+		t.Errorf("unexpected current values: %q %q %q", steps[0].Current, steps[1].Current, steps[2].Current)
+	}
+
+	rec, err := engine.Apply(steps)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(root, name))
+		return string(data)
+	}
+	if got := read("proc/sys/net/core/somaxconn"); got != "4096\n" {
+		t.Errorf("somaxconn = %q", got)
+	}
+	if got := read("etc/sysctl.conf"); got != "# local\nnet.core.somaxconn = 4096\nvm.swappiness = 10\n" {
+		t.Errorf("sysctl.conf = %q", got)
+	}
+	limits := read("etc/security/limits.d/99-ossre.conf")
+	for _, want := range []string{"*    soft nofile 655350", "*    hard nofile 655350", "root soft nofile 655350", "root hard nofile 655350"} {
+		if !strings.Contains(limits, want) {
+			t.Errorf("limits drop-in missing %q:\n%s", want, limits)
+		}
+	}
+
+	if _, err := engine.Rollback(rec.ID); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	for name, content := range files {
+		if got := read(name); got != content {
+			t.Errorf("%s after rollback = %q, want %q", name, got, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "etc/security/limits.d/99-ossre.conf")); !os.IsNotExist(err) {
+		t.Errorf("drop-in created by apply should be removed on rollback, stat err = %v", err)
+	}
+
+	history, err := engine.History()
+	if err != nil || len(history) != 1 || history[0].RolledBack == nil {
+		t.Errorf("history = %+v, err = %v", history, err)
+	}
+
+	// 回滚后的手工修改不应被再次回滚覆盖
+	if err := os.WriteFile(filepath.Join(root, "etc/sysctl.conf"), []byte("net.core.somaxconn = 8192\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.Rollback(rec.ID); err == nil || !strings.Contains(err.Error(), "already rolled back") {
+		t.Errorf("second rollback err = %v, want already rolled back", err)
+	}
+	if got := read("etc/sysctl.conf"); got != "net.core.somaxconn = 8192\n" {
+		t.Errorf("sysctl.conf after second rollback = %q, want manual change kept", got)
+	}
+}
+
+func TestExportArtifacts(t *testing.T) {
+	results := []models.Result{
+		{Plugin: "kernel", Suggestions: []models.Suggestion{
+			{Actions: []models.Action{
+				{Type: models.ActionSysctl, Key: "net.core.somaxconn", Value: "4096"},
+				{Type: models.ActionSysctlDropIn, Key: "net.core.somaxconn", Value: "4096", Path: "/etc/sysctl.conf"},
+			}},
+			{Actions: []models.Action{
+				{Type: models.ActionLimitsDropIn, Key: "nofile", Value: "655350", Path: models.LimitsDropInFile},
+			}},
+		}},
+		{Plugin: "maxproc", Suggestions: []models.Suggestion{
+			{Actions: []models.Action{
+				{Type: models.ActionCgroupPidsMax, Value: "2048", Path: "/sys/fs/cgroup/system.slice/app.service/pids.max"},
+			}},
+		}},
+	}
+
+	artifacts, warnings := remedy.Export(results, remedy.ExportOptions{Unit: "app.service", Ansible: true})
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "/etc/sysctl.conf") {
+		t.Errorf("warnings = %q, want one about /etc/sysctl.conf", warnings)
+	}
+	got := make(map[string]string)
+	for _, a := range artifacts {
+		got[a.Path] = a.Content
+	}
+	for path, want := range map[string][]string{
+		models.SysctlDropInFile:                           {"net.core.somaxconn = 4096\n"},
+		models.LimitsDropInFile:                           {"*    soft nofile 655350\n", "root hard nofile 655350\n"},
+		"/etc/systemd/system/app.service.d/99-ossre.conf": {"[Service]\nLimitNOFILE=655350\nTasksMax=2048\n"},
+		remedy.AnsibleTasksFile:                           {"dest: /etc/sysctl.d/99-ossre.conf", "daemon_reload: true"},
+	} {
+		content, ok := got[path]
+		if !ok {
+			t.Errorf("missing artifact %s", path)
+			continue
+		}
+		for _, w := range want {
+			if !strings.Contains(content, w) {
+				t.Errorf("%s missing %q:\n%s", path, w, content)
+			}
+		}
+	}
+
+	// 未指定服务单元时不生成对所有服务生效的 drop-in，只提示需要写入目标服务
+	artifacts, warnings = remedy.Export(results, remedy.ExportOptions{})
+	for _, a := range artifacts {
+		if strings.HasPrefix(a.Path, "/etc/systemd/") {
+			t.Errorf("unexpected systemd artifact without unit: %s", a.Path)
+		}
+	}
+	if len(warnings) != 2 || !strings.Contains(warnings[1], "LimitNOFILE=655350、TasksMax=2048") {
+		t.Errorf("warnings = %q, want one about the skipped systemd drop-in", warnings)
+	}
+}
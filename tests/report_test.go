@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/supperghost/ossre/internal/report"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+func reportFixture() []models.Result {
+	return []models.Result{
+		{
+			Plugin: "maxproc",
+			Findings: []models.Finding{{
+				ID:          "maxproc.thread.headroom",
+				Title:       "线程创建余量 <script>",
+				Description: "A(nproc) 剩余: 0\n含有 ``` 的证据",
+				Severity:    models.SeverityError,
+				ConfigFile:  "/etc/security/limits.conf",
+			}, {
+				ID:       "maxproc.thread.usage",
+				Title:    "线程使用情况",
+				Severity: models.SeverityInfo,
+			}},
+			Suggestions: []models.Suggestion{{
+				FindingID: "maxproc.thread.headroom",
+				Title:     "提升 nproc",
+				Details:   "ulimit -SHu 655350",
+			}},
+		},
+		{Plugin: "io"},
+	}
+}
+
+func TestMarkdownReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := report.Write(&buf, report.FormatMarkdown, report.Meta{Hostname: "node-1"}, reportFixture()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"- 主机: node-1",
+		"- 配置文件: `/etc/security/limits.conf`",
+		"| [maxproc](#maxproc) | 🟠 error | 0 | 1 | 0 | 1 |",
+		"| [io](#io) | ✅ 未发现问题 | 0 | 0 | 0 | 0 |",
+		"<details>",
+		// 证据中含有三个反引号时围栏自动加长
+		"````text\nA(nproc) 剩余: 0\n含有 ``` 的证据\n````",
+		"```\nulimit -SHu 655350\n```",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestHTMLReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := report.Write(&buf, report.FormatHTML, report.Meta{}, reportFixture()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "<script>") {
+		t.Error("finding title was not escaped")
+	}
+	for _, want := range []string{
+		`<span class="badge error">error</span>`,
+		"<details>",
+		"<pre><code>ulimit -SHu 655350</code></pre>",
+		`<a href="#plugin-io">io</a>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html report missing %q", want)
+		}
+	}
+	for _, external := range []string{"http://", "https://", "<link", "<script"} {
+		if strings.Contains(out, external) {
+			t.Errorf("html report references external resource %q", external)
+		}
+	}
+}
+
+func TestSARIFReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := report.Write(&buf, report.FormatSARIF, report.Meta{Version: "v1"}, reportFixture()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID   string `json:"id"`
+						Help struct {
+							Text string `json:"text"`
+						} `json:"help"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v\n%s", err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log header: %s", buf.String())
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || len(run.Results) != 2 {
+		t.Fatalf("rules=%d results=%d, want 2 and 2", len(run.Tool.Driver.Rules), len(run.Results))
+	}
+	if rule := run.Tool.Driver.Rules[0]; rule.ID != "maxproc.thread.headroom" || !strings.Contains(rule.Help.Text, "ulimit -SHu 655350") {
+		t.Errorf("unexpected first rule: %+v", rule)
+	}
+	first := run.Results[0]
+	if first.RuleID != "maxproc.thread.headroom" || first.Level != "error" {
+		t.Errorf("unexpected first result: %+v", first)
+	}
+	if len(first.Locations) != 1 || first.Locations[0].PhysicalLocation.ArtifactLocation.URI != "file:///etc/security/limits.conf" {
+		t.Errorf("unexpected locations: %+v", first.Locations)
+	}
+	if second := run.Results[1]; second.Level != "note" || len(second.Locations) != 0 {
+		t.Errorf("unexpected second result: %+v", second)
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := report.Write(&buf, report.FormatJUnit, report.Meta{}, reportFixture()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	var suites struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Type string `xml:"type,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid JUnit XML: %v\n%s", err, buf.String())
+	}
+	// maxproc 两个案例（一个失败、一个 info 通过）加上元数据中声明但未产出的 trend 案例，
+	// io 未注册且无发现时输出一个通过的案例
+	if suites.Tests != 4 || suites.Failures != 1 || len(suites.Suites) != 2 {
+		t.Fatalf("tests=%d failures=%d suites=%d, want 4/1/2:\n%s", suites.Tests, suites.Failures, len(suites.Suites), buf.String())
+	}
+	cases := suites.Suites[0].Cases
+	if cases[0].Name != "maxproc.thread.headroom" || cases[0].Failure == nil || cases[0].Failure.Type != "error" {
+		t.Errorf("unexpected failing case: %+v", cases[0])
+	}
+	if cases[1].Failure != nil {
+		t.Errorf("info finding should pass: %+v", cases[1])
+	}
+	if len(cases) != 3 || cases[2].Name != "maxproc.thread.trend" || cases[2].Failure != nil {
+		t.Errorf("declared case without findings should pass: %+v", cases)
+	}
+	if io := suites.Suites[1]; io.Name != "io" || len(io.Cases) != 1 || io.Cases[0].Failure != nil {
+		t.Errorf("unexpected io suite: %+v", io)
+	}
+}
+
+func TestPrometheusReport(t *testing.T) {
+	results := reportFixture()
+	results[0].Metrics = []models.Metric{
+		{Name: "maxproc_thread_headroom", Help: "余量", Labels: map[string]string{"pid": "1", "dimension": "nproc"}, Value: 1048570},
+		{Name: "maxproc_thread_headroom", Help: "余量", Labels: map[string]string{"pid": "1", "dimension": "a\"b"}, Value: 0.5},
+	}
+	var buf bytes.Buffer
+	if err := report.Write(&buf, report.FormatPrometheus, report.Meta{}, results); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE ossre_findings gauge\n",
+		`ossre_findings{plugin="maxproc",severity="error"} 1`,
+		`ossre_findings{plugin="io",severity="critical"} 0`,
+		`ossre_finding{plugin="maxproc",id="maxproc.thread.headroom",severity="error"} 1`,
+		// 标签按名称排序，整数不使用科学计数法，标签值中的引号被转义
+		`ossre_maxproc_thread_headroom{plugin="maxproc",dimension="nproc",pid="1"} 1048570`,
+		`ossre_maxproc_thread_headroom{plugin="maxproc",dimension="a\"b",pid="1"} 0.5`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("prometheus output missing %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "# TYPE ossre_maxproc_thread_headroom "); n != 1 {
+		t.Errorf("metric family declared %d times, want 1", n)
+	}
+}
+
+func TestSkippedChecksReport(t *testing.T) {
+	results := []models.Result{{
+		Plugin: "maxfd",
+		Skipped: []models.SkippedCheck{{
+			Case:       "maxfd.fd.headroom",
+			Reason:     "权限不足，无法读取 /proc/42/fd（需要 root 或 CAP_SYS_PTRACE）",
+			Path:       "/proc/42/fd",
+			Permission: true,
+		}},
+	}}
+	for format, wants := range map[string][]string{
+		report.FormatPlain:      {"未发现问题，但有 1 项检查未评估。", "未评估:\n\n1. maxfd.fd.headroom\n   权限不足"},
+		report.FormatMarkdown:   {"| [maxfd](#maxfd) | ⚠️ 1 项未评估 |", "| `maxfd.fd.headroom` | 权限不足"},
+		report.FormatHTML:       {"1 项未评估", "<h3>未评估</h3>"},
+		report.FormatSARIF:      {`"toolExecutionNotifications"`, `"id": "maxfd.fd.headroom"`},
+		report.FormatJUnit:      {`skipped="1"`, `<skipped message="未评估: 权限不足`},
+		report.FormatPrometheus: {`ossre_checks_skipped{plugin="maxfd"} 1`},
+	} {
+		var buf bytes.Buffer
+		if err := report.Write(&buf, format, report.Meta{}, results); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s report missing %q:\n%s", format, want, buf.String())
+			}
+		}
+	}
+}
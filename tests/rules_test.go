@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/plugins/rules"
+	"github.com/supperghost/ossre/internal/plugintest"
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+func TestRulesPlugin(t *testing.T) {
+	dir := t.TempDir()
+	ruleFile := `rules:
+  - id: site.vm.swappiness
+    title: swappiness too high
+    sysctl: vm.swappiness
+    parser: int
+    op: "<="
+    expected: "10"
+    suggestion:
+      title: set to {{expected}}
+      details: current {{value}}
+  - id: site.mm.thp
+    title: thp enabled
+    source: /sys/kernel/mm/transparent_hugepage/enabled
+    parser: selected
+    op: in
+    expected: [never, madvise]
+    severity: error
+  - id: site.mem.free
+    title: low free memory
+    source: /proc/meminfo
+    parser: keyvalue
+    key: MemFree
+    op: ">="
+    expected: "1024"
+  - id: site.missing
+    title: skipped when the source does not exist
+    source: /proc/sys/does/not/exist
+    op: "=="
+    expected: "1"
+  - id: site.unparsable
+    title: skipped when the value cannot be parsed
+    source: /sys/kernel/mm/transparent_hugepage/enabled
+    parser: int
+    op: "=="
+    expected: "1"
+`
+	files := map[string]string{
+		"10-site.yaml": ruleFile,
+		"20-bad.yml":   "rules:\n  - id: bad\n    title: no source\n    op: \"==\"\n    expected: \"1\"\n",
+		"README.md":    "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fsys := plugintest.FromFS(fstest.MapFS{
+		"proc/sys/vm/swappiness":                     {Data: []byte("60\n")},
+		"sys/kernel/mm/transparent_hugepage/enabled": {Data: []byte("always [madvise] never\n")},
+		"proc/meminfo":                               {Data: []byte("MemTotal:  16384 kB\nMemFree:   512 kB\n")},
+	})
+	cfg := config.NewDefault()
+	cfg.Rules.Dirs = []string{dir}
+	result, err := plugintest.Run(context.Background(), rules.New(), fsys, core.Target{}, cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	got := make(map[string]models.Finding)
+	for _, f := range result.Findings {
+		got[f.ID] = f
+	}
+	if len(got) != 3 {
+		t.Fatalf("findings = %+v, want swappiness, meminfo and the invalid file", result.Findings)
+	}
+	if f := got["site.vm.swappiness"]; f.Severity != models.SeverityWarning || !strings.Contains(f.Description, "60") {
+		t.Errorf("swappiness finding = %+v", f)
+	}
+	if _, ok := got["site.mem.free"]; !ok {
+		t.Error("missing site.mem.free finding")
+	}
+	if f := got["rules.load.invalid"]; !strings.Contains(f.Description, "20-bad.yml") {
+		t.Errorf("invalid file finding = %+v", f)
+	}
+	if len(result.Suggestions) != 1 || result.Suggestions[0].Title != "set to 10" || result.Suggestions[0].Details != "current 60" {
+		t.Errorf("suggestions = %+v", result.Suggestions)
+	}
+	if len(result.Metrics) != 2 {
+		t.Errorf("metrics = %+v, want numeric values of swappiness and MemFree", result.Metrics)
+	}
+	// 无法读取或无法求值的规则记为未评估，而不是静默通过
+	var skipped []string
+	for _, s := range result.Skipped {
+		skipped = append(skipped, s.Case)
+	}
+	if want := []string{"site.missing", "site.unparsable"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+}
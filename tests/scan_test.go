@@ -0,0 +1,32 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/supperghost/ossre/internal/plugins/scan"
+)
+
+// TestSelectorMatchCgroup 验证 cgroup 筛选按路径边界匹配，而不是按字符串前缀匹配。
+func TestSelectorMatchCgroup(t *testing.T) {
+	sel := scan.Selector{Cgroups: []string{"/system.slice/app", "/kubepods/"}}
+	for _, tc := range []struct {
+		cgroup string
+		want   bool
+	}{
+		{"/system.slice/app", true},
+		{"/system.slice/app/worker", true},
+		{"/system.slice/app2", false},
+		{"/system.slice/application.service", false},
+		{"/kubepods", true},
+		{"/kubepods/pod1/c1", true},
+		{"/kubepods-besteffort", false},
+	} {
+		if got := sel.Match("java", 0, []string{tc.cgroup}); got != tc.want {
+			t.Errorf("Match(cgroup %q) = %v, want %v", tc.cgroup, got, tc.want)
+		}
+	}
+
+	if !(scan.Selector{Cgroups: []string{"/"}}).Match("java", 0, []string{"/user.slice"}) {
+		t.Error("cgroup:/ should match every cgroup")
+	}
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/supperghost/ossre/pkg/models"
+	"github.com/supperghost/ossre/pkg/ossre"
+)
+
+// fileMaxPlugin 是通过公开 API 编写的自定义插件，file-max 低于 min 时产生 error 级别发现。
+type fileMaxPlugin struct {
+	name string
+	min  int64
+	env  *ossre.Env
+}
+
+func (p *fileMaxPlugin) Name() string        { return p.name }
+func (p *fileMaxPlugin) Description() string { return "checks fs.file-max" }
+
+func (p *fileMaxPlugin) Run(ctx context.Context, env *ossre.Env) (models.Result, error) {
+	p.env = env
+	result := models.Result{Plugin: p.name}
+	data, err := env.FS.ReadFile("/proc/sys/fs/file-max")
+	if err != nil {
+		return result, err
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return result, err
+	}
+	if v < p.min {
+		result.Findings = append(result.Findings, models.Finding{
+			ID:       "site.fd.file-max",
+			Title:    "file-max too low",
+			Severity: models.SeverityError,
+		})
+	}
+	return result, nil
+}
+
+func TestSDKCustomPlugin(t *testing.T) {
+	fsys := fstest.MapFS{
+		"proc/sys/fs/file-max":     {Data: []byte("1024\n")},
+		"proc/sys/kernel/hostname": {Data: []byte("web-1\n")},
+	}
+	p := &fileMaxPlugin{name: "filemax", min: 65536}
+	r, err := ossre.New(ossre.WithBuiltins(), ossre.WithPlugins(p), ossre.WithFS(ossre.FromFS(fsys)))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := r.Plugins(); len(got) != 1 || got[0].Name != "filemax" || got[0].Builtin {
+		t.Fatalf("Plugins = %+v, want only the custom plugin", got)
+	}
+
+	rep, err := r.Run(context.Background(), ossre.Target{PIDs: []int{42}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if p.env.Target.PIDs[0] != 42 || p.env.Config == nil || p.env.Logger == nil {
+		t.Errorf("env = %+v", p.env)
+	}
+	if rep.Hostname != "web-1" {
+		t.Errorf("Hostname = %q, want web-1", rep.Hostname)
+	}
+	if got := rep.Findings(models.SeverityError); len(got) != 1 || got[0].ID != "site.fd.file-max" {
+		t.Errorf("Findings(error) = %+v", got)
+	}
+	if got := rep.Findings(models.SeverityCritical); len(got) != 0 {
+		t.Errorf("Findings(critical) = %+v, want none", got)
+	}
+	var buf bytes.Buffer
+	if err := rep.Write(&buf, "json"); err != nil || !strings.Contains(buf.String(), "site.fd.file-max") {
+		t.Errorf("Write json: err=%v\n%s", err, buf.String())
+	}
+}
+
+func TestSDKBuiltinsAndValidation(t *testing.T) {
+	builtins := ossre.Builtins()
+	names := make(map[string]bool)
+	for _, b := range builtins {
+		names[b.Name] = b.Builtin
+	}
+	for _, want := range []string{"kernel", "maxfd", "maxproc", "rules"} {
+		if !names[want] {
+			t.Errorf("Builtins missing %s: %+v", want, builtins)
+		}
+	}
+
+	r, err := ossre.New(ossre.WithBuiltins("kernel", "maxfd"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := r.Plugins(); len(got) != 2 || got[0].Name != "kernel" || got[1].Name != "maxfd" || !got[0].Builtin {
+		t.Errorf("Plugins = %+v, want kernel and maxfd", got)
+	}
+
+	if _, err := ossre.New(ossre.WithBuiltins("nope")); err == nil {
+		t.Error("unknown builtin should fail")
+	}
+	if _, err := ossre.New(ossre.WithPlugins(&fileMaxPlugin{name: "kernel"})); err == nil {
+		t.Error("plugin shadowing a builtin should fail")
+	}
+	if _, err := ossre.New(ossre.WithConfigFile("testdata/does-not-exist.yaml")); err == nil {
+		t.Error("missing config file should fail")
+	}
+	r, err = ossre.New(ossre.WithBuiltins())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := r.Run(context.Background(), ossre.Target{}, "kernel"); err == nil {
+		t.Error("running an unregistered plugin should fail")
+	}
+}
@@ -0,0 +1,305 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/server"
+	"github.com/supperghost/ossre/internal/suppress"
+	"github.com/supperghost/ossre/pkg/config"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+// metricPlugin 为只产出固定指标的测试插件，并记录被运行的次数。
+type metricPlugin struct {
+	runs int
+}
+
+func (p *metricPlugin) Name() string        { return "fake" }
+func (p *metricPlugin) Description() string { return "fake" }
+func (p *metricPlugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	p.runs++
+	return models.Result{
+		Plugin:  "fake",
+		Metrics: []models.Metric{{Name: "fake_value", Value: 42}},
+	}, nil
+}
+
+func TestSchedulerServesCachedMetrics(t *testing.T) {
+	p := &metricPlugin{}
+	sched := server.NewScheduler(core.NewRunner([]core.Plugin{p}), []string{"fake"}, time.Minute)
+
+	rec := httptest.NewRecorder()
+	sched.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("before first run: status %d, want 503", rec.Code)
+	}
+
+	if err := sched.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	// 多次抓取只返回缓存，不重复运行插件
+	for i := 0; i < 3; i++ {
+		rec = httptest.NewRecorder()
+		sched.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{`ossre_fake_value{plugin="fake"} 42`, "ossre_run_success 1"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+	if p.runs != 1 {
+		t.Errorf("plugin ran %d times, want 1", p.runs)
+	}
+}
+
+// blockingPlugin 在 release 关闭或 ctx 取消前一直阻塞，用于测试并发上限。
+type blockingPlugin struct {
+	release chan struct{}
+}
+
+func (p *blockingPlugin) Name() string        { return "block" }
+func (p *blockingPlugin) Description() string { return "block" }
+func (p *blockingPlugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+	}
+	return models.Result{Plugin: "block"}, nil
+}
+
+func newTestAPI(t *testing.T, opts ...server.APIOption) (*httptest.Server, *blockingPlugin) {
+	t.Helper()
+	block := &blockingPlugin{release: make(chan struct{})}
+	factory := func(o ...core.Option) *core.Runner {
+		return core.NewRunner([]core.Plugin{&metricPlugin{}, block}, o...)
+	}
+	api := server.NewAPI(factory, config.NewDefault(), opts...)
+	srv := httptest.NewServer(api.Handler())
+	t.Cleanup(func() {
+		srv.Close()
+		api.Close()
+	})
+	return srv, block
+}
+
+func apiRequest(t *testing.T, method, url, token, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestAPIRunLifecycle(t *testing.T) {
+	srv, _ := newTestAPI(t, server.WithToken("secret"))
+
+	resp := apiRequest(t, http.MethodGet, srv.URL+"/api/v1/plugins", "", "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("without token: status %d, want 401", resp.StatusCode)
+	}
+
+	resp = apiRequest(t, http.MethodPost, srv.URL+"/api/v1/runs", "secret", `{"modules":["nope"]}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unknown module: status %d, want 400", resp.StatusCode)
+	}
+
+	resp = apiRequest(t, http.MethodPost, srv.URL+"/api/v1/runs", "secret", `{"modules":["fake"],"pid":1}`)
+	var created server.RunInfo
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted || created.ID == "" {
+		t.Fatalf("create run: status %d, id %q", resp.StatusCode, created.ID)
+	}
+
+	// 进度流在运行结束后以 done 事件结束
+	resp = apiRequest(t, http.MethodGet, srv.URL+"/api/v1/runs/"+created.ID+"/events", "secret", "")
+	var phases []string
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		if data, ok := strings.CutPrefix(sc.Text(), "data: "); ok {
+			var e server.ProgressEvent
+			if err := json.Unmarshal([]byte(data), &e); err != nil {
+				t.Fatal(err)
+			}
+			phases = append(phases, e.Phase)
+		}
+	}
+	resp.Body.Close()
+	if got := strings.Join(phases, ","); got != "started,finished,done" {
+		t.Fatalf("progress phases = %s", got)
+	}
+
+	resp = apiRequest(t, http.MethodGet, srv.URL+"/api/v1/runs/"+created.ID, "secret", "")
+	var info server.RunInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if info.Status != server.RunSucceeded || len(info.Results) != 1 || len(info.Results[0].Metrics) != 1 {
+		t.Fatalf("unexpected run info: %+v", info)
+	}
+}
+
+func TestAPIConcurrencyLimitAndTimeout(t *testing.T) {
+	srv, block := newTestAPI(t, server.WithMaxConcurrent(1), server.WithRunTimeout(time.Minute))
+
+	resp := apiRequest(t, http.MethodPost, srv.URL+"/api/v1/runs", "", `{"modules":["block"]}`)
+	var first server.RunInfo
+	_ = json.NewDecoder(resp.Body).Decode(&first)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("first run: status %d", resp.StatusCode)
+	}
+
+	resp = apiRequest(t, http.MethodPost, srv.URL+"/api/v1/runs", "", `{"modules":["fake"]}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("second run: status %d, want 429", resp.StatusCode)
+	}
+
+	resp = apiRequest(t, http.MethodPost, srv.URL+"/api/v1/runs", "", `{"modules":["fake"],"timeout":"2m"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("timeout above limit: status %d, want 400", resp.StatusCode)
+	}
+
+	close(block.release)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp = apiRequest(t, http.MethodGet, srv.URL+"/api/v1/runs/"+first.ID, "", "")
+		var info server.RunInfo
+		_ = json.NewDecoder(resp.Body).Decode(&info)
+		resp.Body.Close()
+		if info.Status == server.RunSucceeded {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run did not finish: %+v", info)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waivedPlugin 产出两条发现，分别被有效与已过期的豁免匹配。
+type waivedPlugin struct{}
+
+func (p *waivedPlugin) Name() string        { return "waived" }
+func (p *waivedPlugin) Description() string { return "waived" }
+func (p *waivedPlugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	return models.Result{
+		Plugin: "waived",
+		Findings: []models.Finding{
+			{ID: "waived.accepted", Title: "accepted", Severity: models.SeverityWarning},
+			{ID: "waived.lapsed", Title: "lapsed", Severity: models.SeverityWarning},
+		},
+	}, nil
+}
+
+func TestServeAppliesSuppressions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "suppressions.yaml")
+	content := `suppressions:
+  - case: waived.accepted
+    hosts: [node-1]
+    roles: [db]
+    reason: accepted
+    owner: dba
+  - case: waived.lapsed
+    reason: temporary
+    owner: dba
+    expires: 2020-01-31
+`
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	list, err := suppress.Load(file)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	sched := server.NewScheduler(core.NewRunner([]core.Plugin{&waivedPlugin{}}), []string{"waived"}, time.Minute,
+		server.WithSuppressions(list, "node-1", "db"))
+	if err := sched.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	sched.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`ossre_findings_suppressed{plugin="waived"} 1`,
+		`ossre_finding{plugin="waived",id="waived.lapsed",severity="warning"} 1`,
+		`ossre_finding{plugin="waived",id="` + suppress.ExpiredFindingID + `",severity="warning"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `id="waived.accepted"`) {
+		t.Errorf("suppressed finding still reported as active:\n%s", body)
+	}
+
+	factory := func(o ...core.Option) *core.Runner {
+		return core.NewRunner([]core.Plugin{&waivedPlugin{}}, o...)
+	}
+	api := server.NewAPI(factory, config.NewDefault(), server.WithAPISuppressions(list, "node-1", "db"))
+	srv := httptest.NewServer(api.Handler())
+	t.Cleanup(func() {
+		srv.Close()
+		api.Close()
+	})
+	resp := apiRequest(t, http.MethodPost, srv.URL+"/api/v1/runs", "", `{"modules":["waived"]}`)
+	var created server.RunInfo
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	// 等待进度流结束，即运行完成
+	resp = apiRequest(t, http.MethodGet, srv.URL+"/api/v1/runs/"+created.ID+"/events", "", "")
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	resp = apiRequest(t, http.MethodGet, srv.URL+"/api/v1/runs/"+created.ID, "", "")
+	var info server.RunInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(info.Results) != 1 {
+		t.Fatalf("unexpected run info: %+v", info)
+	}
+	got := info.Results[0]
+	var ids []string
+	for _, f := range got.Findings {
+		ids = append(ids, f.ID)
+	}
+	if len(got.Suppressed) != 1 || got.Suppressed[0].Finding.ID != "waived.accepted" ||
+		strings.Join(ids, ",") != "waived.lapsed,"+suppress.ExpiredFindingID {
+		t.Errorf("findings = %v, suppressed = %+v", ids, got.Suppressed)
+	}
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/supperghost/ossre/internal/core"
+	"github.com/supperghost/ossre/internal/suppress"
+	"github.com/supperghost/ossre/pkg/models"
+)
+
+func TestSuppressApply(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "suppressions.yaml")
+	content := `suppressions:
+  - case: kernel.net.baseline.sysctl.*
+    hosts: [web-*]
+    roles: [frontend]
+    reason: accepted by netops
+    owner: netops
+    expires: 2026-12-31
+  - case: kernel.limit.baseline.ulimit.nofile
+    reason: legacy service
+    owner: alice
+    expires: 2026-01-31
+`
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	list, err := suppress.Load(file)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	results := []models.Result{{
+		Plugin: "kernel",
+		Findings: []models.Finding{
+			{ID: "kernel.net.baseline.sysctl.net_core_somaxconn", Title: "somaxconn", Severity: models.SeverityWarning},
+			{ID: "kernel.limit.baseline.ulimit.nofile", Title: "nofile", Severity: models.SeverityWarning},
+		},
+		Suggestions: []models.Suggestion{
+			{FindingID: "kernel.net.baseline.sysctl.net_core_somaxconn", Title: "raise somaxconn"},
+			{FindingID: "kernel.limit.baseline.ulimit.nofile", Title: "raise nofile"},
+		},
+	}}
+	// 到期日当天仍然有效
+	now := time.Date(2026, 12, 31, 23, 0, 0, 0, time.Local)
+	got := list.Apply(results, "web-1", "frontend", now)[0]
+
+	if len(got.Suppressed) != 1 || got.Suppressed[0].Finding.Title != "somaxconn" {
+		t.Fatalf("Suppressed = %+v", got.Suppressed)
+	}
+	s := got.Suppressed[0]
+	if s.Owner != "netops" || s.Expires != "2026-12-31" || len(s.Suggestions) != 1 {
+		t.Errorf("suppressed entry = %+v", s)
+	}
+	var ids []string
+	for _, f := range got.Findings {
+		ids = append(ids, f.ID)
+	}
+	if strings.Join(ids, ",") != "kernel.limit.baseline.ulimit.nofile,"+suppress.ExpiredFindingID {
+		t.Errorf("Findings = %v, want expired waiver to keep nofile and add a notice", ids)
+	}
+	if len(got.Suggestions) != 2 || got.Suggestions[0].Title != "raise nofile" {
+		t.Errorf("Suggestions = %+v", got.Suggestions)
+	}
+
+	// 主机或角色不匹配时不豁免；过期后也不再豁免
+	for _, tc := range []struct {
+		host, role string
+		now        time.Time
+	}{
+		{"db-1", "frontend", now},
+		{"web-1", "backend", now},
+		{"web-1", "frontend", now.Add(2 * time.Hour)},
+	} {
+		got := list.Apply(results, tc.host, tc.role, tc.now)[0]
+		for _, s := range got.Suppressed {
+			if s.Finding.Title == "somaxconn" {
+				t.Errorf("host=%s role=%s now=%s: somaxconn should not be suppressed", tc.host, tc.role, tc.now)
+			}
+		}
+	}
+
+	if l, err := suppress.Load(filepath.Join(t.TempDir(), "missing.yaml")); err != nil || len(l.Entries) != 0 {
+		t.Errorf("missing file: %v, %+v", err, l)
+	}
+	bad := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(bad, []byte("suppressions:\n  - case: kernel.*\n    reason: x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := suppress.Load(bad); err == nil || !strings.Contains(err.Error(), "owner") {
+		t.Errorf("Load without owner: err = %v", err)
+	}
+}
+
+// scenarioPlugin 声明两个场景，并按 ScenarioEnabled 运行。
+type scenarioPlugin struct {
+	ran []string
+}
+
+func (p *scenarioPlugin) Name() string        { return "scen" }
+func (p *scenarioPlugin) Description() string { return "scenario test plugin" }
+
+func (p *scenarioPlugin) Metadata() core.Metadata {
+	return core.Metadata{Scenarios: []string{"scen.net.baseline", "scen.disk.usage"}}
+}
+
+func (p *scenarioPlugin) Run(ctx context.Context, rc *core.RunContext) (models.Result, error) {
+	result := models.Result{Plugin: p.Name()}
+	for _, s := range p.Metadata().Scenarios {
+		if rc.ScenarioEnabled(s) {
+			p.ran = append(p.ran, s)
+		}
+	}
+	// 不检查 ScenarioEnabled 直接产出的发现由 Runner 按场景过滤
+	result.Findings = []models.Finding{
+		{ID: "scen.net.baseline.rmem", Severity: models.SeverityWarning},
+		{ID: "scen.disk.usage.root", Severity: models.SeverityWarning},
+		{ID: "scen.other", Severity: models.SeverityInfo},
+	}
+	result.Suggestions = []models.Suggestion{{FindingID: "scen.disk.usage.root"}}
+	return result, nil
+}
+
+func TestRunnerScenarioSelection(t *testing.T) {
+	p := &scenarioPlugin{}
+	r := core.NewRunner([]core.Plugin{p, &metricPlugin{}}, core.WithScenarios([]string{"scen.net.*"}))
+
+	names, err := r.ScenarioPlugins([]string{"scen.net.*"})
+	if err != nil || strings.Join(names, ",") != "scen" {
+		t.Fatalf("ScenarioPlugins = %v, %v", names, err)
+	}
+	if _, err := r.ScenarioPlugins([]string{"scen.cpu.*"}); err == nil {
+		t.Error("pattern matching no scenario should fail")
+	}
+
+	result, err := r.Run(context.Background(), "scen", core.Target{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(p.ran, ",") != "scen.net.baseline" {
+		t.Errorf("ran scenarios %v, want only scen.net.baseline", p.ran)
+	}
+	var ids []string
+	for _, f := range result.Findings {
+		ids = append(ids, f.ID)
+	}
+	if strings.Join(ids, ",") != "scen.net.baseline.rmem,scen.other" || len(result.Suggestions) != 0 {
+		t.Errorf("Findings = %v, Suggestions = %+v", ids, result.Suggestions)
+	}
+}